@@ -31,6 +31,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
@@ -64,6 +65,7 @@ type DevCmd struct {
 	ExitAfterDeploy bool
 	SkipPipeline    bool
 	Portforwarding  bool
+	Proxy           bool
 	VerboseSync     bool
 	PrintSyncLog    bool
 
@@ -136,6 +138,7 @@ Open terminal instead of logs:
 	devCmd.Flags().BoolVar(&cmd.PrintSyncLog, "print-sync", false, "If enabled will print the sync log to the terminal")
 
 	devCmd.Flags().BoolVar(&cmd.Portforwarding, "portforwarding", true, "Enable port forwarding")
+	devCmd.Flags().BoolVar(&cmd.Proxy, "proxy", true, "Start the proxy configured in dev.proxy")
 
 	devCmd.Flags().BoolVar(&cmd.ExitAfterDeploy, "exit-after-deploy", false, "Exits the command after building the images and deploying the project")
 	devCmd.Flags().BoolVarP(&cmd.Interactive, "interactive", "i", false, "DEPRECATED: DO NOT USE ANYMORE")
@@ -366,7 +369,7 @@ func (cmd *DevCmd) buildAndDeploy(f factory.Factory, configInterface config.Conf
 		var err error
 
 		// Start services
-		exitCode, err = cmd.startServices(f, configInterface, client, args, dependencies, cmd.log)
+		exitCode, err = cmd.startServices(f, configInterface, configOptions, client, args, dependencies, cmd.log)
 		if err != nil {
 			// Check if we should reload
 			if _, ok := err.(*reloadError); ok {
@@ -387,7 +390,7 @@ func (cmd *DevCmd) buildAndDeploy(f factory.Factory, configInterface config.Conf
 	return exitCode, nil
 }
 
-func (cmd *DevCmd) startServices(f factory.Factory, configInterface config.Config, client kubectl.Client, args []string, dependencies []types.Dependency, logger log.Logger) (int, error) {
+func (cmd *DevCmd) startServices(f factory.Factory, configInterface config.Config, configOptions *loader.ConfigOptions, client kubectl.Client, args []string, dependencies []types.Dependency, logger log.Logger) (int, error) {
 	var (
 		config          = configInterface.Config()
 		servicesClient  = f.NewServicesClient(configInterface, dependencies, client, logger)
@@ -414,6 +417,14 @@ func (cmd *DevCmd) startServices(f factory.Factory, configInterface config.Confi
 		}
 	}
 
+	if cmd.Proxy {
+		cmd.Proxy = false
+		err := servicesClient.StartProxy(nil)
+		if err != nil {
+			return 0, errors.Errorf("Unable to start proxy: %v", err)
+		}
+	}
+
 	// Open UI if configured
 	if cmd.UI {
 		cmd.UI = false
@@ -491,7 +502,18 @@ func (cmd *DevCmd) startServices(f factory.Factory, configInterface config.Confi
 		// Skip executing open config next time (e.g. when automatic redeployment is enabled)
 		cmd.Open = false
 
-		for _, openConfig := range config.Dev.Open {
+		// the urls were resolved before a fallback port might have been forwarded
+		openConfigs := config.Dev.Open
+		if portVariablesChanged(configInterface) {
+			reloadedConfig, err := cmd.configLoader.Load(configOptions, logger)
+			if err != nil {
+				return 0, err
+			}
+
+			openConfigs = reloadedConfig.Config().Dev.Open
+		}
+
+		for _, openConfig := range openConfigs {
 			if openConfig.URL != "" {
 				maxWait := 4 * time.Minute
 				logger.Infof("Opening '%s' as soon as application will be started (timeout: %s)", openConfig.URL, maxWait)
@@ -733,6 +755,26 @@ func contains(haystack []string, needle string) bool {
 	return false
 }
 
+// portVariablesChanged checks if the config was loaded with other local ports in the DEVSPACE_PORT_<NAME>
+// variables than the ones that were forwarded
+func portVariablesChanged(configInterface config.Config) bool {
+	if configInterface.Generated() == nil {
+		return false
+	}
+
+	for name, value := range configInterface.Generated().Vars {
+		if strings.HasPrefix(name, variable.PortVariablePrefix) == false {
+			continue
+		}
+
+		if resolved, ok := configInterface.Variables()[name]; ok && fmt.Sprintf("%v", resolved) != value {
+			return true
+		}
+	}
+
+	return false
+}
+
 func updateLastKubeContext(configLoader loader.ConfigLoader, client kubectl.Client, generatedConfig *generated.Config) error {
 	// Update generated if we deploy the application
 	if generatedConfig != nil {
@@ -780,6 +822,7 @@ func addDependenciesDevConfig(config *latest.Config, dependencies []types.Depend
 						LabelSelector:       p.LabelSelector,
 						ContainerName:       p.ContainerName,
 						Namespace:           p.Namespace,
+						ServiceName:         p.ServiceName,
 						Arch:                p.Arch,
 						PortMappings:        p.PortMappings,
 						PortMappingsReverse: p.PortMappingsReverse,
@@ -4,6 +4,9 @@ import (
 	"strconv"
 
 	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/services"
 	"github.com/loft-sh/devspace/pkg/util/factory"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
@@ -25,7 +28,8 @@ func newPortsCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Comma
 #######################################################
 ############### devspace list ports ###################
 #######################################################
-Lists the port forwarding configurations
+Lists the port forwarding configurations and the state
+of the port forwardings of a running devspace dev
 #######################################################
 	`,
 		Args: cobra.NoArgs,
@@ -60,17 +64,27 @@ func (cmd *portsCmd) RunListPort(f factory.Factory, cobraCmd *cobra.Command, arg
 		return nil
 	}
 
+	// a running devspace dev reports the state of its port forwardings
+	status, err := services.LoadPortForwardingStatus(services.PortForwardingStatusFile)
+	if err != nil {
+		return errors.Wrap(err, "load port forwarding status")
+	}
+
 	headerColumnNames := []string{
 		"Image",
 		"ImageSelector",
 		"LabelSelector",
+		"Service",
 		"Ports (Local:Remote)",
 	}
+	if status != nil {
+		headerColumnNames = append(headerColumnNames, "Status", "Pod", "Last Error")
+	}
 
 	portForwards := make([][]string, 0, len(config.Dev.Ports))
 
 	// Transform values into string arrays
-	for _, value := range config.Dev.Ports {
+	for index, value := range config.Dev.Ports {
 		selector := ""
 		for k, v := range value.LabelSelector {
 			if len(selector) > 0 {
@@ -87,21 +101,43 @@ func (cmd *portsCmd) RunListPort(f factory.Factory, cobraCmd *cobra.Command, arg
 					portMappings += ", "
 				}
 
-				remotePort := *v.LocalPort
+				localPort, remotePort := latest.LocalPortAuto, 0
+				if v.LocalPort != nil {
+					localPort, remotePort = strconv.Itoa(*v.LocalPort), *v.LocalPort
+				}
 				if v.RemotePort != nil {
 					remotePort = *v.RemotePort
 				}
 
-				portMappings += strconv.Itoa(*v.LocalPort) + ":" + strconv.Itoa(remotePort)
+				// an auto or fallback port was forwarded if the local port was already in use
+				if v.Name != "" && configInterface.Generated() != nil && configInterface.Generated().Vars[variable.PortVariableName(v.Name)] != "" {
+					localPort = configInterface.Generated().Vars[variable.PortVariableName(v.Name)]
+				}
+
+				portMappings += localPort + ":" + strconv.Itoa(remotePort)
+				if v.Protocol == latest.PortProtocolUDP {
+					portMappings += "/udp"
+				}
 			}
 		}
 
-		portForwards = append(portForwards, []string{
+		row := []string{
 			value.ImageName,
 			value.ImageSelector,
 			selector,
+			value.ServiceName,
 			portMappings,
-		})
+		}
+		if status != nil {
+			state, pod, lastError := "-", "", ""
+			if status[index] != nil {
+				state, pod, lastError = string(status[index].State), status[index].Pod, status[index].LastError
+			}
+
+			row = append(row, state, pod, lastError)
+		}
+
+		portForwards = append(portForwards, row)
 	}
 
 	log.PrintTable(logger, headerColumnNames, portForwards)
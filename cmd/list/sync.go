@@ -1,7 +1,13 @@
 package list
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
 	"github.com/loft-sh/devspace/pkg/util/factory"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
@@ -12,6 +18,8 @@ import (
 
 type syncCmd struct {
 	*flags.GlobalFlags
+
+	Explain string
 }
 
 func newSyncCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
@@ -25,6 +33,9 @@ func newSyncCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Comman
 ################# devspace list sync ##################
 #######################################################
 Lists the sync configuration
+
+devspace list sync
+devspace list sync --explain=node_modules/
 #######################################################
 	`,
 		Args: cobra.NoArgs,
@@ -32,6 +43,7 @@ Lists the sync configuration
 			return cmd.RunListSync(f, cobraCmd, args)
 		}}
 
+	syncCmd.Flags().StringVar(&cmd.Explain, "explain", "", "Explains which exclude rule of each sync configuration excludes the given path")
 	return syncCmd
 }
 
@@ -58,6 +70,9 @@ func (cmd *syncCmd) RunListSync(f factory.Factory, cobraCmd *cobra.Command, args
 		logger.Info("No sync paths are configured. Run `devspace add sync` to add new sync path\n")
 		return nil
 	}
+	if cmd.Explain != "" {
+		return explainSync(config.Dev.Sync, cmd.Explain, logger)
+	}
 
 	headerColumnNames := []string{
 		"Label Selector",
@@ -102,3 +117,72 @@ func (cmd *syncCmd) RunListSync(f factory.Factory, cobraCmd *cobra.Command, args
 	log.PrintTable(logger, headerColumnNames, syncPaths)
 	return nil
 }
+
+func explainSync(syncConfigs []*latest.SyncConfig, path string, logger log.Logger) error {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return errors.Wrap(err, "absolute path")
+	}
+
+	isDir := strings.HasSuffix(filepath.ToSlash(path), "/")
+	if stat, err := os.Stat(absolutePath); err == nil {
+		isDir = stat.IsDir()
+	}
+
+	rows := [][]string{}
+	for _, syncConfig := range syncConfigs {
+		localPath := "."
+		if syncConfig.LocalSubPath != "" {
+			localPath = syncConfig.LocalSubPath
+		}
+		containerPath := "."
+		if syncConfig.ContainerPath != "" {
+			containerPath = syncConfig.ContainerPath
+		}
+
+		absoluteLocalPath, err := filepath.Abs(localPath)
+		if err != nil {
+			return errors.Wrap(err, "absolute path")
+		}
+
+		relativePath, err := filepath.Rel(absoluteLocalPath, absolutePath)
+		if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+			logger.Infof("Path %s is not within local path %s of sync %s <-> %s", path, localPath, localPath, containerPath)
+			continue
+		}
+
+		exclusions, err := sync.Explain(sync.Options{
+			ExcludePaths:         syncConfig.ExcludePaths,
+			DownloadExcludePaths: syncConfig.DownloadExcludePaths,
+			UploadExcludePaths:   syncConfig.UploadExcludePaths,
+			ConflictStrategy:     syncConfig.ConflictStrategy,
+			UpstreamDisabled:     syncConfig.DisableUpload != nil && *syncConfig.DisableUpload,
+			DownstreamDisabled:   syncConfig.DisableDownload != nil && *syncConfig.DisableDownload,
+		}, relativePath, isDir)
+		if err != nil {
+			return err
+		}
+
+		for _, exclusion := range exclusions {
+			status := "synced"
+			if exclusion.Excluded {
+				status = "excluded"
+			}
+
+			rows = append(rows, []string{
+				localPath + " <-> " + containerPath,
+				exclusion.Direction,
+				status,
+				exclusion.Rule,
+				exclusion.Source,
+			})
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	log.PrintTable(logger, []string{"Sync", "Direction", "Status", "Rule", "Config Key"}, rows)
+	return nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/imageselector"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// ProxyCmd is a struct that defines a command call for "proxy"
+type ProxyCmd struct {
+	*flags.GlobalFlags
+
+	LabelSelector string
+	Image         string
+	Container     string
+	Pod           string
+	Pick          bool
+
+	Port        int
+	BindAddress string
+	Arch        string
+}
+
+// NewProxyCmd creates a new proxy command
+func NewProxyCmd(f factory.Factory, globalFlags *flags.GlobalFlags, plugins []plugin.Metadata) *cobra.Command {
+	cmd := &ProxyCmd{GlobalFlags: globalFlags}
+
+	proxyCmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Starts a local SOCKS5 / HTTP CONNECT proxy into the cluster network",
+		Long: `
+#######################################################
+################## devspace proxy #####################
+#######################################################
+Starts a local SOCKS5 and HTTP CONNECT proxy, which opens
+the proxied connections from the selected container. Every
+host and port that is reachable from the container, such
+as service dns names, is reachable through the proxy:
+
+devspace proxy
+devspace proxy --port=8888
+devspace proxy -l release=test
+devspace proxy --pod=my-pod --container=my-container
+
+curl --socks5-hostname localhost:1080 http://my-service
+#######################################################`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(f, plugins, cobraCmd, args)
+		},
+	}
+
+	proxyCmd.Flags().StringVarP(&cmd.Container, "container", "c", "", "Container name within pod the proxy connects from")
+	proxyCmd.Flags().StringVar(&cmd.Pod, "pod", "", "Pod the proxy connects from")
+	proxyCmd.Flags().StringVar(&cmd.Image, "image", "", "Image is the config name of an image to select in the devspace config (e.g. 'default'), it is NOT a docker image like myuser/myimage")
+	proxyCmd.Flags().StringVarP(&cmd.LabelSelector, "label-selector", "l", "", "Comma separated key=value selector list (e.g. release=test)")
+	proxyCmd.Flags().BoolVar(&cmd.Pick, "pick", true, "Select a pod / container if multiple are found")
+
+	proxyCmd.Flags().IntVar(&cmd.Port, "port", 0, "The local port of the proxy (Default is dev.proxy.port or 1080)")
+	proxyCmd.Flags().StringVar(&cmd.BindAddress, "bind-address", "", "The local address the proxy is bound to (Default is localhost)")
+	proxyCmd.Flags().StringVar(&cmd.Arch, "arch", "", "The architecture of the selected container (amd64 or arm64)")
+
+	return proxyCmd
+}
+
+// Run executes the command logic
+func (cmd *ProxyCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *cobra.Command, args []string) error {
+	// Set config root
+	logger := f.GetLog()
+	configOptions := cmd.ToConfigOptions()
+	configLoader := f.NewConfigLoader(cmd.ConfigPath)
+	configExists, err := configLoader.SetDevSpaceRoot(logger)
+	if err != nil {
+		return err
+	}
+
+	// Load generated config if possible
+	var generatedConfig *generated.Config
+	if configExists {
+		generatedConfig, err = configLoader.LoadGenerated(configOptions)
+		if err != nil {
+			return err
+		}
+		configOptions.GeneratedConfig = generatedConfig
+	}
+
+	// Use last context if specified
+	err = cmd.UseLastContext(generatedConfig, logger)
+	if err != nil {
+		return err
+	}
+
+	// Get kubectl client
+	client, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace, cmd.SwitchContext)
+	if err != nil {
+		return errors.Wrap(err, "new kube client")
+	}
+	configOptions.KubeClient = client
+
+	// Execute plugin hook
+	err = plugin.ExecutePluginHook(plugins, cobraCmd, args, "proxy", client.CurrentContext(), client.Namespace(), nil)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Arch != "" && loader.ValidContainerArch(latest.ContainerArchitecture(cmd.Arch)) == false {
+		return errors.Errorf("--arch is not valid '%s'", cmd.Arch)
+	}
+
+	// Build params
+	options := targetselector.NewOptionsFromFlags(cmd.Container, cmd.LabelSelector, cmd.Namespace, cmd.Pod, cmd.Pick)
+	proxyConfig := &latest.ProxyConfig{}
+
+	// without selector flags the proxy of the devspace config is used
+	var configInterface config.Config
+	if configExists && cmd.Image == "" && cmd.LabelSelector == "" && cmd.Pod == "" {
+		configInterface, err = configLoader.Load(configOptions, logger)
+		if err != nil {
+			return err
+		}
+
+		if configInterface.Config().Dev.Proxy != nil {
+			proxyConfig = configInterface.Config().Dev.Proxy
+			options = options.ApplyConfigParameter(proxyConfig.LabelSelector, proxyConfig.Namespace, proxyConfig.ContainerName, "")
+
+			imageSelector, err := imageselector.Resolve(proxyConfig.ImageName, configInterface, nil)
+			if err != nil {
+				return err
+			} else if imageSelector != nil {
+				options.ImageSelector = append(options.ImageSelector, *imageSelector)
+			}
+			if proxyConfig.ImageSelector != "" {
+				imageSelector, err := util.ResolveImageAsImageSelector(proxyConfig.ImageSelector, configInterface, nil)
+				if err != nil {
+					return err
+				}
+
+				options.ImageSelector = append(options.ImageSelector, *imageSelector)
+			}
+		}
+	} else {
+		options.ImageSelector, err = getImageSelector(client, configLoader, configOptions, cmd.Image, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.Port != 0 {
+		proxyConfig.Port = &cmd.Port
+	}
+	if cmd.BindAddress != "" {
+		proxyConfig.BindAddress = cmd.BindAddress
+	}
+	if cmd.Arch != "" {
+		proxyConfig.Arch = latest.ContainerArchitecture(cmd.Arch)
+	}
+
+	// Start proxy
+	return f.NewServicesClient(configInterface, nil, client, logger).StartProxyFromCmd(options, proxyConfig, make(chan error))
+}
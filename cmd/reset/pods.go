@@ -32,7 +32,9 @@ func newPodsCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Comman
 #######################################################
 ############### devspace reset pods ###################
 #######################################################
-Resets the replaced pods to its original state
+Resets the replaced pods to its original state and
+deletes the persistent volume claims that were created
+for the persisted paths of the replaced pods
 
 Examples:
 devspace reset pods
@@ -154,6 +154,7 @@ func BuildRoot(f factory.Factory) *cobra.Command {
 	rootCmd.AddCommand(NewDevCmd(f, globalFlags, plugins))
 	rootCmd.AddCommand(NewBuildCmd(f, globalFlags, plugins))
 	rootCmd.AddCommand(NewSyncCmd(f, globalFlags, plugins))
+	rootCmd.AddCommand(NewProxyCmd(f, globalFlags, plugins))
 	rootCmd.AddCommand(NewRenderCmd(f, globalFlags, plugins))
 	rootCmd.AddCommand(NewPurgeCmd(f, globalFlags, plugins))
 	rootCmd.AddCommand(NewUpgradeCmd(plugins))
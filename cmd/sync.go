@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/loft-sh/devspace/pkg/devspace/config"
 	"github.com/loft-sh/devspace/pkg/devspace/plugin"
@@ -14,7 +15,9 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
 	latest "github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
 	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/survey"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -38,6 +41,8 @@ type SyncCmd struct {
 	Verbose bool
 
 	NoWatch               bool
+	DryRun                bool
+	Output                string
 	DownloadOnInitialSync bool
 	DownloadOnly          bool
 	UploadOnly            bool
@@ -62,6 +67,7 @@ devspace sync --local-path=subfolder --container-path=/app
 devspace sync --exclude=node_modules --exclude=test
 devspace sync --pod=my-pod --container=my-container
 devspace sync --container-path=/my-path
+devspace sync --dry-run -o json
 #######################################################`,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			// Print upgrade message if new version available
@@ -84,6 +90,8 @@ devspace sync --container-path=/my-path
 	syncCmd.Flags().StringVar(&cmd.InitialSync, "initial-sync", "", "The initial sync strategy to use (mirrorLocal, mirrorRemote, preferLocal, preferRemote, preferNewest, keepAll)")
 
 	syncCmd.Flags().BoolVar(&cmd.NoWatch, "no-watch", false, "Synchronizes local and remote and then stops")
+	syncCmd.Flags().BoolVar(&cmd.DryRun, "dry-run", false, "Prints the changes of the initial sync without transferring any files")
+	syncCmd.Flags().StringVarP(&cmd.Output, "output", "o", "", "The output format of --dry-run. Can be either empty or json")
 	syncCmd.Flags().BoolVar(&cmd.Verbose, "verbose", false, "Shows every file that is synced")
 
 	syncCmd.Flags().BoolVar(&cmd.UploadOnly, "upload-only", false, "If set DevSpace will only upload files")
@@ -264,6 +272,64 @@ func (cmd *SyncCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *
 		options = options.ApplyConfigParameter(syncConfig.LabelSelector, syncConfig.Namespace, syncConfig.ContainerName, "")
 	}
 
+	// Only print what the initial sync would do
+	if cmd.DryRun {
+		plan, err := f.NewServicesClient(configInterface, nil, client, logger).PlanSyncFromCmd(options, syncConfig)
+		if err != nil {
+			return err
+		}
+
+		return printSyncPlan(plan, cmd.Output, logger)
+	}
+
 	// Start terminal
 	return f.NewServicesClient(configInterface, nil, client, logger).StartSyncFromCmd(options, syncConfig, nil, cmd.Verbose)
 }
+
+func printSyncPlan(plan *sync.Plan, output string, logger log.Logger) error {
+	switch output {
+	case "":
+		rows := [][]string{}
+		for _, action := range []struct {
+			name    string
+			entries []*sync.PlanEntry
+		}{
+			{name: "upload", entries: plan.Upload},
+			{name: "download", entries: plan.Download},
+			{name: "remove remote", entries: plan.RemoveRemote},
+			{name: "remove local", entries: plan.RemoveLocal},
+		} {
+			size := int64(0)
+			for _, entry := range action.entries {
+				path := entry.Path
+				if entry.IsDirectory {
+					path += "/"
+				}
+
+				size += entry.Size
+				rows = append(rows, []string{action.name, path, fmt.Sprintf("%0.2f KB", float64(entry.Size)/1024.0)})
+			}
+
+			logger.Infof("%d file(s) to %s (~%0.2f KB)", len(action.entries), action.name, float64(size)/1024.0)
+		}
+
+		if len(rows) == 0 {
+			logger.Donef("Local and remote are in sync with initial sync strategy %s", plan.Strategy)
+			return nil
+		}
+
+		logger.Infof("Initial sync strategy %s would apply the following changes:", plan.Strategy)
+		log.PrintTable(logger, []string{"Action", "Path", "Size"}, rows)
+	case "json":
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(out))
+	default:
+		return errors.Errorf("unsupported value for flag --output: %s", output)
+	}
+
+	return nil
+}
@@ -87,6 +87,7 @@ module.exports = {
             'configuration/development/basics',
             'configuration/development/port-forwarding',
             'configuration/development/reverse-port-forwarding',
+            'configuration/development/proxy',
             'configuration/development/open-links',
             'configuration/development/file-synchronization',
             'configuration/development/terminal',
@@ -235,6 +236,7 @@ module.exports = {
         "commands/devspace_logs",
         "commands/devspace_open",
         "commands/devspace_print",
+        "commands/devspace_proxy",
         "commands/devspace_purge",
         {
           type: "category",
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// PersistCmd holds the persist populate cmd flags
+type PersistCmd struct {
+	Volume  string
+	Staging string
+}
+
+// NewPersistCmd creates a new persist command
+func NewPersistCmd() *cobra.Command {
+	persistCmd := &cobra.Command{
+		Use:   "persist",
+		Short: "Populates the persistent volume claim of persisted paths",
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install [target]",
+		Short: "Copies the helper to the target, so that it can be run in the image of the persisted container",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return installHelper(args[0])
+		},
+	}
+
+	cmd := &PersistCmd{}
+	populateCmd := &cobra.Command{
+		Use:   "populate [path volumePath]...",
+		Short: "Copies the paths to their volume paths within the volume if the volume paths do not exist yet",
+		Args: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) == 0 || len(args)%2 != 0 {
+				return errors.New("expected pairs of a path and its volume path")
+			}
+
+			return nil
+		},
+		RunE: cmd.Run,
+	}
+	populateCmd.Flags().StringVar(&cmd.Volume, "volume", "", "The path the persistent volume claim is mounted at")
+	populateCmd.Flags().StringVar(&cmd.Staging, "staging", "", "The path within the volume the paths are copied to before they are moved to their volume path")
+
+	persistCmd.AddCommand(installCmd)
+	persistCmd.AddCommand(populateCmd)
+	return persistCmd
+}
+
+// Run runs the command logic
+func (cmd *PersistCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	if cmd.Volume == "" || cmd.Staging == "" {
+		return errors.New("--volume and --staging are required")
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		err := util.PopulatePersistentPath(args[i], cmd.Volume, args[i+1], cmd.Staging)
+		if err != nil {
+			return errors.Wrapf(err, "populate %s", args[i])
+		}
+	}
+
+	return os.RemoveAll(filepath.Join(cmd.Volume, cmd.Staging))
+}
+
+// installHelper copies the running helper binary to the target
+func installHelper(target string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "find helper binary")
+	}
+
+	in, err := os.Open(executable)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return errors.Wrapf(err, "copy helper binary to %s", target)
+	}
+
+	return out.Close()
+}
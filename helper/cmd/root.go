@@ -36,6 +36,7 @@ func BuildRoot() *cobra.Command {
 	rootCmd.AddCommand(NewRestartCmd())
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewTunnelCmd())
+	rootCmd.AddCommand(NewPersistCmd())
 	rootCmd.AddCommand(sync.NewSyncCmd())
 
 	return rootCmd
@@ -0,0 +1,14 @@
+// +build !windows
+
+package sync
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the command in its own session, so that it keeps running after the exec session that
+// started it has ended
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,8 @@
+// +build windows
+
+package sync
+
+import "os/exec"
+
+// detach does nothing on windows
+func detach(cmd *exec.Cmd) {}
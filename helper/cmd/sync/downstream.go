@@ -2,8 +2,8 @@ package sync
 
 import (
 	"github.com/loft-sh/devspace/helper/server"
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 // DownstreamCmd holds the downstream cmd flags
@@ -13,11 +13,18 @@ type DownstreamCmd struct {
 	Throttle int64
 
 	Polling bool
+
+	Symlinks string
+
+	MaxFileSize int64
+	SizeRules   []string
+
+	streams *Streams
 }
 
 // NewDownstreamCmd creates a new downstream command
-func NewDownstreamCmd() *cobra.Command {
-	cmd := &DownstreamCmd{}
+func NewDownstreamCmd(streams *Streams) *cobra.Command {
+	cmd := &DownstreamCmd{streams: streams}
 	downstreamCmd := &cobra.Command{
 		Use:   "downstream",
 		Short: "Starts the downstream sync server",
@@ -28,6 +35,9 @@ func NewDownstreamCmd() *cobra.Command {
 	downstreamCmd.Flags().StringSliceVar(&cmd.Exclude, "exclude", []string{}, "The exclude paths for downstream watching")
 	downstreamCmd.Flags().Int64Var(&cmd.Throttle, "throttle", 5, "The amount of milliseconds to throttle change detection per 100 files")
 	downstreamCmd.Flags().BoolVar(&cmd.Polling, "polling", false, "If true, DevSpace will use polling instead of inotify")
+	downstreamCmd.Flags().StringVar(&cmd.Symlinks, "symlinks", "follow", "How symlinks are synced: follow, preserve or skip")
+	downstreamCmd.Flags().Int64Var(&cmd.MaxFileSize, "maxfilesize", 0, "Files larger than this amount of bytes are not synced")
+	downstreamCmd.Flags().StringArrayVar(&cmd.SizeRules, "sizerule", []string{}, "Rule as json that limits the size of files matching a path")
 	return downstreamCmd
 }
 
@@ -38,12 +48,29 @@ func (cmd *DownstreamCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return server.StartDownstreamServer(os.Stdin, os.Stdout, &server.DownstreamOptions{
+	sizeRules := make([]util.SizeRule, 0, len(cmd.SizeRules))
+	for _, rule := range cmd.SizeRules {
+		sizeRule, err := util.ParseSizeRule(rule)
+		if err != nil {
+			return err
+		}
+
+		sizeRules = append(sizeRules, sizeRule)
+	}
+
+	sizeLimits, err := util.NewSizeLimits(cmd.MaxFileSize, sizeRules)
+	if err != nil {
+		return err
+	}
+
+	return server.StartDownstreamServer(cmd.streams.Reader, cmd.streams.Writer, &server.DownstreamOptions{
 		RemotePath:   absolutePath,
 		ExcludePaths: cmd.Exclude,
 
 		Throttle:    cmd.Throttle,
 		Polling:     cmd.Polling,
-		ExitOnClose: true,
+		Symlinks:    cmd.Symlinks,
+		SizeLimits:  sizeLimits,
+		ExitOnClose: cmd.streams.ExitOnClose,
 	})
 }
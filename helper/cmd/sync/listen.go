@@ -0,0 +1,241 @@
+package sync
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// DefaultListenPort is the port the long-running sync server listens on by default
+const DefaultListenPort = 8765
+
+// ListenTokenEnv is the environment variable that holds the token clients of the sync server have to send
+const ListenTokenEnv = "DEVSPACE_SYNC_TOKEN"
+
+// ListenRequest is the first line a client sends to the sync server as json. It authenticates the client
+// and tells the server which sync command to run, such as ["upstream", "--exclude", "node_modules", "/app"]
+type ListenRequest struct {
+	Token string   `json:"token"`
+	Args  []string `json:"args"`
+}
+
+// CommandFlags are the upstream flags that run commands in the container. The sync server rejects them,
+// because they may only be passed to a sync command that was started via exec
+var CommandFlags = map[string]bool{
+	"--filechangecmd":  true,
+	"--filechangeargs": true,
+	"--dircreatecmd":   true,
+	"--dircreateargs":  true,
+	"--batchcmd":       true,
+	"--batchargs":      true,
+	"--uploadrule":     true,
+}
+
+// HasCommandArgs checks if the sync command args contain one of the command flags
+func HasCommandArgs(args []string) bool {
+	for _, arg := range args {
+		if CommandFlags[strings.SplitN(arg, "=", 2)[0]] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListenCmd holds the listen cmd flags
+type ListenCmd struct {
+	Address string
+	Port    int
+
+	Background bool
+}
+
+// NewListenCmd creates a new listen command
+func NewListenCmd() *cobra.Command {
+	cmd := &ListenCmd{}
+	listenCmd := &cobra.Command{
+		Use:   "listen",
+		Short: "Starts a long-running server that accepts upstream and downstream connections over tcp",
+		Long: `Starts a long-running server that accepts upstream and downstream connections over tcp.
+Clients have to send the token of the server, which is read from the environment variable
+` + ListenTokenEnv + ` or generated and printed on startup. With --background the token of
+the running server is printed.`,
+		Args: cobra.NoArgs,
+		RunE: cmd.Run,
+	}
+
+	listenCmd.Flags().StringVar(&cmd.Address, "address", "127.0.0.1", "The address to listen on")
+	listenCmd.Flags().IntVar(&cmd.Port, "port", DefaultListenPort, "The port to listen on")
+	listenCmd.Flags().BoolVar(&cmd.Background, "background", false, "If true, the server is started in the background if it is not running already")
+	return listenCmd
+}
+
+// Run runs the command logic
+func (cmd *ListenCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	address := net.JoinHostPort(cmd.Address, strconv.Itoa(cmd.Port))
+	if cmd.Background {
+		return cmd.startInBackground(address)
+	}
+
+	token := os.Getenv(ListenTokenEnv)
+	if token == "" {
+		var err error
+		token, err = newListenToken()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(token)
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Wrapf(err, "listen on %s", address)
+	}
+	defer listener.Close()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return errors.Wrap(err, "accept connection")
+		}
+
+		go serveConnection(conn, token)
+	}
+}
+
+// startInBackground starts the sync server if it is not running yet and prints its token
+func (cmd *ListenCmd) startInBackground(address string) error {
+	tokenFile := listenTokenFile(cmd.Port)
+	if isListening(address) {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return errors.Wrap(err, "read token of the running sync server")
+		}
+
+		fmt.Println(string(token))
+		return nil
+	}
+
+	token, err := newListenToken()
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(tokenFile, []byte(token), 0600)
+	if err != nil {
+		return errors.Wrap(err, "write token")
+	}
+
+	server := exec.Command(os.Args[0], "sync", "listen", "--address", cmd.Address, "--port", strconv.Itoa(cmd.Port))
+	server.Env = append(os.Environ(), ListenTokenEnv+"="+token)
+	detach(server)
+	err = server.Start()
+	if err != nil {
+		return errors.Wrap(err, "start sync server")
+	}
+
+	// The server is reaped as long as we are running and we notice if it exits right away
+	exited := make(chan error, 1)
+	go func() {
+		exited <- server.Wait()
+	}()
+
+	for i := 0; i < 50; i++ {
+		if isListening(address) {
+			fmt.Println(token)
+			return nil
+		}
+
+		select {
+		case err := <-exited:
+			return errors.Errorf("sync server exited: %v", err)
+		case <-time.After(time.Millisecond * 100):
+		}
+	}
+
+	return errors.Errorf("timeout waiting for the sync server to listen on %s", address)
+}
+
+// listenTokenFile returns the file the token of the sync server on the given port is saved in
+func listenTokenFile(port int) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("devspace-sync-%d.token", port))
+}
+
+func newListenToken() (string, error) {
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", errors.Wrap(err, "generate token")
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+func isListening(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return false
+	}
+
+	conn.Close()
+	return true
+}
+
+// serveConnection reads the listen request from the first line of the connection, checks the token
+// and then runs the sync command over the connection
+func serveConnection(conn net.Conn, token string) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+
+	request := &ListenRequest{}
+	err = json.Unmarshal(line, request)
+	if err != nil {
+		log.Printf("invalid listen request from %s", conn.RemoteAddr())
+		return
+	} else if subtle.ConstantTimeCompare([]byte(request.Token), []byte(token)) != 1 {
+		log.Printf("invalid token from %s", conn.RemoteAddr())
+		return
+	}
+
+	args := request.Args
+	if len(args) == 0 || (args[0] != "upstream" && args[0] != "downstream") {
+		log.Printf("invalid sync command %v", args)
+		return
+	} else if HasCommandArgs(args) {
+		log.Printf("sync command %v runs commands, which is not allowed over tcp", args)
+		return
+	}
+
+	syncCmd := NewSyncCmdWithStreams(&Streams{
+		Reader: reader,
+		Writer: conn,
+	})
+	syncCmd.SetArgs(args)
+	syncCmd.SilenceUsage = true
+	syncCmd.SilenceErrors = true
+
+	err = syncCmd.Execute()
+	if err != nil {
+		log.Printf("error running sync %s: %v", args[0], err)
+	}
+}
@@ -1,18 +1,43 @@
 package sync
 
 import (
+	"io"
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
+// Streams are the reader and writer the sync servers communicate over
+type Streams struct {
+	Reader io.Reader
+	Writer io.Writer
+
+	// ExitOnClose exits the process as soon as the streams are closed
+	ExitOnClose bool
+}
+
 // NewSyncCmd creates a new cobra command
 func NewSyncCmd() *cobra.Command {
+	syncCmd := NewSyncCmdWithStreams(&Streams{
+		Reader:      os.Stdin,
+		Writer:      os.Stdout,
+		ExitOnClose: true,
+	})
+
+	syncCmd.AddCommand(NewListenCmd())
+	return syncCmd
+}
+
+// NewSyncCmdWithStreams creates a new cobra command whose sync servers communicate over the given streams
+// instead of stdin and stdout
+func NewSyncCmdWithStreams(streams *Streams) *cobra.Command {
 	syncCmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Sync holds the sync relevant commands",
 		Args:  cobra.NoArgs,
 	}
 
-	syncCmd.AddCommand(NewDownstreamCmd())
-	syncCmd.AddCommand(NewUpstreamCmd())
+	syncCmd.AddCommand(NewDownstreamCmd(streams))
+	syncCmd.AddCommand(NewUpstreamCmd(streams))
 	return syncCmd
 }
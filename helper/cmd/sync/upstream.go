@@ -19,12 +19,24 @@ type UpstreamCmd struct {
 	BatchCmd  string
 	BatchArgs []string
 
+	UploadRules []string
+
+	Owner              string
+	Group              string
+	FileMode           string
+	DirMode            string
+	PreserveExecutable bool
+
+	Symlinks string
+
 	Exclude []string
+
+	streams *Streams
 }
 
 // NewUpstreamCmd creates a new upstream command
-func NewUpstreamCmd() *cobra.Command {
-	cmd := &UpstreamCmd{}
+func NewUpstreamCmd(streams *Streams) *cobra.Command {
+	cmd := &UpstreamCmd{streams: streams}
 	upstreamCmd := &cobra.Command{
 		Use:   "upstream",
 		Short: "Starts the upstream sync server",
@@ -40,6 +52,15 @@ func NewUpstreamCmd() *cobra.Command {
 
 	upstreamCmd.Flags().StringVar(&cmd.BatchCmd, "batchcmd", "", "Command that should be run after a batch of changes is processed")
 	upstreamCmd.Flags().StringSliceVar(&cmd.BatchArgs, "batchargs", []string{}, "Args that should be used for the command that is run after a batch of changes is processed")
+	upstreamCmd.Flags().StringArrayVar(&cmd.UploadRules, "uploadrule", []string{}, "Rule as json that runs a command or restarts the container after a batch of changes that contains a matching path")
+
+	upstreamCmd.Flags().StringVar(&cmd.Owner, "owner", "", "User name or id uploaded files and created directories should belong to")
+	upstreamCmd.Flags().StringVar(&cmd.Group, "group", "", "Group name or id uploaded files and created directories should belong to")
+	upstreamCmd.Flags().StringVar(&cmd.FileMode, "filemode", "", "Octal mode uploaded files should have (e.g. 0644)")
+	upstreamCmd.Flags().StringVar(&cmd.DirMode, "dirmode", "", "Octal mode created directories should have (e.g. 0755)")
+	upstreamCmd.Flags().BoolVar(&cmd.PreserveExecutable, "preserveexec", false, "If true the executable bits of uploaded files are set as they are locally")
+
+	upstreamCmd.Flags().StringVar(&cmd.Symlinks, "symlinks", "follow", "How symlinks are synced: follow, preserve or skip")
 
 	upstreamCmd.Flags().StringSliceVar(&cmd.Exclude, "exclude", []string{}, "The exclude paths for upstream watching")
 	return upstreamCmd
@@ -52,12 +73,36 @@ func (cmd *UpstreamCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return server.StartUpstreamServer(os.Stdin, os.Stdout, &server.UpstreamOptions{
+	owner, err := server.ParseOwner(cmd.Owner, cmd.Group)
+	if err != nil {
+		return err
+	}
+	fileMode, err := server.ParseMode(cmd.FileMode)
+	if err != nil {
+		return err
+	}
+	dirMode, err := server.ParseMode(cmd.DirMode)
+	if err != nil {
+		return err
+	}
+
+	uploadRules := make([]*server.UploadRule, 0, len(cmd.UploadRules))
+	for _, rule := range cmd.UploadRules {
+		uploadRule, err := server.ParseUploadRule(rule)
+		if err != nil {
+			return err
+		}
+
+		uploadRules = append(uploadRules, uploadRule)
+	}
+
+	return server.StartUpstreamServer(cmd.streams.Reader, cmd.streams.Writer, &server.UpstreamOptions{
 		UploadPath:  absolutePath,
 		ExludePaths: cmd.Exclude,
 
-		BatchCmd:  cmd.BatchCmd,
-		BatchArgs: cmd.BatchArgs,
+		BatchCmd:    cmd.BatchCmd,
+		BatchArgs:   cmd.BatchArgs,
+		UploadRules: uploadRules,
 
 		FileChangeCmd:  cmd.FileChangeCmd,
 		FileChangeArgs: cmd.FileChangeArgs,
@@ -65,7 +110,13 @@ func (cmd *UpstreamCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		DirCreateCmd:  cmd.DirCreateCmd,
 		DirCreateArgs: cmd.DirCreateArgs,
 
-		ExitOnClose: true,
+		Owner:              owner,
+		FileMode:           fileMode,
+		DirMode:            dirMode,
+		PreserveExecutable: cmd.PreserveExecutable,
+		Symlinks:           cmd.Symlinks,
+
+		ExitOnClose: cmd.streams.ExitOnClose,
 	})
 }
 
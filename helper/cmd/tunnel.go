@@ -14,7 +14,7 @@ func NewTunnelCmd() *cobra.Command {
 	cmd := &TunnelCmd{}
 	tunnelCmd := &cobra.Command{
 		Use:   "tunnel",
-		Short: "Starts a new tunnel for reverse port forwarding",
+		Short: "Starts a new tunnel for reverse and udp port forwarding",
 		Args:  cobra.NoArgs,
 		RunE:  cmd.Run,
 	}
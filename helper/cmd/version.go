@@ -2,12 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/spf13/cobra"
 	"os"
 )
 
-var version string
-
 // NewVersionCmd creates a new version command
 func NewVersionCmd() *cobra.Command {
 	versionCmd := &cobra.Command{
@@ -15,11 +14,7 @@ func NewVersionCmd() *cobra.Command {
 		Short: "Prints the cli version",
 		Args:  cobra.NoArgs,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
-			if version == "" {
-				version = "latest"
-			}
-
-			fmt.Fprint(os.Stdout, version)
+			fmt.Fprint(os.Stdout, util.GetVersion())
 			return nil
 		},
 	}
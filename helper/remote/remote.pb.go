@@ -160,6 +160,7 @@ type SocketDataRequest struct {
 	Scheme               TunnelScheme `protobuf:"varint,4,opt,name=scheme,proto3,enum=remote.TunnelScheme" json:"scheme,omitempty"`
 	Data                 []byte       `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
 	ShouldClose          bool         `protobuf:"varint,6,opt,name=shouldClose,proto3" json:"shouldClose,omitempty"`
+	Host                 string       `protobuf:"bytes,7,opt,name=host,proto3" json:"host,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
 	XXX_unrecognized     []byte       `json:"-"`
 	XXX_sizecache        int32        `json:"-"`
@@ -232,6 +233,13 @@ func (m *SocketDataRequest) GetShouldClose() bool {
 	return false
 }
 
+func (m *SocketDataRequest) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
 type SocketDataResponse struct {
 	HasErr               bool        `protobuf:"varint,1,opt,name=hasErr,proto3" json:"hasErr,omitempty"`
 	LogMessage           *LogMessage `protobuf:"bytes,2,opt,name=logMessage,proto3" json:"logMessage,omitempty"`
@@ -435,6 +443,8 @@ type Change struct {
 	MtimeUnixNano        int64      `protobuf:"varint,4,opt,name=MtimeUnixNano,proto3" json:"MtimeUnixNano,omitempty"`
 	Size                 int64      `protobuf:"varint,5,opt,name=Size,proto3" json:"Size,omitempty"`
 	IsDir                bool       `protobuf:"varint,6,opt,name=IsDir,proto3" json:"IsDir,omitempty"`
+	LinkTarget           string     `protobuf:"bytes,7,opt,name=LinkTarget,proto3" json:"LinkTarget,omitempty"`
+	SizeExceeded         bool       `protobuf:"varint,8,opt,name=SizeExceeded,proto3" json:"SizeExceeded,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
 	XXX_unrecognized     []byte     `json:"-"`
 	XXX_sizecache        int32      `json:"-"`
@@ -507,8 +517,463 @@ func (m *Change) GetIsDir() bool {
 	return false
 }
 
+func (m *Change) GetLinkTarget() string {
+	if m != nil {
+		return m.LinkTarget
+	}
+	return ""
+}
+
+func (m *Change) GetSizeExceeded() bool {
+	if m != nil {
+		return m.SizeExceeded
+	}
+	return false
+}
+
+type ChecksumChunk struct {
+	Checksums            []*Checksum `protobuf:"bytes,1,rep,name=checksums,proto3" json:"checksums,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ChecksumChunk) Reset()         { *m = ChecksumChunk{} }
+func (m *ChecksumChunk) String() string { return proto.CompactTextString(m) }
+func (*ChecksumChunk) ProtoMessage()    {}
+func (*ChecksumChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{7}
+}
+
+func (m *ChecksumChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChecksumChunk.Unmarshal(m, b)
+}
+func (m *ChecksumChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChecksumChunk.Marshal(b, m, deterministic)
+}
+func (m *ChecksumChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChecksumChunk.Merge(m, src)
+}
+func (m *ChecksumChunk) XXX_Size() int {
+	return xxx_messageInfo_ChecksumChunk.Size(m)
+}
+func (m *ChecksumChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChecksumChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChecksumChunk proto.InternalMessageInfo
+
+func (m *ChecksumChunk) GetChecksums() []*Checksum {
+	if m != nil {
+		return m.Checksums
+	}
+	return nil
+}
+
+type Checksum struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Sha256               string   `protobuf:"bytes,2,opt,name=Sha256,proto3" json:"Sha256,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Checksum) Reset()         { *m = Checksum{} }
+func (m *Checksum) String() string { return proto.CompactTextString(m) }
+func (*Checksum) ProtoMessage()    {}
+func (*Checksum) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{8}
+}
+
+func (m *Checksum) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Checksum.Unmarshal(m, b)
+}
+func (m *Checksum) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Checksum.Marshal(b, m, deterministic)
+}
+func (m *Checksum) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Checksum.Merge(m, src)
+}
+func (m *Checksum) XXX_Size() int {
+	return xxx_messageInfo_Checksum.Size(m)
+}
+func (m *Checksum) XXX_DiscardUnknown() {
+	xxx_messageInfo_Checksum.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Checksum proto.InternalMessageInfo
+
+func (m *Checksum) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Checksum) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+type SignatureRequest struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	BlockSize            int64    `protobuf:"varint,2,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignatureRequest) Reset()         { *m = SignatureRequest{} }
+func (m *SignatureRequest) String() string { return proto.CompactTextString(m) }
+func (*SignatureRequest) ProtoMessage()    {}
+func (*SignatureRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{9}
+}
+
+func (m *SignatureRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignatureRequest.Unmarshal(m, b)
+}
+func (m *SignatureRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignatureRequest.Marshal(b, m, deterministic)
+}
+func (m *SignatureRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignatureRequest.Merge(m, src)
+}
+func (m *SignatureRequest) XXX_Size() int {
+	return xxx_messageInfo_SignatureRequest.Size(m)
+}
+func (m *SignatureRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignatureRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignatureRequest proto.InternalMessageInfo
+
+func (m *SignatureRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *SignatureRequest) GetBlockSize() int64 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+type Signature struct {
+	Path                 string            `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	BlockSize            int64             `protobuf:"varint,2,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	Blocks               []*BlockSignature `protobuf:"bytes,3,rep,name=Blocks,proto3" json:"Blocks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Signature) Reset()         { *m = Signature{} }
+func (m *Signature) String() string { return proto.CompactTextString(m) }
+func (*Signature) ProtoMessage()    {}
+func (*Signature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{10}
+}
+
+func (m *Signature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Signature.Unmarshal(m, b)
+}
+func (m *Signature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Signature.Marshal(b, m, deterministic)
+}
+func (m *Signature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Signature.Merge(m, src)
+}
+func (m *Signature) XXX_Size() int {
+	return xxx_messageInfo_Signature.Size(m)
+}
+func (m *Signature) XXX_DiscardUnknown() {
+	xxx_messageInfo_Signature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Signature proto.InternalMessageInfo
+
+func (m *Signature) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Signature) GetBlockSize() int64 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+func (m *Signature) GetBlocks() []*BlockSignature {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+type BlockSignature struct {
+	Index                int64    `protobuf:"varint,1,opt,name=Index,proto3" json:"Index,omitempty"`
+	Weak                 uint32   `protobuf:"varint,2,opt,name=Weak,proto3" json:"Weak,omitempty"`
+	Strong               []byte   `protobuf:"bytes,3,opt,name=Strong,proto3" json:"Strong,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockSignature) Reset()         { *m = BlockSignature{} }
+func (m *BlockSignature) String() string { return proto.CompactTextString(m) }
+func (*BlockSignature) ProtoMessage()    {}
+func (*BlockSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{11}
+}
+
+func (m *BlockSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockSignature.Unmarshal(m, b)
+}
+func (m *BlockSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockSignature.Marshal(b, m, deterministic)
+}
+func (m *BlockSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockSignature.Merge(m, src)
+}
+func (m *BlockSignature) XXX_Size() int {
+	return xxx_messageInfo_BlockSignature.Size(m)
+}
+func (m *BlockSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockSignature proto.InternalMessageInfo
+
+func (m *BlockSignature) GetIndex() int64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *BlockSignature) GetWeak() uint32 {
+	if m != nil {
+		return m.Weak
+	}
+	return 0
+}
+
+func (m *BlockSignature) GetStrong() []byte {
+	if m != nil {
+		return m.Strong
+	}
+	return nil
+}
+
+type Delta struct {
+	Path                 string            `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	MtimeUnix            int64             `protobuf:"varint,2,opt,name=MtimeUnix,proto3" json:"MtimeUnix,omitempty"`
+	Size                 int64             `protobuf:"varint,3,opt,name=Size,proto3" json:"Size,omitempty"`
+	Sha256               string            `protobuf:"bytes,4,opt,name=Sha256,proto3" json:"Sha256,omitempty"`
+	BlockSize            int64             `protobuf:"varint,5,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	Operations           []*DeltaOperation `protobuf:"bytes,6,rep,name=Operations,proto3" json:"Operations,omitempty"`
+	Mode                 uint32            `protobuf:"varint,7,opt,name=Mode,proto3" json:"Mode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Delta) Reset()         { *m = Delta{} }
+func (m *Delta) String() string { return proto.CompactTextString(m) }
+func (*Delta) ProtoMessage()    {}
+func (*Delta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{12}
+}
+
+func (m *Delta) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Delta.Unmarshal(m, b)
+}
+func (m *Delta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Delta.Marshal(b, m, deterministic)
+}
+func (m *Delta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Delta.Merge(m, src)
+}
+func (m *Delta) XXX_Size() int {
+	return xxx_messageInfo_Delta.Size(m)
+}
+func (m *Delta) XXX_DiscardUnknown() {
+	xxx_messageInfo_Delta.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Delta proto.InternalMessageInfo
+
+func (m *Delta) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Delta) GetMtimeUnix() int64 {
+	if m != nil {
+		return m.MtimeUnix
+	}
+	return 0
+}
+
+func (m *Delta) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Delta) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+func (m *Delta) GetBlockSize() int64 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+func (m *Delta) GetOperations() []*DeltaOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+func (m *Delta) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+type DeltaOperation struct {
+	BlockIndex           int64    `protobuf:"varint,1,opt,name=BlockIndex,proto3" json:"BlockIndex,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeltaOperation) Reset()         { *m = DeltaOperation{} }
+func (m *DeltaOperation) String() string { return proto.CompactTextString(m) }
+func (*DeltaOperation) ProtoMessage()    {}
+func (*DeltaOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{13}
+}
+
+func (m *DeltaOperation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeltaOperation.Unmarshal(m, b)
+}
+func (m *DeltaOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeltaOperation.Marshal(b, m, deterministic)
+}
+func (m *DeltaOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeltaOperation.Merge(m, src)
+}
+func (m *DeltaOperation) XXX_Size() int {
+	return xxx_messageInfo_DeltaOperation.Size(m)
+}
+func (m *DeltaOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeltaOperation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeltaOperation proto.InternalMessageInfo
+
+func (m *DeltaOperation) GetBlockIndex() int64 {
+	if m != nil {
+		return m.BlockIndex
+	}
+	return 0
+}
+
+func (m *DeltaOperation) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type Capabilities struct {
+	Compressions         []string `protobuf:"bytes,1,rep,name=Compressions,proto3" json:"Compressions,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=Version,proto3" json:"Version,omitempty"`
+	WatchMode            string   `protobuf:"bytes,3,opt,name=WatchMode,proto3" json:"WatchMode,omitempty"`
+	Features             []string `protobuf:"bytes,4,rep,name=Features,proto3" json:"Features,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Capabilities) Reset()         { *m = Capabilities{} }
+func (m *Capabilities) String() string { return proto.CompactTextString(m) }
+func (*Capabilities) ProtoMessage()    {}
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{14}
+}
+
+func (m *Capabilities) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Capabilities.Unmarshal(m, b)
+}
+func (m *Capabilities) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Capabilities.Marshal(b, m, deterministic)
+}
+func (m *Capabilities) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Capabilities.Merge(m, src)
+}
+func (m *Capabilities) XXX_Size() int {
+	return xxx_messageInfo_Capabilities.Size(m)
+}
+func (m *Capabilities) XXX_DiscardUnknown() {
+	xxx_messageInfo_Capabilities.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Capabilities proto.InternalMessageInfo
+
+func (m *Capabilities) GetCompressions() []string {
+	if m != nil {
+		return m.Compressions
+	}
+	return nil
+}
+
+func (m *Capabilities) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Capabilities) GetWatchMode() string {
+	if m != nil {
+		return m.WatchMode
+	}
+	return ""
+}
+
+func (m *Capabilities) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
 type Paths struct {
 	Paths                []string `protobuf:"bytes,1,rep,name=Paths,proto3" json:"Paths,omitempty"`
+	Compression          string   `protobuf:"bytes,2,opt,name=Compression,proto3" json:"Compression,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -518,7 +983,7 @@ func (m *Paths) Reset()         { *m = Paths{} }
 func (m *Paths) String() string { return proto.CompactTextString(m) }
 func (*Paths) ProtoMessage()    {}
 func (*Paths) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{7}
+	return fileDescriptor_eefc82927d57d89b, []int{15}
 }
 
 func (m *Paths) XXX_Unmarshal(b []byte) error {
@@ -546,6 +1011,13 @@ func (m *Paths) GetPaths() []string {
 	return nil
 }
 
+func (m *Paths) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
 type Chunk struct {
 	Content              []byte   `protobuf:"bytes,1,opt,name=Content,proto3" json:"Content,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -557,7 +1029,7 @@ func (m *Chunk) Reset()         { *m = Chunk{} }
 func (m *Chunk) String() string { return proto.CompactTextString(m) }
 func (*Chunk) ProtoMessage()    {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{8}
+	return fileDescriptor_eefc82927d57d89b, []int{16}
 }
 
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
@@ -595,7 +1067,7 @@ func (m *Empty) Reset()         { *m = Empty{} }
 func (m *Empty) String() string { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()    {}
 func (*Empty) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{9}
+	return fileDescriptor_eefc82927d57d89b, []int{17}
 }
 
 func (m *Empty) XXX_Unmarshal(b []byte) error {
@@ -627,6 +1099,14 @@ func init() {
 	proto.RegisterType((*ChangeAmount)(nil), "remote.ChangeAmount")
 	proto.RegisterType((*ChangeChunk)(nil), "remote.ChangeChunk")
 	proto.RegisterType((*Change)(nil), "remote.Change")
+	proto.RegisterType((*ChecksumChunk)(nil), "remote.ChecksumChunk")
+	proto.RegisterType((*Checksum)(nil), "remote.Checksum")
+	proto.RegisterType((*SignatureRequest)(nil), "remote.SignatureRequest")
+	proto.RegisterType((*Signature)(nil), "remote.Signature")
+	proto.RegisterType((*BlockSignature)(nil), "remote.BlockSignature")
+	proto.RegisterType((*Delta)(nil), "remote.Delta")
+	proto.RegisterType((*DeltaOperation)(nil), "remote.DeltaOperation")
+	proto.RegisterType((*Capabilities)(nil), "remote.Capabilities")
 	proto.RegisterType((*Paths)(nil), "remote.Paths")
 	proto.RegisterType((*Chunk)(nil), "remote.Chunk")
 	proto.RegisterType((*Empty)(nil), "remote.Empty")
@@ -635,53 +1115,81 @@ func init() {
 func init() { proto.RegisterFile("remote.proto", fileDescriptor_eefc82927d57d89b) }
 
 var fileDescriptor_eefc82927d57d89b = []byte{
-	// 730 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x41, 0x6f, 0xf2, 0x46,
-	0x10, 0x65, 0x63, 0x6c, 0x60, 0x20, 0x91, 0xbb, 0x8d, 0x2a, 0x17, 0xa5, 0x12, 0xb5, 0xa2, 0xc8,
-	0x42, 0x51, 0x94, 0xba, 0x8a, 0x7a, 0x4e, 0xc0, 0x4d, 0x91, 0x08, 0x41, 0x0b, 0x34, 0x67, 0x17,
-	0x56, 0x80, 0x62, 0x7b, 0xa9, 0x77, 0x49, 0x93, 0xfe, 0xae, 0x9e, 0xf3, 0x13, 0x7a, 0xed, 0xdf,
-	0xa9, 0x76, 0xd7, 0x06, 0x3b, 0xf9, 0xa2, 0x7c, 0xb7, 0x79, 0x33, 0xf3, 0x86, 0x79, 0x6f, 0xc7,
-	0x02, 0x5a, 0x29, 0x8d, 0x99, 0xa0, 0x17, 0x9b, 0x94, 0x09, 0x86, 0x2d, 0x8d, 0xdc, 0x29, 0xc0,
-	0x90, 0x2d, 0xef, 0x28, 0xe7, 0xe1, 0x92, 0xe2, 0x73, 0xa8, 0x47, 0x6c, 0x39, 0xa4, 0x4f, 0x34,
-	0x72, 0x50, 0x07, 0x79, 0x47, 0xbe, 0x7d, 0x91, 0xd1, 0x86, 0x59, 0x9e, 0xec, 0x3a, 0xb0, 0x03,
-	0xb5, 0x58, 0x13, 0x9d, 0x83, 0x0e, 0xf2, 0x1a, 0x24, 0x87, 0xee, 0x7f, 0x08, 0xbe, 0x99, 0xb0,
-	0xf9, 0x23, 0x15, 0xfd, 0x50, 0x84, 0x84, 0xfe, 0xb9, 0xa5, 0x5c, 0x60, 0x0c, 0xd5, 0x0d, 0x4b,
-	0x85, 0x9a, 0x6c, 0x12, 0x15, 0xe3, 0x13, 0x68, 0xa4, 0xba, 0x3c, 0x58, 0x64, 0x53, 0xf6, 0x89,
-	0xd2, 0x3e, 0xc6, 0xa7, 0xfb, 0x9c, 0x83, 0xc5, 0xe7, 0x2b, 0x1a, 0x53, 0xa7, 0xaa, 0x7a, 0x8f,
-	0xf3, 0xde, 0xe9, 0x36, 0x49, 0x68, 0x34, 0x51, 0x35, 0x92, 0xf5, 0xc8, 0x6d, 0x16, 0xa1, 0x08,
-	0x1d, 0xb3, 0x83, 0xbc, 0x16, 0x51, 0x31, 0xee, 0x40, 0x93, 0xaf, 0xd8, 0x36, 0x5a, 0xf4, 0x22,
-	0xc6, 0xa9, 0x63, 0x75, 0x90, 0x57, 0x27, 0xc5, 0x94, 0xfb, 0x0f, 0x02, 0x5c, 0x54, 0xc6, 0x37,
-	0x2c, 0xe1, 0x14, 0x7f, 0x07, 0xd6, 0x2a, 0xe4, 0x41, 0x9a, 0x2a, 0x71, 0x75, 0x92, 0x21, 0xec,
-	0x03, 0x44, 0x3b, 0x7b, 0x95, 0xbe, 0xa6, 0x8f, 0x0b, 0x12, 0xb2, 0x0a, 0x29, 0x74, 0x95, 0x2d,
-	0x31, 0xde, 0x5a, 0x92, 0xaf, 0x5d, 0xfd, 0x78, 0x6d, 0xf3, 0xfd, 0xda, 0x57, 0x60, 0x3e, 0x84,
-	0x62, 0xbe, 0x92, 0xf4, 0x71, 0x28, 0x56, 0x6a, 0xcd, 0x06, 0x51, 0xb1, 0x7c, 0xc7, 0xe0, 0x79,
-	0x1e, 0x6d, 0x17, 0x72, 0x43, 0x43, 0xbe, 0x63, 0x06, 0xdd, 0x33, 0x68, 0xf5, 0x56, 0x61, 0xb2,
-	0xa4, 0xd7, 0x31, 0xdb, 0x26, 0x42, 0xca, 0xd4, 0x91, 0xe2, 0x1b, 0x24, 0x43, 0xee, 0x2f, 0xd0,
-	0xd4, 0x7d, 0xbd, 0xd5, 0x36, 0x79, 0xc4, 0x1e, 0xd4, 0xe6, 0x0a, 0x72, 0x07, 0x75, 0x0c, 0xaf,
-	0xe9, 0x1f, 0xe5, 0x92, 0x75, 0x17, 0xc9, 0xcb, 0xee, 0x2b, 0x02, 0x4b, 0xe7, 0xa4, 0x55, 0x3a,
-	0x9a, 0xbe, 0x6c, 0x68, 0x76, 0x7d, 0xb8, 0xcc, 0x93, 0x15, 0x52, 0xe8, 0xda, 0xa9, 0x39, 0x28,
-	0xa8, 0x39, 0x81, 0xc6, 0x9d, 0x58, 0xc7, 0x74, 0x96, 0xac, 0x9f, 0x95, 0x7d, 0x06, 0xd9, 0x27,
-	0xf0, 0x29, 0x1c, 0xee, 0xc0, 0x28, 0x4c, 0x98, 0xf2, 0xd1, 0x20, 0xe5, 0xa4, 0x9c, 0x3b, 0x59,
-	0xff, 0xad, 0x9d, 0x34, 0x88, 0x8a, 0xf1, 0x31, 0x98, 0x03, 0xde, 0x5f, 0xa7, 0xd9, 0x55, 0x68,
-	0xe0, 0xfe, 0x00, 0xa6, 0xfc, 0x55, 0x2e, 0xcb, 0x2a, 0x50, 0x8a, 0x1b, 0x44, 0x03, 0xf7, 0x47,
-	0x30, 0xb5, 0x25, 0x0e, 0xd4, 0x7a, 0x2c, 0x11, 0x34, 0xb3, 0xae, 0x45, 0x72, 0xe8, 0xd6, 0xc0,
-	0x0c, 0xe2, 0x8d, 0x78, 0xe9, 0xf6, 0xa1, 0x9e, 0x1f, 0x35, 0xae, 0x43, 0x75, 0x30, 0xfa, 0xf5,
-	0xde, 0xae, 0xe0, 0x26, 0xd4, 0x7e, 0x0f, 0xc8, 0xcd, 0xfd, 0x24, 0xb0, 0x11, 0x6e, 0x80, 0xd9,
-	0x0f, 0x6e, 0x66, 0xb7, 0xf6, 0x81, 0xcc, 0x3f, 0x5c, 0x93, 0xd1, 0x60, 0x74, 0x6b, 0x1b, 0x32,
-	0x1f, 0x10, 0x72, 0x4f, 0xec, 0x6a, 0xb7, 0x03, 0xad, 0xe2, 0xb9, 0xe3, 0x1a, 0x18, 0xd3, 0xde,
-	0xd8, 0xae, 0xc8, 0x60, 0xd6, 0x1f, 0xdb, 0xa8, 0x7b, 0x5a, 0x34, 0x1a, 0x03, 0x58, 0xbd, 0xdf,
-	0xae, 0x47, 0xb7, 0x81, 0x5d, 0x91, 0x71, 0x3f, 0x18, 0x06, 0xd3, 0xc0, 0x46, 0xfe, 0x04, 0x2c,
-	0x3d, 0x07, 0x0f, 0x00, 0x06, 0xc9, 0x5a, 0x64, 0xe8, 0xfb, 0xfc, 0x49, 0xde, 0x7d, 0xdf, 0xed,
-	0xf6, 0x97, 0x4a, 0xfa, 0x03, 0x71, 0x2b, 0x1e, 0xba, 0x44, 0xfe, 0xbf, 0x08, 0xa0, 0xcf, 0xfe,
-	0x4a, 0xb8, 0x48, 0x69, 0x18, 0xe3, 0x0b, 0xa8, 0x4b, 0x14, 0xb1, 0x70, 0x81, 0x0f, 0x73, 0xb2,
-	0x32, 0xae, 0x7d, 0xb8, 0x7f, 0xf9, 0x6d, 0xf2, 0xa8, 0xe9, 0xf8, 0x27, 0xa8, 0xe9, 0xcd, 0xf9,
-	0xbe, 0x5d, 0x79, 0xd7, 0xfe, 0xb6, 0x7c, 0x28, 0x19, 0xe9, 0x12, 0xe1, 0xab, 0xfc, 0x82, 0x79,
-	0x4f, 0x5d, 0xf0, 0x1b, 0xde, 0x71, 0x99, 0x97, 0x9d, 0x73, 0x05, 0x9f, 0x41, 0x75, 0xbc, 0x4e,
-	0x96, 0x6f, 0xdb, 0xcb, 0xd0, 0xad, 0xf8, 0xaf, 0x08, 0xea, 0xb3, 0x4d, 0x26, 0xa7, 0x0b, 0xd6,
-	0x6c, 0x53, 0x16, 0xa3, 0x16, 0x79, 0x47, 0xf3, 0x10, 0xf6, 0xc1, 0x26, 0x94, 0x8b, 0x30, 0x15,
-	0xf2, 0x0e, 0xc2, 0x75, 0x42, 0xd3, 0xcf, 0x7e, 0x4c, 0xce, 0x27, 0x34, 0x66, 0x4f, 0xf4, 0x43,
-	0xb3, 0xf6, 0xf3, 0xbf, 0x52, 0xc0, 0x1f, 0x96, 0xfa, 0x3b, 0xf8, 0xf9, 0xff, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0x13, 0x5a, 0x36, 0x87, 0x1e, 0x06, 0x00, 0x00,
+	// 1171 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0x51, 0x6f, 0xe2, 0xc6,
+	0x13, 0xc7, 0x18, 0x0c, 0x0c, 0x10, 0xf9, 0xf6, 0x9f, 0xff, 0xc9, 0x8d, 0x4e, 0x15, 0xb5, 0x4e,
+	0x27, 0x94, 0x9e, 0xe8, 0x95, 0x2a, 0xb9, 0x3e, 0x54, 0x3a, 0x25, 0xe0, 0xa4, 0x48, 0x09, 0x89,
+	0x16, 0x72, 0x79, 0xde, 0x83, 0x2d, 0x58, 0x18, 0xaf, 0xeb, 0x5d, 0xee, 0x92, 0x3e, 0xf6, 0xa1,
+	0xcf, 0x7d, 0xef, 0x27, 0xe8, 0x7b, 0xbf, 0x47, 0x3f, 0x48, 0xbf, 0x44, 0xb5, 0xeb, 0x35, 0xd8,
+	0x90, 0xe8, 0xae, 0xaa, 0xfa, 0x36, 0xf3, 0xdb, 0x99, 0xd9, 0x99, 0xdf, 0x8c, 0x67, 0x0d, 0x8d,
+	0x98, 0x2e, 0x99, 0xa0, 0x9d, 0x28, 0x66, 0x82, 0x21, 0x2b, 0xd1, 0xdc, 0x31, 0xc0, 0x05, 0x9b,
+	0x5d, 0x52, 0xce, 0xc9, 0x8c, 0xa2, 0x97, 0x50, 0x0d, 0xd8, 0xec, 0x82, 0xbe, 0xa7, 0x81, 0x63,
+	0xb4, 0x8c, 0xf6, 0x5e, 0xd7, 0xee, 0x68, 0xb7, 0x0b, 0x8d, 0xe3, 0xb5, 0x05, 0x72, 0xa0, 0xb2,
+	0x4c, 0x1c, 0x9d, 0x62, 0xcb, 0x68, 0xd7, 0x70, 0xaa, 0xba, 0x7f, 0x19, 0xf0, 0x64, 0xc4, 0x26,
+	0x0b, 0x2a, 0xfa, 0x44, 0x10, 0x4c, 0x7f, 0x5c, 0x51, 0x2e, 0x10, 0x82, 0x52, 0xc4, 0x62, 0xa1,
+	0x22, 0x97, 0xb1, 0x92, 0xd1, 0x33, 0xa8, 0xc5, 0xc9, 0xf1, 0x60, 0xaa, 0xa3, 0x6c, 0x80, 0x5c,
+	0x3e, 0xe6, 0x47, 0xf3, 0x79, 0x09, 0x16, 0x9f, 0xcc, 0xe9, 0x92, 0x3a, 0x25, 0x65, 0xbb, 0x9f,
+	0xda, 0x8e, 0x57, 0x61, 0x48, 0x83, 0x91, 0x3a, 0xc3, 0xda, 0x46, 0x66, 0x33, 0x25, 0x82, 0x38,
+	0xe5, 0x96, 0xd1, 0x6e, 0x60, 0x25, 0xa3, 0x16, 0xd4, 0xf9, 0x9c, 0xad, 0x82, 0x69, 0x2f, 0x60,
+	0x9c, 0x3a, 0x56, 0xcb, 0x68, 0x57, 0x71, 0x16, 0x92, 0x5e, 0x73, 0xc6, 0x85, 0x53, 0x51, 0xa9,
+	0x2a, 0xd9, 0xfd, 0xc3, 0x00, 0x94, 0xad, 0x96, 0x47, 0x2c, 0xe4, 0x14, 0x3d, 0x05, 0x6b, 0x4e,
+	0xb8, 0x17, 0xc7, 0xaa, 0xe0, 0x2a, 0xd6, 0x1a, 0xea, 0x02, 0x04, 0x6b, 0xca, 0x55, 0xcd, 0xf5,
+	0x2e, 0xca, 0x94, 0xa5, 0x4f, 0x70, 0xc6, 0x2a, 0x4f, 0x93, 0xb9, 0x4d, 0x53, 0x5a, 0x4a, 0xe9,
+	0xf1, 0x52, 0xca, 0x3b, 0xa5, 0xb8, 0x47, 0x50, 0xbe, 0x25, 0x62, 0x32, 0x97, 0xee, 0xd7, 0x44,
+	0xcc, 0x55, 0x9a, 0x35, 0xac, 0x64, 0xd9, 0x5b, 0xef, 0x6e, 0x12, 0xac, 0xa6, 0x32, 0x43, 0x53,
+	0xf6, 0x56, 0xab, 0xee, 0x0b, 0x68, 0xf4, 0xe6, 0x24, 0x9c, 0xd1, 0x93, 0x25, 0x5b, 0x85, 0x42,
+	0x96, 0x99, 0x48, 0xca, 0xdf, 0xc4, 0x5a, 0x73, 0x5f, 0x43, 0x3d, 0xb1, 0xeb, 0xcd, 0x57, 0xe1,
+	0x02, 0xb5, 0xa1, 0x32, 0x51, 0x2a, 0x77, 0x8c, 0x96, 0xd9, 0xae, 0x77, 0xf7, 0xd2, 0x92, 0x13,
+	0x2b, 0x9c, 0x1e, 0xbb, 0x3f, 0x17, 0xc1, 0x4a, 0x30, 0x49, 0x55, 0x22, 0x8d, 0xef, 0x23, 0xaa,
+	0x27, 0x12, 0xe5, 0xfd, 0xe4, 0x09, 0xce, 0x58, 0xad, 0xab, 0x29, 0x66, 0xaa, 0x79, 0x06, 0xb5,
+	0x4b, 0xe1, 0x2f, 0xe9, 0x4d, 0xe8, 0xdf, 0x29, 0xfa, 0x4c, 0xbc, 0x01, 0xd0, 0x73, 0x68, 0xae,
+	0x95, 0x21, 0x09, 0x99, 0xe2, 0xd1, 0xc4, 0x79, 0x50, 0xc6, 0x1d, 0xf9, 0x3f, 0x25, 0x4c, 0x9a,
+	0x58, 0xc9, 0x68, 0x1f, 0xca, 0x03, 0xde, 0xf7, 0x63, 0x3d, 0x29, 0x89, 0x82, 0x3e, 0x07, 0xb8,
+	0xf0, 0xc3, 0xc5, 0x98, 0xc4, 0x33, 0x9a, 0x4e, 0x4a, 0x06, 0x41, 0x2e, 0x34, 0xa4, 0xb7, 0x77,
+	0x37, 0xa1, 0x74, 0x4a, 0xa7, 0x4e, 0x55, 0x39, 0xe7, 0x30, 0xf7, 0x0d, 0x34, 0x7b, 0x73, 0x3a,
+	0x59, 0xf0, 0xd5, 0x32, 0xe1, 0xaf, 0x03, 0xb5, 0x89, 0x06, 0x52, 0x06, 0xed, 0x0d, 0x13, 0xc9,
+	0x01, 0xde, 0x98, 0xb8, 0xc7, 0x50, 0x4d, 0xe1, 0x07, 0x1b, 0xfc, 0x14, 0xac, 0xd1, 0x9c, 0x74,
+	0x8f, 0x8e, 0x35, 0x51, 0x5a, 0x73, 0xfb, 0x60, 0x8f, 0xfc, 0x59, 0x48, 0xc4, 0x2a, 0xa6, 0x99,
+	0x0f, 0x77, 0xc7, 0xff, 0x19, 0xd4, 0x4e, 0x03, 0x36, 0x59, 0x28, 0x4e, 0x8a, 0x09, 0xa5, 0x6b,
+	0xc0, 0x5d, 0x42, 0x6d, 0x1d, 0xe5, 0x9f, 0xbb, 0xa3, 0x0e, 0x58, 0x4a, 0xe1, 0x8e, 0xa9, 0x2a,
+	0x7d, 0x9a, 0x56, 0xaa, 0x4d, 0xd2, 0xfc, 0xb4, 0x95, 0x8b, 0x61, 0x2f, 0x7f, 0xa2, 0x3a, 0x13,
+	0x4e, 0xe9, 0x9d, 0x1e, 0xca, 0x44, 0x91, 0x99, 0xdc, 0x52, 0xb2, 0x50, 0x17, 0x36, 0xb1, 0x92,
+	0x15, 0x11, 0x22, 0x66, 0xe1, 0x4c, 0x0d, 0x46, 0x03, 0x6b, 0xcd, 0xfd, 0xd3, 0x80, 0x72, 0x9f,
+	0x06, 0x82, 0x3c, 0x96, 0xff, 0x66, 0xa2, 0x8a, 0xdb, 0x13, 0x95, 0xce, 0x8a, 0x99, 0x99, 0x95,
+	0x0d, 0xe1, 0xa5, 0x2c, 0xe1, 0x79, 0x26, 0xca, 0xdb, 0x4c, 0x1c, 0x03, 0x5c, 0x45, 0x34, 0x26,
+	0xc2, 0x67, 0x21, 0x77, 0xac, 0x3c, 0x1b, 0x2a, 0xbd, 0xf5, 0x31, 0xce, 0x58, 0xca, 0x0c, 0x2e,
+	0xd9, 0x94, 0xaa, 0xe9, 0x6b, 0x62, 0x25, 0xbb, 0x7d, 0xd8, 0xcb, 0x7b, 0xc8, 0x49, 0x55, 0x57,
+	0x65, 0xa9, 0xca, 0x20, 0x32, 0x8a, 0x5c, 0x69, 0xaa, 0xc0, 0x06, 0x56, 0xb2, 0xfb, 0x8b, 0x01,
+	0x8d, 0x1e, 0x89, 0xc8, 0x3b, 0x3f, 0xf0, 0x85, 0x4f, 0xb9, 0x1c, 0xe7, 0x1e, 0x5b, 0x46, 0x31,
+	0xe5, 0x5c, 0x25, 0x69, 0xa8, 0x7d, 0x91, 0xc3, 0xe4, 0x3a, 0x79, 0x4b, 0x63, 0x29, 0xa7, 0x4f,
+	0x85, 0x56, 0x65, 0xf9, 0x6a, 0x0b, 0xa9, 0x6c, 0xf5, 0x66, 0x5b, 0x03, 0xe8, 0x00, 0xaa, 0x67,
+	0x54, 0x75, 0x94, 0x3b, 0x25, 0x15, 0x77, 0xad, 0xbb, 0x6f, 0xa0, 0x2c, 0x5b, 0xc1, 0xd1, 0xbe,
+	0x16, 0xf4, 0xcd, 0x1a, 0x6d, 0x41, 0x3d, 0x93, 0x82, 0xbe, 0x36, 0x0b, 0xb9, 0x5f, 0x40, 0x39,
+	0xf9, 0xb6, 0x1c, 0xa8, 0xf4, 0x58, 0x28, 0xa8, 0xde, 0x61, 0x0d, 0x9c, 0xaa, 0x6e, 0x05, 0xca,
+	0xde, 0x32, 0x12, 0xf7, 0x87, 0x7d, 0xa8, 0xa6, 0x2f, 0x0e, 0xaa, 0x42, 0x69, 0x30, 0x3c, 0xbb,
+	0xb2, 0x0b, 0xa8, 0x0e, 0x95, 0xb7, 0x1e, 0x3e, 0xbd, 0x1a, 0x79, 0xb6, 0x81, 0x6a, 0x50, 0xee,
+	0x7b, 0xa7, 0x37, 0xe7, 0x76, 0x51, 0xe2, 0xb7, 0x27, 0x78, 0x38, 0x18, 0x9e, 0xdb, 0xa6, 0xc4,
+	0x3d, 0x8c, 0xaf, 0xb0, 0x5d, 0x3a, 0x6c, 0x41, 0x23, 0xfb, 0x16, 0xa1, 0x0a, 0x98, 0xe3, 0xde,
+	0xb5, 0x5d, 0x90, 0xc2, 0x4d, 0xff, 0xda, 0x36, 0x0e, 0x9f, 0x67, 0x37, 0x1e, 0x02, 0xb0, 0x7a,
+	0xdf, 0x9f, 0x0c, 0xcf, 0x3d, 0xbb, 0x20, 0xe5, 0xbe, 0x77, 0xe1, 0x8d, 0x3d, 0xdb, 0xe8, 0xfe,
+	0x66, 0x80, 0x95, 0x04, 0x42, 0x03, 0x80, 0x41, 0xe8, 0x0b, 0xad, 0x7d, 0x96, 0x8e, 0xc6, 0xce,
+	0xeb, 0x7b, 0x70, 0xf0, 0xd0, 0x51, 0xf2, 0x54, 0xb9, 0x85, 0xb6, 0xf1, 0xca, 0x40, 0x67, 0x50,
+	0x39, 0x63, 0xf1, 0x07, 0x12, 0x4f, 0xff, 0x55, 0x9c, 0xee, 0xef, 0x26, 0x40, 0x9f, 0x7d, 0x08,
+	0xb9, 0x88, 0x29, 0x59, 0xa2, 0x0e, 0x54, 0xa5, 0x16, 0x30, 0x32, 0x45, 0xcd, 0xd4, 0x59, 0xf5,
+	0xe8, 0xa0, 0xb9, 0xd9, 0x60, 0xab, 0x70, 0xa1, 0xd3, 0xf8, 0x1a, 0x2a, 0x09, 0x05, 0x7c, 0x63,
+	0xae, 0x9a, 0x70, 0xf0, 0xbf, 0xfc, 0xea, 0xd7, 0x4e, 0xaf, 0x0c, 0x74, 0x94, 0xbe, 0x49, 0xbc,
+	0xa7, 0xde, 0xa4, 0x2d, 0xbf, 0xfd, 0xbc, 0x9f, 0x7e, 0xa0, 0x0a, 0xe8, 0x5b, 0x68, 0x26, 0x08,
+	0x1f, 0x32, 0xe1, 0xff, 0x70, 0xff, 0x89, 0x7e, 0xaf, 0x0c, 0xf4, 0x1a, 0x6a, 0xe9, 0x76, 0xe5,
+	0xdb, 0x45, 0xfd, 0x7f, 0x7b, 0x2d, 0x67, 0x8b, 0xfb, 0x2a, 0x5d, 0x2a, 0x4f, 0xd6, 0x34, 0xa6,
+	0x3b, 0x6b, 0xc3, 0x86, 0xb2, 0xd0, 0x0e, 0x47, 0x5b, 0x5f, 0xdb, 0xa3, 0x29, 0x66, 0x8c, 0xdc,
+	0x02, 0x7a, 0x01, 0xa5, 0x6b, 0x3f, 0x9c, 0x6d, 0x9b, 0xe7, 0x55, 0xb7, 0xd0, 0xfd, 0xd5, 0x84,
+	0xea, 0x4d, 0xa4, 0x3b, 0x75, 0x08, 0xd6, 0x4d, 0x94, 0xef, 0x93, 0xca, 0x7d, 0xc7, 0xad, 0x6d,
+	0xa0, 0x2e, 0xd8, 0x98, 0x72, 0x41, 0x62, 0x21, 0xbf, 0x15, 0xe2, 0x87, 0x34, 0xfe, 0xd8, 0x65,
+	0x32, 0x3e, 0xa6, 0x4b, 0xf6, 0x9e, 0x3e, 0x3a, 0x07, 0x9b, 0xf8, 0xdf, 0x65, 0x5f, 0x10, 0x67,
+	0x87, 0xac, 0x74, 0x1a, 0x77, 0x69, 0x54, 0xfd, 0xe9, 0x00, 0x9c, 0x44, 0x51, 0x70, 0x9f, 0x70,
+	0x9d, 0x27, 0xf6, 0xa1, 0xdb, 0xbe, 0x94, 0xbf, 0x3b, 0x74, 0xb2, 0x12, 0x9f, 0x92, 0xda, 0x7f,
+	0xdb, 0x92, 0x77, 0x96, 0xfa, 0x43, 0xff, 0xe6, 0xef, 0x01, 0x00, 0xb6, 0xd0, 0x30, 0x31, 0xb1,
+	0x0b, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -697,6 +1205,7 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type TunnelClient interface {
 	InitTunnel(ctx context.Context, opts ...grpc.CallOption) (Tunnel_InitTunnelClient, error)
+	Forward(ctx context.Context, opts ...grpc.CallOption) (Tunnel_ForwardClient, error)
 }
 
 type tunnelClient struct {
@@ -738,9 +1247,41 @@ func (x *tunnelInitTunnelClient) Recv() (*SocketDataResponse, error) {
 	return m, nil
 }
 
+func (c *tunnelClient) Forward(ctx context.Context, opts ...grpc.CallOption) (Tunnel_ForwardClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Tunnel_serviceDesc.Streams[1], "/remote.Tunnel/Forward", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tunnelForwardClient{stream}
+	return x, nil
+}
+
+type Tunnel_ForwardClient interface {
+	Send(*SocketDataRequest) error
+	Recv() (*SocketDataResponse, error)
+	grpc.ClientStream
+}
+
+type tunnelForwardClient struct {
+	grpc.ClientStream
+}
+
+func (x *tunnelForwardClient) Send(m *SocketDataRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *tunnelForwardClient) Recv() (*SocketDataResponse, error) {
+	m := new(SocketDataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TunnelServer is the server API for Tunnel service.
 type TunnelServer interface {
 	InitTunnel(Tunnel_InitTunnelServer) error
+	Forward(Tunnel_ForwardServer) error
 }
 
 func RegisterTunnelServer(s *grpc.Server, srv TunnelServer) {
@@ -773,6 +1314,32 @@ func (x *tunnelInitTunnelServer) Recv() (*SocketDataRequest, error) {
 	return m, nil
 }
 
+func _Tunnel_Forward_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TunnelServer).Forward(&tunnelForwardServer{stream})
+}
+
+type Tunnel_ForwardServer interface {
+	Send(*SocketDataResponse) error
+	Recv() (*SocketDataRequest, error)
+	grpc.ServerStream
+}
+
+type tunnelForwardServer struct {
+	grpc.ServerStream
+}
+
+func (x *tunnelForwardServer) Send(m *SocketDataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *tunnelForwardServer) Recv() (*SocketDataRequest, error) {
+	m := new(SocketDataRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _Tunnel_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "remote.Tunnel",
 	HandlerType: (*TunnelServer)(nil),
@@ -784,6 +1351,12 @@ var _Tunnel_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Forward",
+			Handler:       _Tunnel_Forward_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "remote.proto",
 }
@@ -795,6 +1368,10 @@ type DownstreamClient interface {
 	Download(ctx context.Context, opts ...grpc.CallOption) (Downstream_DownloadClient, error)
 	Changes(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_ChangesClient, error)
 	ChangesCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChangeAmount, error)
+	ChangesNotify(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_ChangesNotifyClient, error)
+	Checksums(ctx context.Context, opts ...grpc.CallOption) (Downstream_ChecksumsClient, error)
+	Delta(ctx context.Context, opts ...grpc.CallOption) (Downstream_DeltaClient, error)
+	Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Capabilities, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 }
 
@@ -846,32 +1423,135 @@ func (c *downstreamClient) Changes(ctx context.Context, in *Empty, opts ...grpc.
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Downstream_ChangesClient interface {
+	Recv() (*ChangeChunk, error)
+	grpc.ClientStream
+}
+
+type downstreamChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *downstreamChangesClient) Recv() (*ChangeChunk, error) {
+	m := new(ChangeChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *downstreamClient) ChangesCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChangeAmount, error) {
+	out := new(ChangeAmount)
+	err := c.cc.Invoke(ctx, "/remote.Downstream/ChangesCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *downstreamClient) ChangesNotify(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_ChangesNotifyClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Downstream_serviceDesc.Streams[2], "/remote.Downstream/ChangesNotify", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &downstreamChangesNotifyClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Downstream_ChangesNotifyClient interface {
+	Recv() (*ChangeAmount, error)
+	grpc.ClientStream
+}
+
+type downstreamChangesNotifyClient struct {
+	grpc.ClientStream
+}
+
+func (x *downstreamChangesNotifyClient) Recv() (*ChangeAmount, error) {
+	m := new(ChangeAmount)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *downstreamClient) Checksums(ctx context.Context, opts ...grpc.CallOption) (Downstream_ChecksumsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Downstream_serviceDesc.Streams[3], "/remote.Downstream/Checksums", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &downstreamChecksumsClient{stream}
+	return x, nil
+}
+
+type Downstream_ChecksumsClient interface {
+	Send(*Paths) error
+	Recv() (*ChecksumChunk, error)
+	grpc.ClientStream
+}
+
+type downstreamChecksumsClient struct {
+	grpc.ClientStream
+}
+
+func (x *downstreamChecksumsClient) Send(m *Paths) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *downstreamChecksumsClient) Recv() (*ChecksumChunk, error) {
+	m := new(ChecksumChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *downstreamClient) Delta(ctx context.Context, opts ...grpc.CallOption) (Downstream_DeltaClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Downstream_serviceDesc.Streams[4], "/remote.Downstream/Delta", opts...)
+	if err != nil {
 		return nil, err
 	}
+	x := &downstreamDeltaClient{stream}
 	return x, nil
 }
 
-type Downstream_ChangesClient interface {
-	Recv() (*ChangeChunk, error)
+type Downstream_DeltaClient interface {
+	Send(*Signature) error
+	Recv() (*Delta, error)
 	grpc.ClientStream
 }
 
-type downstreamChangesClient struct {
+type downstreamDeltaClient struct {
 	grpc.ClientStream
 }
 
-func (x *downstreamChangesClient) Recv() (*ChangeChunk, error) {
-	m := new(ChangeChunk)
+func (x *downstreamDeltaClient) Send(m *Signature) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *downstreamDeltaClient) Recv() (*Delta, error) {
+	m := new(Delta)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *downstreamClient) ChangesCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChangeAmount, error) {
-	out := new(ChangeAmount)
-	err := c.cc.Invoke(ctx, "/remote.Downstream/ChangesCount", in, out, opts...)
+func (c *downstreamClient) Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Capabilities, error) {
+	out := new(Capabilities)
+	err := c.cc.Invoke(ctx, "/remote.Downstream/Capabilities", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -892,6 +1572,10 @@ type DownstreamServer interface {
 	Download(Downstream_DownloadServer) error
 	Changes(*Empty, Downstream_ChangesServer) error
 	ChangesCount(context.Context, *Empty) (*ChangeAmount, error)
+	ChangesNotify(*Empty, Downstream_ChangesNotifyServer) error
+	Checksums(Downstream_ChecksumsServer) error
+	Delta(Downstream_DeltaServer) error
+	Capabilities(context.Context, *Empty) (*Capabilities, error)
 	Ping(context.Context, *Empty) (*Empty, error)
 }
 
@@ -964,6 +1648,97 @@ func _Downstream_ChangesCount_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Downstream_ChangesNotify_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DownstreamServer).ChangesNotify(m, &downstreamChangesNotifyServer{stream})
+}
+
+type Downstream_ChangesNotifyServer interface {
+	Send(*ChangeAmount) error
+	grpc.ServerStream
+}
+
+type downstreamChangesNotifyServer struct {
+	grpc.ServerStream
+}
+
+func (x *downstreamChangesNotifyServer) Send(m *ChangeAmount) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Downstream_Checksums_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DownstreamServer).Checksums(&downstreamChecksumsServer{stream})
+}
+
+type Downstream_ChecksumsServer interface {
+	Send(*ChecksumChunk) error
+	Recv() (*Paths, error)
+	grpc.ServerStream
+}
+
+type downstreamChecksumsServer struct {
+	grpc.ServerStream
+}
+
+func (x *downstreamChecksumsServer) Send(m *ChecksumChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *downstreamChecksumsServer) Recv() (*Paths, error) {
+	m := new(Paths)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Downstream_Delta_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DownstreamServer).Delta(&downstreamDeltaServer{stream})
+}
+
+type Downstream_DeltaServer interface {
+	Send(*Delta) error
+	Recv() (*Signature, error)
+	grpc.ServerStream
+}
+
+type downstreamDeltaServer struct {
+	grpc.ServerStream
+}
+
+func (x *downstreamDeltaServer) Send(m *Delta) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *downstreamDeltaServer) Recv() (*Signature, error) {
+	m := new(Signature)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Downstream_Capabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownstreamServer).Capabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Downstream/Capabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownstreamServer).Capabilities(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Downstream_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -990,6 +1765,10 @@ var _Downstream_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ChangesCount",
 			Handler:    _Downstream_ChangesCount_Handler,
 		},
+		{
+			MethodName: "Capabilities",
+			Handler:    _Downstream_Capabilities_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _Downstream_Ping_Handler,
@@ -1007,6 +1786,23 @@ var _Downstream_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Downstream_Changes_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ChangesNotify",
+			Handler:       _Downstream_ChangesNotify_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Checksums",
+			Handler:       _Downstream_Checksums_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Delta",
+			Handler:       _Downstream_Delta_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "remote.proto",
 }
@@ -1018,6 +1814,10 @@ type UpstreamClient interface {
 	Upload(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadClient, error)
 	RestartContainer(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Remove(ctx context.Context, opts ...grpc.CallOption) (Upstream_RemoveClient, error)
+	Signature(ctx context.Context, in *SignatureRequest, opts ...grpc.CallOption) (Upstream_SignatureClient, error)
+	ApplyDelta(ctx context.Context, opts ...grpc.CallOption) (Upstream_ApplyDeltaClient, error)
+	Execute(ctx context.Context, opts ...grpc.CallOption) (Upstream_ExecuteClient, error)
+	Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Capabilities, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 }
 
@@ -1106,6 +1906,115 @@ func (x *upstreamRemoveClient) CloseAndRecv() (*Empty, error) {
 	return m, nil
 }
 
+func (c *upstreamClient) Signature(ctx context.Context, in *SignatureRequest, opts ...grpc.CallOption) (Upstream_SignatureClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[2], "/remote.Upstream/Signature", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamSignatureClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Upstream_SignatureClient interface {
+	Recv() (*Signature, error)
+	grpc.ClientStream
+}
+
+type upstreamSignatureClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamSignatureClient) Recv() (*Signature, error) {
+	m := new(Signature)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *upstreamClient) ApplyDelta(ctx context.Context, opts ...grpc.CallOption) (Upstream_ApplyDeltaClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[3], "/remote.Upstream/ApplyDelta", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamApplyDeltaClient{stream}
+	return x, nil
+}
+
+type Upstream_ApplyDeltaClient interface {
+	Send(*Delta) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type upstreamApplyDeltaClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamApplyDeltaClient) Send(m *Delta) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *upstreamApplyDeltaClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *upstreamClient) Execute(ctx context.Context, opts ...grpc.CallOption) (Upstream_ExecuteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[4], "/remote.Upstream/Execute", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamExecuteClient{stream}
+	return x, nil
+}
+
+type Upstream_ExecuteClient interface {
+	Send(*Paths) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type upstreamExecuteClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamExecuteClient) Send(m *Paths) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *upstreamExecuteClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *upstreamClient) Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Capabilities, error) {
+	out := new(Capabilities)
+	err := c.cc.Invoke(ctx, "/remote.Upstream/Capabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *upstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/remote.Upstream/Ping", in, out, opts...)
@@ -1120,6 +2029,10 @@ type UpstreamServer interface {
 	Upload(Upstream_UploadServer) error
 	RestartContainer(context.Context, *Empty) (*Empty, error)
 	Remove(Upstream_RemoveServer) error
+	Signature(*SignatureRequest, Upstream_SignatureServer) error
+	ApplyDelta(Upstream_ApplyDeltaServer) error
+	Execute(Upstream_ExecuteServer) error
+	Capabilities(context.Context, *Empty) (*Capabilities, error)
 	Ping(context.Context, *Empty) (*Empty, error)
 }
 
@@ -1197,6 +2110,97 @@ func (x *upstreamRemoveServer) Recv() (*Paths, error) {
 	return m, nil
 }
 
+func _Upstream_Signature_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SignatureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UpstreamServer).Signature(m, &upstreamSignatureServer{stream})
+}
+
+type Upstream_SignatureServer interface {
+	Send(*Signature) error
+	grpc.ServerStream
+}
+
+type upstreamSignatureServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamSignatureServer) Send(m *Signature) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Upstream_ApplyDelta_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UpstreamServer).ApplyDelta(&upstreamApplyDeltaServer{stream})
+}
+
+type Upstream_ApplyDeltaServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*Delta, error)
+	grpc.ServerStream
+}
+
+type upstreamApplyDeltaServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamApplyDeltaServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *upstreamApplyDeltaServer) Recv() (*Delta, error) {
+	m := new(Delta)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Upstream_Execute_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UpstreamServer).Execute(&upstreamExecuteServer{stream})
+}
+
+type Upstream_ExecuteServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*Paths, error)
+	grpc.ServerStream
+}
+
+type upstreamExecuteServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamExecuteServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *upstreamExecuteServer) Recv() (*Paths, error) {
+	m := new(Paths)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Upstream_Capabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpstreamServer).Capabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Upstream/Capabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpstreamServer).Capabilities(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Upstream_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -1223,6 +2227,10 @@ var _Upstream_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RestartContainer",
 			Handler:    _Upstream_RestartContainer_Handler,
 		},
+		{
+			MethodName: "Capabilities",
+			Handler:    _Upstream_Capabilities_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _Upstream_Ping_Handler,
@@ -1239,6 +2247,21 @@ var _Upstream_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Upstream_Remove_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Signature",
+			Handler:       _Upstream_Signature_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ApplyDelta",
+			Handler:       _Upstream_ApplyDelta_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Execute",
+			Handler:       _Upstream_Execute_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "remote.proto",
 }
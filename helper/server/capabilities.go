@@ -0,0 +1,17 @@
+package server
+
+import (
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+)
+
+// capabilities returns the version and features of this helper, so that the client
+// can decide how to talk to it
+func capabilities(watchMode string) *remote.Capabilities {
+	return &remote.Capabilities{
+		Version:      util.GetVersion(),
+		Compressions: util.SupportedCompressions,
+		WatchMode:    watchMode,
+		Features:     util.SupportedFeatures,
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"context"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
 	"github.com/syncthing/notify"
@@ -24,6 +23,12 @@ import (
 
 const rescanPeriod = time.Minute * 15
 
+// pollingInterval is the interval in which the remote path is walked if polling is used
+const pollingInterval = time.Millisecond * 1700
+
+// settleInterval is the time to wait for more changes before the client is notified
+const settleInterval = time.Millisecond * 300
+
 // DownstreamOptions holds the options for the downstream server
 type DownstreamOptions struct {
 	RemotePath   string
@@ -32,6 +37,12 @@ type DownstreamOptions struct {
 	Throttle     int64
 
 	Polling bool
+
+	// Symlinks defines if symlinks are followed, preserved or skipped
+	Symlinks string
+
+	// SizeLimits marks files that are too large to be synced, these are never sent to the client
+	SizeLimits *util.SizeLimits
 }
 
 // StartDownstreamServer starts a new downstream server with the given reader and writer
@@ -53,26 +64,36 @@ func StartDownstreamServer(reader io.Reader, writer io.Writer, options *Downstre
 			ignoreMatcher: ignoreMatcher,
 			events:        make(chan notify.EventInfo, 1000),
 			changes:       map[string]bool{},
+			notify:        make(chan struct{}, 1),
+			polling:       options.Polling,
 		}
 
 		remote.RegisterDownstreamServer(s, downStream)
 		reflection.Register(s)
 
+		// There is only a single connection, so we stop serving as soon as it is closed
+		go func() {
+			<-pipe.Closed()
+			s.Stop()
+		}()
+
 		// start watcher if this we should use it
 		watchStop := make(chan struct{})
 		if options.Polling == false {
-			go func() {
-				// set up a watchpoint listening for events within a directory tree rooted at specified directory
-				err := notify.Watch(options.RemotePath+"/...", downStream.events, notify.All)
-				if err != nil {
-					log.Fatalf("error watching path %s: %v", options.RemotePath, err)
-					return
-				}
-				defer notify.Stop(downStream.events)
+			// set up a watchpoint listening for events within a directory tree rooted at specified directory,
+			// this fails if the inotify limits are reached or the filesystem does not support it
+			err := notify.Watch(options.RemotePath+"/...", downStream.events, notify.All)
+			if err != nil {
+				log.Printf("error watching path %s, falling back to polling: %v", options.RemotePath, err)
+				downStream.setPolling(true)
+			} else {
+				go func() {
+					defer notify.Stop(downStream.events)
 
-				// start the watch loop
-				downStream.watch(watchStop)
-			}()
+					// start the watch loop
+					downStream.watch(watchStop)
+				}()
+			}
 		}
 
 		done <- s.Serve(lis)
@@ -104,14 +125,50 @@ type Downstream struct {
 
 	// lastRescan is used to rescan the complete path from time to time
 	lastRescan *time.Time
+
+	// notify is signaled by the watcher whenever a new change was recorded
+	notify chan struct{}
+
+	// polling is true if changes are detected by walking the remote path instead
+	// of watching it. It is protected by changesMutex
+	polling bool
+
+	// collected is increased every time the client collects the changes. It is protected by changesMutex
+	collected int64
+}
+
+func (d *Downstream) collectedChanges() int64 {
+	d.changesMutex.Lock()
+	defer d.changesMutex.Unlock()
+
+	return d.collected
+}
+
+func (d *Downstream) isPolling() bool {
+	d.changesMutex.Lock()
+	defer d.changesMutex.Unlock()
+
+	return d.polling
+}
+
+func (d *Downstream) setPolling(polling bool) {
+	d.changesMutex.Lock()
+	defer d.changesMutex.Unlock()
+
+	d.polling = polling
 }
 
 // Download sends the file at the temp download location to the client
 func (d *Downstream) Download(stream remote.Downstream_DownloadServer) error {
 	filesToCompress := make([]string, 0, 128)
+	compression := util.CompressionGzip
 	for {
 		paths, err := stream.Recv()
 		if paths != nil {
+			if paths.Compression != "" {
+				compression = paths.Compression
+			}
+
 			for _, path := range paths.Paths {
 				filesToCompress = append(filesToCompress, path)
 			}
@@ -131,7 +188,7 @@ func (d *Downstream) Download(stream remote.Downstream_DownloadServer) error {
 	// Compress archive and send at the same time
 	errorChan := make(chan error)
 	go func() {
-		errorChan <- d.compress(writer, filesToCompress)
+		errorChan <- d.compress(writer, filesToCompress, compression)
 	}()
 
 	// Send compressed archive to client
@@ -157,12 +214,74 @@ func (d *Downstream) Download(stream remote.Downstream_DownloadServer) error {
 	return <-errorChan
 }
 
+// Checksums calculates the content checksums of the given files and sends them to the client.
+// Paths that do not exist anymore or are directories are skipped.
+func (d *Downstream) Checksums(stream remote.Downstream_ChecksumsServer) error {
+	paths := make([]string, 0, 128)
+	for {
+		recvPaths, err := stream.Recv()
+		if recvPaths != nil {
+			paths = append(paths, recvPaths.Paths...)
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	checksums := make([]*remote.Checksum, 0, 64)
+	for _, path := range paths {
+		absolutePath, err := d.remoteFilePath(path)
+		if err != nil {
+			return err
+		}
+
+		stat, err := os.Stat(absolutePath)
+		if err != nil || stat.Mode().IsRegular() == false {
+			continue
+		}
+
+		checksum, err := util.FileChecksum(absolutePath)
+		if err != nil {
+			continue
+		}
+
+		checksums = append(checksums, &remote.Checksum{
+			Path:   path,
+			Sha256: checksum,
+		})
+		if len(checksums) >= 64 {
+			err = stream.Send(&remote.ChecksumChunk{Checksums: checksums})
+			if err != nil {
+				return errors.Wrap(err, "send checksums")
+			}
+
+			checksums = make([]*remote.Checksum, 0, 64)
+		}
+	}
+
+	if len(checksums) > 0 {
+		err := stream.Send(&remote.ChecksumChunk{Checksums: checksums})
+		if err != nil {
+			return errors.Wrap(err, "send checksums")
+		}
+	}
+
+	return nil
+}
+
 // Compress compresses the given files and folders into a tar archive
-func (d *Downstream) compress(writer io.WriteCloser, files []string) error {
+func (d *Downstream) compress(writer io.WriteCloser, files []string, compression string) error {
 	defer writer.Close()
 
 	// Use compression
-	gw := gzip.NewWriter(writer)
+	gw, err := util.NewCompressionWriter(writer, compression)
+	if err != nil {
+		return err
+	}
 	defer gw.Close()
 
 	tarWriter := tar.NewWriter(gw)
@@ -170,8 +289,21 @@ func (d *Downstream) compress(writer io.WriteCloser, files []string) error {
 
 	writtenFiles := make(map[string]bool)
 	for _, path := range files {
+		absolutePath, err := d.remoteFilePath(path)
+		if err != nil {
+			return err
+		}
+
 		if _, ok := writtenFiles[path]; ok == false {
-			err := recursiveTar(d.options.RemotePath, path, writtenFiles, tarWriter, true)
+			// Files that are too large are never sent
+			if d.options.SizeLimits != nil {
+				stat, err := os.Stat(absolutePath)
+				if err == nil && stat.IsDir() == false && d.options.SizeLimits.Exceeds(path, stat.Size()) {
+					continue
+				}
+			}
+
+			err := recursiveTar(d.options.RemotePath, path, d.options.Symlinks, writtenFiles, tarWriter, true)
 			if err != nil {
 				return errors.Wrapf(err, "compress %s", path)
 			}
@@ -181,27 +313,144 @@ func (d *Downstream) compress(writer io.WriteCloser, files []string) error {
 	return nil
 }
 
+// Delta sends the changed blocks of a file compared to the block signatures of the file the client already has
+func (d *Downstream) Delta(stream remote.Downstream_DeltaServer) error {
+	var (
+		relativePath string
+		blockSize    int64
+		blocks       = make([]*remote.BlockSignature, 0, 1024)
+	)
+	for {
+		signature, err := stream.Recv()
+		if signature != nil {
+			if signature.Path != "" {
+				relativePath = signature.Path
+				blockSize = signature.BlockSize
+			}
+
+			blocks = append(blocks, signature.Blocks...)
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if relativePath == "" || blockSize <= 0 {
+		return errors.New("no path or block size specified")
+	}
+
+	absolutePath, err := d.remoteFilePath(relativePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = util.SendDelta(absolutePath, relativePath, blockSize, blocks, stream.Send)
+	if err != nil {
+		return errors.Wrapf(err, "send delta of %s", relativePath)
+	}
+
+	return nil
+}
+
+// remoteFilePath returns the absolute path of a file relative to the remote path. Paths that point outside
+// of the remote path are rejected
+func (d *Downstream) remoteFilePath(relativePath string) (string, error) {
+	return containedPath(d.options.RemotePath, relativePath)
+}
+
 // Ping returns empty
 func (d *Downstream) Ping(context.Context, *remote.Empty) (*remote.Empty, error) {
 	return &remote.Empty{}, nil
 }
 
+// Capabilities returns the version and features of this helper and how remote changes are detected
+func (d *Downstream) Capabilities(context.Context, *remote.Empty) (*remote.Capabilities, error) {
+	if d.isPolling() {
+		return capabilities(util.WatchModePolling), nil
+	}
+
+	return capabilities(util.WatchModeInotify), nil
+}
+
 // ChangesCount returns the amount of changes on the remote side
 func (d *Downstream) ChangesCount(context.Context, *remote.Empty) (*remote.ChangeAmount, error) {
-	newState := make(map[string]*remote.Change)
-	throttle := time.Duration(d.options.Throttle) * time.Millisecond
+	changeAmount, err := d.changeAmount()
+	if err != nil {
+		return nil, err
+	}
 
-	// Walk through the dir
-	if d.options.Polling {
-		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, throttle)
+	return &remote.ChangeAmount{
+		Amount: changeAmount,
+	}, nil
+}
+
+// ChangesNotify notifies the client as soon as there are changes on the remote side that have settled.
+// If the remote path is watched the client is notified as changes happen, otherwise the remote path is
+// walked periodically. If the watcher fails, the stream continues by walking the remote path. The client
+// is notified only once until it collects the changes.
+func (d *Downstream) ChangesNotify(empty *remote.Empty, stream remote.Downstream_ChangesNotifyServer) error {
+	lastAmount := int64(0)
+	notified := int64(-1)
+	for {
+		// a failed walk is retried with the next poll instead of ending the stream
+		changeAmount, err := d.changeAmount()
+		if err != nil {
+			log.Printf("error counting changes in %s: %v", d.options.RemotePath, err)
+			changeAmount = 0
+		}
+
+		// We only notify the client if the amount of changes did not change since the last check
+		if changeAmount > 0 && changeAmount == lastAmount {
+			collected := d.collectedChanges()
+			if collected != notified {
+				err = stream.Send(&remote.ChangeAmount{Amount: changeAmount})
+				if err != nil {
+					return errors.Wrap(err, "send change amount")
+				}
+
+				notified = collected
+			}
+
+			lastAmount = 0
+		} else {
+			lastAmount = changeAmount
+		}
+
+		// Wait for new changes, if we are watching and there are no pending changes
+		// we only wake up if the watcher records a new change
+		var wait <-chan time.Time
+		if d.isPolling() {
+			wait = time.After(pollingInterval)
+		} else if lastAmount > 0 {
+			wait = time.After(settleInterval)
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-d.notify:
+		case <-wait:
+		}
 	}
+}
 
+func (d *Downstream) changeAmount() (int64, error) {
 	changeAmount := int64(0)
-	if d.options.Polling {
+	if d.isPolling() {
+		newState := make(map[string]*remote.Change)
+		throttle := time.Duration(d.options.Throttle) * time.Millisecond
+
+		// Walk through the dir
+		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, d.options.Symlinks, d.options.SizeLimits, newState, throttle)
+
 		var err error
 		changeAmount, err = streamChanges(d.options.RemotePath, d.watchedFiles, newState, nil, throttle)
 		if err != nil {
-			return nil, errors.Wrap(err, "count changes")
+			return 0, errors.Wrap(err, "count changes")
 		}
 	} else {
 		d.changesMutex.Lock()
@@ -215,9 +464,7 @@ func (d *Downstream) ChangesCount(context.Context, *remote.Empty) (*remote.Chang
 		d.changesMutex.Unlock()
 	}
 
-	return &remote.ChangeAmount{
-		Amount: changeAmount,
-	}, nil
+	return changeAmount, nil
 }
 
 func (d *Downstream) getWatchState() map[string]*remote.Change {
@@ -236,7 +483,7 @@ func (d *Downstream) getWatchState() map[string]*remote.Change {
 		d.lastRescan = &now
 
 		newState := make(map[string]*remote.Change)
-		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, 0)
+		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, d.options.Symlinks, d.options.SizeLimits, newState, 0)
 		return newState
 	} else if changeAmount == 0 {
 		return nil
@@ -262,14 +509,18 @@ func (d *Downstream) getWatchState() map[string]*remote.Change {
 
 // Changes retrieves all changes from the watch path
 func (d *Downstream) Changes(empty *remote.Empty, stream remote.Downstream_ChangesServer) error {
+	d.changesMutex.Lock()
+	d.collected++
+	d.changesMutex.Unlock()
+
 	newState := make(map[string]*remote.Change)
 	throttle := time.Duration(d.options.Throttle) * time.Millisecond
 
 	// Walk through the dir
-	if d.options.Polling == false {
+	if d.isPolling() == false {
 		newState = d.getWatchState()
 	} else {
-		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, throttle)
+		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, d.options.Symlinks, d.options.SizeLimits, newState, throttle)
 	}
 
 	if newState != nil {
@@ -290,6 +541,7 @@ func (d *Downstream) watch(stopChan chan struct{}) {
 			return
 		case event, ok := <-d.events:
 			if ok == false {
+				d.fallBackToPolling("watcher stopped")
 				return
 			}
 
@@ -314,10 +566,28 @@ func (d *Downstream) watch(stopChan chan struct{}) {
 				}
 			}
 			d.changesMutex.Unlock()
+
+			// notify a waiting client without blocking
+			select {
+			case d.notify <- struct{}{}:
+			default:
+			}
 		}
 	}
 }
 
+// fallBackToPolling switches to walking the remote path if the watcher fails while the server is running.
+// A waiting ChangesNotify stream is woken up and continues by polling
+func (d *Downstream) fallBackToPolling(reason string) {
+	log.Printf("error watching path %s, falling back to polling: %s", d.options.RemotePath, reason)
+	d.setPolling(true)
+
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
 func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath string) {
 	if strings.HasSuffix(fullPath, "/") {
 		fullPath = fullPath[:len(fullPath)-1]
@@ -334,6 +604,18 @@ func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath st
 		}
 	}
 
+	// check if the path is a symlink that should not be followed
+	if d.options.Symlinks != "" && d.options.Symlinks != util.SymlinksFollow {
+		lstat, err := os.Lstat(fullPath)
+		if err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+			if change := symlinkChange(d.options.RemotePath, fullPath, lstat, d.ignoreMatcher, d.options.Symlinks); change != nil {
+				newState[fullPath] = change
+			}
+
+			return
+		}
+	}
+
 	// check if the path still exists
 	stat, err := os.Stat(fullPath)
 	if err != nil {
@@ -350,7 +632,7 @@ func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath st
 			}
 		}
 
-		walkDir(d.options.RemotePath, fullPath, d.ignoreMatcher, newState, time.Duration(d.options.Throttle)*time.Millisecond)
+		walkDir(d.options.RemotePath, fullPath, d.ignoreMatcher, d.options.Symlinks, d.options.SizeLimits, newState, time.Duration(d.options.Throttle)*time.Millisecond)
 	} else {
 		if d.ignoreMatcher == nil || d.ignoreMatcher.HasNegatePatterns() == false || util.MatchesPath(d.ignoreMatcher, relativePath, false) == false {
 			newState[fullPath] = &remote.Change{
@@ -359,6 +641,7 @@ func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath st
 				MtimeUnix:     stat.ModTime().Unix(),
 				MtimeUnixNano: stat.ModTime().UnixNano(),
 				IsDir:         false,
+				SizeExceeded:  d.options.SizeLimits.Exceeds(relativePath, stat.Size()),
 			}
 		}
 	}
@@ -382,6 +665,8 @@ func copyState(state map[string]*remote.Change) map[string]*remote.Change {
 			MtimeUnixNano: v.MtimeUnixNano,
 			Size:          v.Size,
 			IsDir:         v.IsDir,
+			LinkTarget:    v.LinkTarget,
+			SizeExceeded:  v.SizeExceeded,
 		}
 	}
 
@@ -404,7 +689,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 		}
 
 		if oldFile, ok := oldState[newFile.Path]; ok {
-			if oldFile.IsDir != newFile.IsDir || oldFile.Size != newFile.Size || oldFile.MtimeUnix != newFile.MtimeUnix || oldFile.MtimeUnixNano != newFile.MtimeUnixNano {
+			if oldFile.IsDir != newFile.IsDir || oldFile.Size != newFile.Size || oldFile.MtimeUnix != newFile.MtimeUnix || oldFile.MtimeUnixNano != newFile.MtimeUnixNano || oldFile.LinkTarget != newFile.LinkTarget {
 				if stream != nil {
 					changes = append(changes, &remote.Change{
 						ChangeType:    remote.ChangeType_CHANGE,
@@ -427,6 +712,8 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 					MtimeUnixNano: newFile.MtimeUnixNano,
 					Size:          newFile.Size,
 					IsDir:         newFile.IsDir,
+					LinkTarget:    newFile.LinkTarget,
+					SizeExceeded:  newFile.SizeExceeded,
 				})
 			}
 
@@ -459,6 +746,8 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 					MtimeUnixNano: oldFile.MtimeUnixNano,
 					Size:          oldFile.Size,
 					IsDir:         oldFile.IsDir,
+					LinkTarget:    oldFile.LinkTarget,
+					SizeExceeded:  oldFile.SizeExceeded,
 				})
 			}
 
@@ -486,7 +775,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 	return changeAmount, nil
 }
 
-func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnoreParser, state map[string]*remote.Change, throttle time.Duration) {
+func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnoreParser, symlinks string, sizeLimits *util.SizeLimits, state map[string]*remote.Change, throttle time.Duration) {
 	files, err := ioutil.ReadDir(path)
 	if err != nil {
 		// We ignore errors here
@@ -496,6 +785,15 @@ func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnorePars
 	for _, f := range files {
 		absolutePath := filepath.Join(path, f.Name())
 
+		// Symlinks are only resolved if they should be followed
+		if f.Mode()&os.ModeSymlink != 0 && symlinks != "" && symlinks != util.SymlinksFollow {
+			if change := symlinkChange(basePath, absolutePath, f, ignoreMatcher, symlinks); change != nil {
+				state[absolutePath] = change
+			}
+
+			continue
+		}
+
 		// Stat is necessary here, because readdir does not follow symlinks and
 		// IsDir() returns false for symlinked folders
 		stat, err := os.Stat(absolutePath)
@@ -524,7 +822,7 @@ func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnorePars
 				}
 			}
 
-			walkDir(basePath, absolutePath, ignoreMatcher, state, throttle)
+			walkDir(basePath, absolutePath, ignoreMatcher, symlinks, sizeLimits, state, throttle)
 		} else {
 			// Check if not ignored
 			if ignoreMatcher == nil || ignoreMatcher.HasNegatePatterns() == false || util.MatchesPath(ignoreMatcher, absolutePath[len(basePath):], false) == false {
@@ -534,8 +832,39 @@ func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnorePars
 					MtimeUnix:     stat.ModTime().Unix(),
 					MtimeUnixNano: stat.ModTime().UnixNano(),
 					IsDir:         false,
+					SizeExceeded:  sizeLimits.Exceeds(absolutePath[len(basePath):], stat.Size()),
 				}
 			}
 		}
 	}
 }
+
+// symlinkChange returns the change for a symlink that should be preserved. Nil is returned if the symlink is skipped,
+// excluded or points outside of the base path
+func symlinkChange(basePath, absolutePath string, lstat os.FileInfo, ignoreMatcher ignoreparser.IgnoreParser, symlinks string) *remote.Change {
+	if symlinks != util.SymlinksPreserve {
+		return nil
+	}
+
+	relativePath := absolutePath[len(basePath):]
+	target, err := os.Readlink(absolutePath)
+	if err != nil || util.SymlinkWithinRoot(basePath, relativePath, target) == false {
+		return nil
+	}
+
+	// Symlinks to folders are excluded like folders
+	if ignoreMatcher != nil {
+		stat, err := os.Stat(absolutePath)
+		if util.MatchesPath(ignoreMatcher, relativePath, err == nil && stat.IsDir()) {
+			return nil
+		}
+	}
+
+	return &remote.Change{
+		Path:          absolutePath,
+		Size:          lstat.Size(),
+		MtimeUnix:     lstat.ModTime().Unix(),
+		MtimeUnixNano: lstat.ModTime().UnixNano(),
+		LinkTarget:    target,
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"context"
 	"github.com/loft-sh/devspace/helper/remote"
 	"github.com/loft-sh/devspace/helper/util"
+	"github.com/syncthing/notify"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDownstreamServer(t *testing.T) {
@@ -133,7 +135,7 @@ func TestDownstreamServer(t *testing.T) {
 	w.Close()
 	log.Println("Downloaded complete file")
 
-	err = untarAll(r, &UpstreamOptions{UploadPath: toDir})
+	_, err = untarAll(r, &UpstreamOptions{UploadPath: toDir})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -207,3 +209,293 @@ func getAllChanges(changesClient remote.Downstream_ChangesClient) ([]*remote.Cha
 
 	return changes, nil
 }
+
+func TestDownstreamChecksums(t *testing.T) {
+	fromDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fromDir)
+
+	err = createFiles(fromDir, fileStructure)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		_ = StartDownstreamServer(serverReader, clientWriter, &DownstreamOptions{
+			RemotePath:  fromDir,
+			ExitOnClose: false,
+			Polling:     true,
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := remote.NewDownstreamClient(conn)
+	checksumsClient, err := client.Checksums(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = checksumsClient.Send(&remote.Paths{
+		Paths: []string{"/test.txt", "/dir1/dir1-child/test", "/emptydir", "/does-not-exist"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = checksumsClient.CloseSend()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksums := map[string]string{}
+	for {
+		chunk, err := checksumsClient.Recv()
+		if chunk != nil {
+			for _, checksum := range chunk.Checksums {
+				checksums[checksum.Path] = checksum.Sha256
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(checksums) != 2 {
+		t.Fatalf("Expected 2 checksums, got %d", len(checksums))
+	}
+
+	for _, path := range []string{"/test.txt", "/dir1/dir1-child/test"} {
+		expected, err := util.FileChecksum(filepath.Join(fromDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if checksums[path] != expected {
+			t.Fatalf("Unexpected checksum for %s: expected %s, got %s", path, expected, checksums[path])
+		}
+	}
+}
+
+func TestDownstreamOutsideRemotePath(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	remotePath := filepath.Join(baseDir, "remote")
+	err = os.Mkdir(remotePath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(baseDir, "outside"), []byte("outside"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		_ = StartDownstreamServer(serverReader, clientWriter, &DownstreamOptions{
+			RemotePath:  remotePath,
+			ExitOnClose: false,
+			Polling:     true,
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := remote.NewDownstreamClient(conn)
+	checksumsClient, err := client.Checksums(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checksumsClient.Send(&remote.Paths{Paths: []string{"../outside"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checksumsClient.CloseSend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = checksumsClient.Recv()
+	if err == nil || err == io.EOF {
+		t.Fatal("Expected an error for a checksum of a file outside of the remote path")
+	}
+
+	deltaClient, err := client.Delta(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = deltaClient.Send(&remote.Signature{Path: "../outside", BlockSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = deltaClient.CloseSend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = deltaClient.Recv()
+	if err == nil || err == io.EOF {
+		t.Fatal("Expected an error for a delta of a file outside of the remote path")
+	}
+
+	downloadClient, err := client.Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = downloadClient.Send(&remote.Paths{Paths: []string{"../outside"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = downloadClient.CloseSend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, err = downloadClient.Recv()
+		if err == io.EOF {
+			t.Fatal("Expected an error for a download of a file outside of the remote path")
+		} else if err != nil {
+			break
+		}
+	}
+}
+
+func TestDownstreamChangesNotify(t *testing.T) {
+	fromDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fromDir)
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		_ = StartDownstreamServer(serverReader, clientWriter, &DownstreamOptions{
+			RemotePath:  fromDir,
+			ExitOnClose: false,
+			Polling:     false,
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	client := remote.NewDownstreamClient(conn)
+	notifyClient, err := client.ChangesNotify(ctx, &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// make sure the watcher is set up before we create the file
+	_, err = client.Ping(ctx, &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(fromDir, "test.txt"), []byte("test"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifications := make(chan *remote.ChangeAmount)
+	go func() {
+		for {
+			amount, err := notifyClient.Recv()
+			if err != nil {
+				close(notifications)
+				return
+			}
+
+			notifications <- amount
+		}
+	}()
+
+	amount := <-notifications
+	if amount == nil {
+		t.Fatal("Change notification stream closed")
+	} else if amount.Amount != 1 {
+		t.Fatalf("Unexpected change amount, expected 1, got %d", amount.Amount)
+	}
+
+	// the client is not notified again until it collects the changes
+	select {
+	case amount := <-notifications:
+		t.Fatalf("Unexpected second notification %#v", amount)
+	case <-time.After(settleInterval * 4):
+	}
+
+	changesClient, err := client.Changes(ctx, &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = getAllChanges(changesClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(fromDir, "test2.txt"), []byte("test"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amount = <-notifications
+	if amount == nil {
+		t.Fatal("Change notification stream closed")
+	}
+}
+
+func TestDownstreamWatchFallback(t *testing.T) {
+	downStream := &Downstream{
+		options: &DownstreamOptions{
+			RemotePath: "/tmp",
+		},
+		events:  make(chan notify.EventInfo, 1),
+		changes: map[string]bool{},
+		notify:  make(chan struct{}, 1),
+	}
+
+	watchStop := make(chan struct{})
+	defer close(watchStop)
+	go downStream.watch(watchStop)
+
+	// a watcher that stops at runtime switches the server to polling and wakes up the notify stream
+	close(downStream.events)
+	select {
+	case <-downStream.notify:
+	case <-time.After(time.Second * 10):
+		t.Fatal("Notify stream was not woken up")
+	}
+	if downStream.isPolling() == false {
+		t.Fatal("Expected the server to fall back to polling")
+	}
+
+	capabilities, err := downStream.Capabilities(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	} else if capabilities.WatchMode != util.WatchModePolling {
+		t.Fatalf("Unexpected watch mode %s", capabilities.WatchMode)
+	}
+}
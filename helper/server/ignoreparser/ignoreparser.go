@@ -3,6 +3,7 @@ package ignoreparser
 import (
 	"github.com/pkg/errors"
 	gitignore "github.com/sabhiram/go-gitignore"
+	"path/filepath"
 	"strings"
 )
 
@@ -53,3 +54,46 @@ func CompilePaths(excludePaths []string) (IgnoreParser, error) {
 
 	return nil, nil
 }
+
+// MatchingRule returns the index of the exclude path that decides whether the given relative path is excluded.
+// Like gitignore, the last matching rule wins, so the returned rule is either the last pattern that
+// excluded the path or the last negating pattern that included it again. If no rule matches the path,
+// -1 is returned
+func MatchingRule(excludePaths []string, relativePath string, isDir bool) (int, bool, error) {
+	relativePath = strings.TrimRight(filepath.ToSlash(relativePath), "/")
+	if isDir {
+		relativePath = relativePath + "/"
+	}
+
+	rule := -1
+	excluded := false
+	for idx, line := range excludePaths {
+		pattern := strings.Trim(line, " ")
+		if pattern == "" || pattern[0] == '#' {
+			continue
+		}
+
+		negate := pattern[0] == '!'
+		if negate {
+			pattern = pattern[1:]
+		}
+
+		gitIgnoreParser, err := gitignore.CompileIgnoreLines(pattern)
+		if err != nil {
+			return -1, false, errors.Wrapf(err, "compile ignore line %s", line)
+		}
+		if gitIgnoreParser.MatchesPath(relativePath) == false {
+			continue
+		}
+
+		if negate == false {
+			rule = idx
+			excluded = true
+		} else if excluded {
+			rule = idx
+			excluded = false
+		}
+	}
+
+	return rule, excluded, nil
+}
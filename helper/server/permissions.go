@@ -0,0 +1,108 @@
+package server
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Owner is the user and group id uploaded files and created folders are changed to. An id of -1 is not changed
+type Owner struct {
+	UID int
+	GID int
+}
+
+// ParseOwner resolves the given user and group, which are either names or numeric ids, to an owner.
+// If both are empty, nil is returned
+func ParseOwner(userName, groupName string) (*Owner, error) {
+	if userName == "" && groupName == "" {
+		return nil, nil
+	}
+
+	owner := &Owner{UID: -1, GID: -1}
+	if userName != "" {
+		uid, err := strconv.Atoi(userName)
+		if err != nil {
+			u, err := user.Lookup(userName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "lookup user %s", userName)
+			}
+
+			uid, err = strconv.Atoi(u.Uid)
+			if err != nil {
+				return nil, errors.Errorf("user %s has no numeric uid", userName)
+			}
+		}
+
+		owner.UID = uid
+	}
+	if groupName != "" {
+		gid, err := strconv.Atoi(groupName)
+		if err != nil {
+			g, err := user.LookupGroup(groupName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "lookup group %s", groupName)
+			}
+
+			gid, err = strconv.Atoi(g.Gid)
+			if err != nil {
+				return nil, errors.Errorf("group %s has no numeric gid", groupName)
+			}
+		}
+
+		owner.GID = gid
+	}
+
+	return owner, nil
+}
+
+// ParseMode parses an octal file mode such as 0644. An empty string results in a mode of 0
+func ParseMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || parsed > uint64(os.ModePerm) {
+		return 0, errors.Errorf("invalid file mode %s, expected an octal mode such as 0644", mode)
+	}
+
+	return os.FileMode(parsed), nil
+}
+
+// applyFilePermissions sets the mode and owner of an uploaded file. A replaced file keeps its old mode and owner
+// and a new file gets the mode it has locally, unless the options specify a fixed mode or owner
+func applyFilePermissions(fileName string, oldStat os.FileInfo, localMode os.FileMode, options *UpstreamOptions) {
+	mode := localMode
+	if oldStat != nil {
+		mode = oldStat.Mode()
+
+		// Set old owner & group correctly
+		_ = Chown(fileName, oldStat)
+	}
+	if options.FileMode != 0 {
+		mode = mode&^os.ModePerm | options.FileMode
+	}
+	if options.PreserveExecutable {
+		// the executable bits are only set where the file is readable
+		mode = mode&^0111 | localMode&0111&((mode&0444)>>2)
+	}
+
+	_ = os.Chmod(fileName, mode)
+	if options.Owner != nil {
+		_ = os.Chown(fileName, options.Owner.UID, options.Owner.GID)
+	}
+}
+
+// applyDirPermissions sets the mode and owner of a newly created folder if the options specify them
+func applyDirPermissions(dirName string, options *UpstreamOptions) {
+	if options.DirMode != 0 {
+		// the mode passed to mkdir is subject to the umask, so we set it explicitly
+		_ = os.Chmod(dirName, options.DirMode)
+	}
+	if options.Owner != nil {
+		_ = os.Chown(dirName, options.Owner.UID, options.Owner.GID)
+	}
+}
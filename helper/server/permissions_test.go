@@ -0,0 +1,129 @@
+// +build !windows
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	testCases := map[string]struct {
+		mode        string
+		expected    os.FileMode
+		expectError bool
+	}{
+		"empty":       {mode: "", expected: 0},
+		"file mode":   {mode: "0644", expected: 0644},
+		"no prefix":   {mode: "755", expected: 0755},
+		"not octal":   {mode: "0899", expectError: true},
+		"too large":   {mode: "17777", expectError: true},
+		"not numeric": {mode: "rwx", expectError: true},
+	}
+
+	for name, testCase := range testCases {
+		mode, err := ParseMode(testCase.mode)
+		if testCase.expectError {
+			if err == nil {
+				t.Fatalf("Test case %s: expected error", name)
+			}
+
+			continue
+		} else if err != nil {
+			t.Fatalf("Test case %s: unexpected error: %v", name, err)
+		}
+
+		if mode != testCase.expected {
+			t.Fatalf("Test case %s: expected mode %o, got %o", name, testCase.expected, mode)
+		}
+	}
+}
+
+func TestApplyFilePermissions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "permissions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testCases := map[string]struct {
+		oldMode   os.FileMode
+		localMode os.FileMode
+		options   *UpstreamOptions
+		expected  os.FileMode
+	}{
+		"new file keeps local mode": {
+			localMode: 0640,
+			options:   &UpstreamOptions{},
+			expected:  0640,
+		},
+		"replaced file keeps old mode": {
+			oldMode:   0600,
+			localMode: 0755,
+			options:   &UpstreamOptions{},
+			expected:  0600,
+		},
+		"fixed file mode": {
+			oldMode:   0600,
+			localMode: 0600,
+			options:   &UpstreamOptions{FileMode: 0644},
+			expected:  0644,
+		},
+		"preserve executable": {
+			oldMode:   0644,
+			localMode: 0755,
+			options:   &UpstreamOptions{PreserveExecutable: true},
+			expected:  0755,
+		},
+		"preserve executable only where readable": {
+			localMode: 0755,
+			options:   &UpstreamOptions{FileMode: 0640, PreserveExecutable: true},
+			expected:  0750,
+		},
+		"preserve not executable": {
+			oldMode:   0755,
+			localMode: 0644,
+			options:   &UpstreamOptions{PreserveExecutable: true},
+			expected:  0644,
+		},
+	}
+
+	for name, testCase := range testCases {
+		fileName := filepath.Join(dir, "file")
+		_ = os.Remove(fileName)
+
+		var oldStat os.FileInfo
+		if testCase.oldMode != 0 {
+			err = ioutil.WriteFile(fileName, []byte("old"), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.Chmod(fileName, testCase.oldMode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oldStat, err = os.Stat(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = ioutil.WriteFile(fileName, []byte("new"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		applyFilePermissions(fileName, oldStat, testCase.localMode, testCase.options)
+
+		stat, err := os.Stat(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat.Mode().Perm() != testCase.expected {
+			t.Fatalf("Test case %s: expected mode %o, got %o", name, testCase.expected, stat.Mode().Perm())
+		}
+	}
+}
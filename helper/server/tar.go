@@ -2,9 +2,9 @@ package server
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"io"
 	"io/ioutil"
+	"log"
 	"os"
 	"os/exec"
 	"path"
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/pkg/errors"
 )
 
@@ -21,21 +22,23 @@ type fileInformation struct {
 	Mtime time.Time
 }
 
-func untarAll(reader io.Reader, options *UpstreamOptions) error {
-	gzr, err := gzip.NewReader(reader)
+// untarAll extracts all files and folders of the archive and returns the changed paths
+func untarAll(reader io.Reader, options *UpstreamOptions) (changedPaths, error) {
+	decompressedReader, err := util.NewDecompressionReader(reader)
 	if err != nil {
-		return errors.Errorf("error decompressing: %v", err)
+		return nil, errors.Errorf("error decompressing: %v", err)
 	}
 
-	defer gzr.Close()
-	tarReader := tar.NewReader(gzr)
+	defer decompressedReader.Close()
+	tarReader := tar.NewReader(decompressedReader)
 
+	changes := changedPaths{}
 	for {
-		shouldContinue, err := untarNext(tarReader, options)
+		shouldContinue, err := untarNext(tarReader, options, &changes)
 		if err != nil {
-			return errors.Wrap(err, "decompress")
+			return nil, errors.Wrap(err, "decompress")
 		} else if shouldContinue == false {
-			return nil
+			return changes, nil
 		}
 	}
 }
@@ -59,27 +62,38 @@ func createAllFolders(name string, perm os.FileMode, options *UpstreamOptions) e
 			return errors.Errorf("error creating %s: %v", dirToCreate, err)
 		}
 
-		if options.DirCreateCmd != "" {
-			cmdArgs := make([]string, 0, len(options.DirCreateArgs))
-			for _, arg := range options.DirCreateArgs {
-				if arg == "{}" {
-					cmdArgs = append(cmdArgs, dirToCreate)
-				} else {
-					cmdArgs = append(cmdArgs, arg)
-				}
-			}
+		applyDirPermissions(dirToCreate, options)
+
+		err = executeDirCreateCommand(dirToCreate, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-			out, err := exec.Command(options.DirCreateCmd, cmdArgs...).CombinedOutput()
-			if err != nil {
-				return errors.Errorf("error executing command '%s %s': %s => %v", options.DirCreateCmd, strings.Join(cmdArgs, " "), string(out), err)
+func executeDirCreateCommand(dirName string, options *UpstreamOptions) error {
+	if options.DirCreateCmd != "" {
+		cmdArgs := make([]string, 0, len(options.DirCreateArgs))
+		for _, arg := range options.DirCreateArgs {
+			if arg == "{}" {
+				cmdArgs = append(cmdArgs, dirName)
+			} else {
+				cmdArgs = append(cmdArgs, arg)
 			}
 		}
+
+		out, err := exec.Command(options.DirCreateCmd, cmdArgs...).CombinedOutput()
+		if err != nil {
+			return errors.Errorf("error executing command '%s %s': %s => %v", options.DirCreateCmd, strings.Join(cmdArgs, " "), string(out), err)
+		}
 	}
 
 	return nil
 }
 
-func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
+func untarNext(tarReader *tar.Reader, options *UpstreamOptions, changes *changedPaths) (bool, error) {
 	header, err := tarReader.Next()
 	if err != nil {
 		if err != io.EOF {
@@ -92,6 +106,7 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 	relativePath := getRelativeFromFullPath("/"+header.Name, "")
 	outFileName := path.Join(options.UploadPath, relativePath)
 	baseName := path.Dir(outFileName)
+	changes.add(relativePath, header.FileInfo().IsDir())
 
 	// Check if newer file is there and then don't override?
 	stat, _ := os.Stat(outFileName)
@@ -108,6 +123,18 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 		return true, nil
 	}
 
+	if header.Typeflag == tar.TypeSymlink {
+		return true, createSymlink(outFileName, relativePath, header.Linkname, options)
+	}
+
+	// Replace a preserved symlink instead of writing to the file it points to
+	if options.Symlinks == util.SymlinksPreserve {
+		if lstat, err := os.Lstat(outFileName); err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+			_ = os.Remove(outFileName)
+			stat = nil
+		}
+	}
+
 	// Create / Override file
 	outFile, err := os.Create(outFileName)
 	if err != nil {
@@ -128,22 +155,60 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 		return false, errors.Wrapf(err, "out file close %s", outFileName)
 	}
 
-	// Set old permissions and owner and group
-	if stat != nil {
-		// Set old permissions correctly
-		_ = os.Chmod(outFileName, stat.Mode())
-
-		// Set old owner & group correctly
-		_ = Chown(outFileName, stat)
-	} else {
-		// Set permissions
-		_ = os.Chmod(outFileName, header.FileInfo().Mode())
-	}
+	// Set old or configured permissions and owner and group
+	applyFilePermissions(outFileName, stat, header.FileInfo().Mode(), options)
 
 	// Set mod time from tar header
 	_ = os.Chtimes(outFileName, time.Now(), header.FileInfo().ModTime())
 
 	// Execute command if defined
+	err = executeFileChangeCommand(outFileName, options)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// createSymlink creates a symlink that was preserved by the client. Symlinks that would point outside of the upload
+// path are skipped
+func createSymlink(outFileName, relativePath, target string, options *UpstreamOptions) error {
+	if util.SymlinkWithinRoot(options.UploadPath, relativePath, target) == false {
+		log.Printf("skip symlink %s, because its target %s is outside of %s", relativePath, target, options.UploadPath)
+		return nil
+	}
+
+	// Replace whatever is there currently
+	lstat, err := os.Lstat(outFileName)
+	if err == nil {
+		if lstat.Mode()&os.ModeSymlink != 0 {
+			if existingTarget, err := os.Readlink(outFileName); err == nil && existingTarget == target {
+				return nil
+			}
+		}
+
+		if lstat.IsDir() {
+			err = os.RemoveAll(outFileName)
+		} else {
+			err = os.Remove(outFileName)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "remove %s", outFileName)
+		}
+	}
+
+	err = os.Symlink(target, outFileName)
+	if err != nil {
+		return errors.Wrapf(err, "create symlink %s", outFileName)
+	}
+	if options.Owner != nil {
+		_ = os.Lchown(outFileName, options.Owner.UID, options.Owner.GID)
+	}
+
+	return nil
+}
+
+func executeFileChangeCommand(outFileName string, options *UpstreamOptions) error {
 	if options.FileChangeCmd != "" {
 		cmdArgs := make([]string, 0, len(options.FileChangeArgs))
 		for _, arg := range options.FileChangeArgs {
@@ -156,19 +221,31 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 
 		out, err := exec.Command(options.FileChangeCmd, cmdArgs...).CombinedOutput()
 		if err != nil {
-			return false, errors.Errorf("error executing command '%s %s': %s => %v", options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
+			return errors.Errorf("error executing command '%s %s': %s => %v", options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
 		}
 	}
 
-	return true, nil
+	return nil
 }
 
-func recursiveTar(basePath, relativePath string, writtenFiles map[string]bool, tw *tar.Writer, skipFolderContents bool) error {
+func recursiveTar(basePath, relativePath, symlinks string, writtenFiles map[string]bool, tw *tar.Writer, skipFolderContents bool) error {
 	absFilepath := path.Join(basePath, relativePath)
 	if _, ok := writtenFiles[relativePath]; ok {
 		return nil
 	}
 
+	// Symlinks are only resolved if they should be followed
+	if symlinks != "" && symlinks != util.SymlinksFollow {
+		lstat, err := os.Lstat(absFilepath)
+		if err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+			if symlinks == util.SymlinksPreserve {
+				return tarSymlink(basePath, relativePath, writtenFiles, lstat, tw)
+			}
+
+			return nil
+		}
+	}
+
 	// We skip files that are suddenly not there anymore
 	stat, err := os.Stat(absFilepath)
 	if err != nil {
@@ -179,13 +256,13 @@ func recursiveTar(basePath, relativePath string, writtenFiles map[string]bool, t
 	fileInformation := createFileInformationFromStat(relativePath, stat)
 	if stat.IsDir() {
 		// Recursively tar folder
-		return tarFolder(basePath, fileInformation, writtenFiles, stat, tw, skipFolderContents)
+		return tarFolder(basePath, fileInformation, symlinks, writtenFiles, stat, tw, skipFolderContents)
 	}
 
 	return tarFile(basePath, fileInformation, writtenFiles, stat, tw)
 }
 
-func tarFolder(basePath string, fileInformation *fileInformation, writtenFiles map[string]bool, stat os.FileInfo, tw *tar.Writer, skipContents bool) error {
+func tarFolder(basePath string, fileInformation *fileInformation, symlinks string, writtenFiles map[string]bool, stat os.FileInfo, tw *tar.Writer, skipContents bool) error {
 	filepath := path.Join(basePath, fileInformation.Name)
 	files, err := ioutil.ReadDir(filepath)
 	if err != nil {
@@ -207,7 +284,7 @@ func tarFolder(basePath string, fileInformation *fileInformation, writtenFiles m
 
 	if skipContents == false {
 		for _, f := range files {
-			if err := recursiveTar(basePath, path.Join(fileInformation.Name, f.Name()), writtenFiles, tw, skipContents); err != nil {
+			if err := recursiveTar(basePath, path.Join(fileInformation.Name, f.Name()), symlinks, writtenFiles, tw, skipContents); err != nil {
 				return errors.Wrap(err, "recursive tar")
 			}
 		}
@@ -263,6 +340,29 @@ func tarFile(basePath string, fileInformation *fileInformation, writtenFiles map
 	return nil
 }
 
+// tarSymlink writes a symlink as symlink instead of the file it points to. Symlinks that point outside of the
+// base path are skipped
+func tarSymlink(basePath, relativePath string, writtenFiles map[string]bool, lstat os.FileInfo, tw *tar.Writer) error {
+	target, err := os.Readlink(path.Join(basePath, relativePath))
+	if err != nil || util.SymlinkWithinRoot(basePath, relativePath, target) == false {
+		return nil
+	}
+
+	hdr, err := tar.FileInfoHeader(lstat, target)
+	if err != nil {
+		return errors.Wrapf(err, "tar file info header %s", relativePath)
+	}
+
+	hdr.Name = relativePath
+	hdr.ModTime = time.Unix(lstat.ModTime().Unix(), 0)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.Wrapf(err, "tw write header %s", relativePath)
+	}
+
+	writtenFiles[relativePath] = true
+	return nil
+}
+
 func getRelativeFromFullPath(fullpath string, prefix string) string {
 	return strings.TrimPrefix(strings.Replace(strings.Replace(fullpath[len(prefix):], "\\", "/", -1), "//", "/", -1), ".")
 }
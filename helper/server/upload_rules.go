@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+)
+
+// UploadRule runs a command or restarts the container after a batch of changes that contains
+// an uploaded or removed path matching the rule's pattern
+type UploadRule struct {
+	Path             string   `json:"path"`
+	Command          string   `json:"command,omitempty"`
+	Args             []string `json:"args,omitempty"`
+	RestartContainer bool     `json:"restartContainer,omitempty"`
+
+	// Debounce is the amount of milliseconds to wait for further matching batches before the rule is executed.
+	// If zero, the rule is executed before the batch is acknowledged
+	Debounce int64 `json:"debounce,omitempty"`
+
+	matcher ignoreparser.IgnoreParser
+
+	timerMutex sync.Mutex
+	timer      *time.Timer
+}
+
+// ParseUploadRule parses an upload rule from its json representation
+func ParseUploadRule(rule string) (*UploadRule, error) {
+	uploadRule := &UploadRule{}
+	err := json.Unmarshal([]byte(rule), uploadRule)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse upload rule %s", rule)
+	} else if uploadRule.Path == "" {
+		return nil, errors.Errorf("upload rule %s has no path", rule)
+	}
+
+	uploadRule.matcher, err = ignoreparser.CompilePaths([]string{uploadRule.Path})
+	if err != nil {
+		return nil, errors.Wrapf(err, "compile upload rule path %s", uploadRule.Path)
+	}
+
+	return uploadRule, nil
+}
+
+// changedPath is a path relative to the upload path that was uploaded or removed
+type changedPath struct {
+	path  string
+	isDir bool
+}
+
+// changedPaths collects the paths of a batch
+type changedPaths []changedPath
+
+func (c *changedPaths) add(path string, isDir bool) {
+	*c = append(*c, changedPath{path: path, isDir: isDir})
+}
+
+func (r *UploadRule) matches(changes changedPaths) bool {
+	for _, change := range changes {
+		if util.MatchesPath(r.matcher, change.path, change.isDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *UploadRule) execute() error {
+	if r.Command != "" {
+		out, err := exec.Command(r.Command, r.Args...).CombinedOutput()
+		if err != nil {
+			return errors.Errorf("Error executing command '%s %s': %s => %v", r.Command, strings.Join(r.Args, " "), string(out), err)
+		}
+	}
+	if r.RestartContainer {
+		err := util.NewContainerRestarter().RestartContainer()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// schedule executes the rule after the debounce and passes the result to done, every call within
+// the debounce restarts the wait
+func (r *UploadRule) schedule(done func(rule *UploadRule, err error)) {
+	r.timerMutex.Lock()
+	defer r.timerMutex.Unlock()
+
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+
+	r.timer = time.AfterFunc(time.Duration(r.Debounce)*time.Millisecond, func() {
+		done(r, r.execute())
+	})
+}
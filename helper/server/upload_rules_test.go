@@ -0,0 +1,219 @@
+// +build !windows
+
+package server
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUploadRuleMatches(t *testing.T) {
+	testCases := map[string]struct {
+		rule     string
+		changes  changedPaths
+		expected bool
+	}{
+		"extension": {
+			rule:     `{"path": "*.go", "command": "true"}`,
+			changes:  changedPaths{{path: "/web/main.css"}, {path: "/cmd/main.go"}},
+			expected: true,
+		},
+		"other extension": {
+			rule:     `{"path": "*.go", "command": "true"}`,
+			changes:  changedPaths{{path: "/web/main.css"}},
+			expected: false,
+		},
+		"folder": {
+			rule:     `{"path": "/web/", "command": "true"}`,
+			changes:  changedPaths{{path: "/web/styles/main.css"}},
+			expected: true,
+		},
+		"removed folder": {
+			rule:     `{"path": "/web/", "command": "true"}`,
+			changes:  changedPaths{{path: "/web", isDir: true}},
+			expected: true,
+		},
+		"empty batch": {
+			rule:     `{"path": "*", "command": "true"}`,
+			expected: false,
+		},
+	}
+
+	for name, testCase := range testCases {
+		rule, err := ParseUploadRule(testCase.rule)
+		if err != nil {
+			t.Fatalf("Test case %s: unexpected error: %v", name, err)
+		}
+
+		matches := rule.matches(testCase.changes)
+		if matches != testCase.expected {
+			t.Fatalf("Test case %s: expected %v, got %v", name, testCase.expected, matches)
+		}
+	}
+
+	_, err := ParseUploadRule(`{"command": "true"}`)
+	if err == nil {
+		t.Fatal("Expected error for rule without path")
+	}
+}
+
+func TestExecuteUploadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	goRule, err := ParseUploadRule(`{"path": "*.go", "command": "touch", "args": ["` + filepath.Join(dir, "go") + `"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cssRule, err := ParseUploadRule(`{"path": "*.css", "command": "touch", "args": ["` + filepath.Join(dir, "css") + `"], "debounce": 50}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upstream := &Upstream{options: &UpstreamOptions{UploadRules: []*UploadRule{goRule, cssRule}}}
+	err = upstream.executeBatchCommand(changedPaths{{path: "/main.go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go")); err != nil {
+		t.Fatalf("Expected go rule to be executed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "css")); err == nil {
+		t.Fatal("Expected css rule not to be executed")
+	}
+
+	// Debounced rules are executed after the batch
+	err = upstream.executeBatchCommand(changedPaths{{path: "/web/main.css"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "css")); err == nil {
+		t.Fatal("Expected css rule to be debounced")
+	}
+
+	// Instead of waiting for the debounce, we stop the timer and execute the rule right away
+	cssRule.timerMutex.Lock()
+	scheduled := cssRule.timer != nil && cssRule.timer.Stop()
+	cssRule.timerMutex.Unlock()
+	if scheduled == false {
+		t.Fatal("Expected css rule to be scheduled")
+	}
+	upstream.debouncedRuleExecuted(cssRule, cssRule.execute())
+	if _, err := os.Stat(filepath.Join(dir, "css")); err != nil {
+		t.Fatalf("Expected css rule to be executed after the debounce: %v", err)
+	}
+	_, err = upstream.Ping(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatalf("Unexpected ping error: %v", err)
+	}
+
+	// Errors of debounced rules are returned by the next ping
+	failingRule, err := ParseUploadRule(`{"path": "*.txt", "command": "false", "debounce": 50}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream.debouncedRuleExecuted(failingRule, failingRule.execute())
+	_, err = upstream.Ping(context.Background(), &remote.Empty{})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("Expected the error of the debounced rule, got %v", err)
+	}
+	_, err = upstream.Ping(context.Background(), &remote.Empty{})
+	if err != nil {
+		t.Fatalf("Expected the error of the debounced rule to be returned only once, got %v", err)
+	}
+}
+
+func TestApplyDeltaBatchCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	uploadPath := filepath.Join(dir, "upload")
+	err = os.Mkdir(uploadPath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localPath := filepath.Join(dir, "local")
+	err = os.Mkdir(localPath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		err = ioutil.WriteFile(filepath.Join(uploadPath, name), []byte("package main"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(localPath, name), []byte("package changed"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the batch command appends a line to the log every time it is executed
+	batchLog := filepath.Join(dir, "batch.log")
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+	go func() {
+		_ = StartUpstreamServer(serverReader, clientWriter, &UpstreamOptions{
+			UploadPath:  uploadPath,
+			BatchCmd:    "sh",
+			BatchArgs:   []string{"-c", "echo batch >> " + batchLog},
+			ExitOnClose: false,
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := remote.NewUpstreamClient(conn)
+
+	for _, name := range []string{"/a.go", "/b.go"} {
+		deltaClient, err := client.ApplyDelta(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = util.SendDelta(filepath.Join(localPath, name), name, 1024, nil, deltaClient.Send)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = deltaClient.CloseAndRecv()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(batchLog); err == nil {
+		t.Fatal("Expected the batch command not to be executed for single deltas")
+	}
+
+	// the batch command runs once the client executes the commands of the batch
+	executeClient, err := client.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = executeClient.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadFile(batchLog)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(out) != "batch\n" {
+		t.Fatalf("Expected the batch command to be executed once, got %q", string(out))
+	}
+}
@@ -2,18 +2,25 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"github.com/loft-sh/devspace/helper/remote"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
 	"github.com/loft-sh/devspace/helper/util"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"io"
 	"io/ioutil"
+	"log"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 // UpstreamOptions holds the upstream server options
@@ -24,12 +31,27 @@ type UpstreamOptions struct {
 	BatchCmd  string
 	BatchArgs []string
 
+	// UploadRules are executed after every batch that changed a path matching them
+	UploadRules []*UploadRule
+
 	FileChangeCmd  string
 	FileChangeArgs []string
 
 	DirCreateCmd  string
 	DirCreateArgs []string
 
+	// Owner, FileMode and DirMode are applied to uploaded files and created folders if set
+	Owner    *Owner
+	FileMode os.FileMode
+	DirMode  os.FileMode
+
+	// PreserveExecutable sets the executable bits of uploaded files as they are locally
+	PreserveExecutable bool
+
+	// Symlinks defines how symlinks are synced, if they are preserved the client sends them as
+	// symlinks and existing remote symlinks are replaced instead of written through
+	Symlinks string
+
 	ExitOnClose bool
 }
 
@@ -54,6 +76,12 @@ func StartUpstreamServer(reader io.Reader, writer io.Writer, options *UpstreamOp
 		})
 		reflection.Register(s)
 
+		// There is only a single connection, so we stop serving as soon as it is closed
+		go func() {
+			<-pipe.Closed()
+			s.Stop()
+		}()
+
 		done <- s.Serve(lis)
 	}()
 
@@ -67,13 +95,60 @@ type Upstream struct {
 
 	// ignore matcher is the ignore matcher which matches against excluded files and paths
 	ignoreMatcher ignoreparser.IgnoreParser
+
+	// deltaChanges are the paths that were patched since the last batch. The client uploads an archive
+	// or executes the commands after the deltas of a batch, which runs the batch command once for all of them
+	deltaChanges      changedPaths
+	deltaChangesMutex sync.Mutex
+
+	// ruleErrors are the errors of debounced upload rules, which run after the batch was acknowledged.
+	// They are returned by the next ping
+	ruleErrors      []string
+	ruleErrorsMutex sync.Mutex
 }
 
-// Ping returns empty
+// Ping returns empty or the errors of the debounced upload rules since the last ping
 func (u *Upstream) Ping(context.Context, *remote.Empty) (*remote.Empty, error) {
+	u.ruleErrorsMutex.Lock()
+	defer u.ruleErrorsMutex.Unlock()
+
+	if len(u.ruleErrors) > 0 {
+		err := status.Error(codes.Aborted, strings.Join(u.ruleErrors, ", "))
+		u.ruleErrors = nil
+		return nil, err
+	}
+
 	return &remote.Empty{}, nil
 }
 
+// debouncedRuleExecuted is called after a debounced upload rule was executed
+func (u *Upstream) debouncedRuleExecuted(rule *UploadRule, err error) {
+	if err == nil {
+		return
+	}
+
+	log.Printf("upload rule %s: %v", rule.Path, err)
+	u.ruleErrorsMutex.Lock()
+	defer u.ruleErrorsMutex.Unlock()
+
+	u.ruleErrors = append(u.ruleErrors, fmt.Sprintf("upload rule %s: %v", rule.Path, err))
+}
+
+// takeDeltaChanges returns the paths that were patched since the last batch and resets them
+func (u *Upstream) takeDeltaChanges() changedPaths {
+	u.deltaChangesMutex.Lock()
+	defer u.deltaChangesMutex.Unlock()
+
+	changes := u.deltaChanges
+	u.deltaChanges = nil
+	return changes
+}
+
+// Capabilities returns the version and features of this helper
+func (u *Upstream) Capabilities(context.Context, *remote.Empty) (*remote.Capabilities, error) {
+	return capabilities(""), nil
+}
+
 // RestartContainer implements the server
 func (u *Upstream) RestartContainer(context.Context, *remote.Empty) (*remote.Empty, error) {
 	err := util.NewContainerRestarter().RestartContainer()
@@ -86,6 +161,8 @@ func (u *Upstream) RestartContainer(context.Context, *remote.Empty) (*remote.Emp
 
 // Remove implements the server
 func (u *Upstream) Remove(stream remote.Upstream_RemoveServer) error {
+	changes := changedPaths{}
+
 	// Receive file
 	for {
 		paths, err := stream.Recv()
@@ -105,12 +182,14 @@ func (u *Upstream) Remove(stream remote.Upstream_RemoveServer) error {
 				} else {
 					_ = os.Remove(absolutePath)
 				}
+
+				changes.add(path, stat.IsDir())
 			}
 		}
 
 		if err == io.EOF {
 			// execute a batch command if needed
-			err = u.executeBatchCommand()
+			err = u.executeBatchCommand(changes)
 			if err != nil {
 				return err
 			}
@@ -171,7 +250,7 @@ func (u *Upstream) Upload(stream remote.Upstream_UploadServer) error {
 		writerErrChan <- u.writeTar(writer, stream)
 	}()
 
-	err := untarAll(reader, u.options)
+	changes, err := untarAll(reader, u.options)
 	if err != nil {
 		return errors.Wrap(err, "untar all")
 	}
@@ -181,15 +260,147 @@ func (u *Upstream) Upload(stream remote.Upstream_UploadServer) error {
 		return errors.Wrap(err, "write tar")
 	}
 
-	// execute a batch command if needed
-	err = u.executeBatchCommand()
+	// execute a batch command if needed, which includes the deltas of this batch
+	err = u.executeBatchCommand(append(u.takeDeltaChanges(), changes...))
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&remote.Empty{})
+}
+
+// Execute runs the file, directory and batch commands for paths that were uploaded over other
+// connections, which were started without commands, so that they run only once for a large upload.
+// The batch command also includes the deltas that were uploaded over this connection
+func (u *Upstream) Execute(stream remote.Upstream_ExecuteServer) error {
+	changes := changedPaths{}
+	for {
+		paths, err := stream.Recv()
+		if paths != nil {
+			for _, path := range paths.Paths {
+				absolutePath, pathErr := u.uploadFilePath(path)
+				if pathErr != nil {
+					return pathErr
+				}
+
+				stat, statErr := os.Lstat(absolutePath)
+				if statErr != nil {
+					continue
+				}
+
+				var cmdErr error
+				if stat.IsDir() {
+					cmdErr = executeDirCreateCommand(absolutePath, u.options)
+				} else if stat.Mode()&os.ModeSymlink == 0 {
+					cmdErr = executeFileChangeCommand(absolutePath, u.options)
+				}
+				if cmdErr != nil {
+					return cmdErr
+				}
+
+				changes.add(path, stat.IsDir())
+			}
+		}
+
+		if err == io.EOF {
+			// execute a batch command if needed
+			err = u.executeBatchCommand(append(u.takeDeltaChanges(), changes...))
+			if err != nil {
+				return err
+			}
+
+			return stream.SendAndClose(&remote.Empty{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Signature sends the block signatures of a file, so that the client only needs to upload the changed blocks
+func (u *Upstream) Signature(request *remote.SignatureRequest, stream remote.Upstream_SignatureServer) error {
+	fileName, err := u.uploadFilePath(request.Path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return errors.Wrap(err, "open file")
+	}
+	defer file.Close()
+
+	return util.ComputeSignature(file, request.BlockSize, func(blocks []*remote.BlockSignature) error {
+		return stream.Send(&remote.Signature{
+			Path:      request.Path,
+			BlockSize: request.BlockSize,
+			Blocks:    blocks,
+		})
+	})
+}
+
+// ApplyDelta recreates an uploaded file from the received changed blocks and the blocks of the existing file
+func (u *Upstream) ApplyDelta(stream remote.Upstream_ApplyDeltaServer) error {
+	delta, err := stream.Recv()
 	if err != nil {
 		return err
 	}
 
+	outFileName, err := u.uploadFilePath(delta.Path)
+	if err != nil {
+		return err
+	}
+
+	stat, err := os.Stat(outFileName)
+	if err != nil {
+		return errors.Wrap(err, "stat file")
+	}
+
+	mtime := time.Unix(delta.MtimeUnix, 0)
+	err = util.PatchFile(outFileName, delta, stream.Recv)
+	if err != nil {
+		return errors.Wrapf(err, "patch %s", outFileName)
+	}
+
+	// Set old or configured permissions, owner & group and mod time from the client. Older clients
+	// don't send the local mode
+	localMode := stat.Mode()
+	if delta.Mode != 0 {
+		localMode = localMode&^os.ModePerm | os.FileMode(delta.Mode)&os.ModePerm
+	}
+	applyFilePermissions(outFileName, stat, localMode, u.options)
+	_ = os.Chtimes(outFileName, time.Now(), mtime)
+
+	err = executeFileChangeCommand(outFileName, u.options)
+	if err != nil {
+		return err
+	}
+
+	// the batch command runs once the whole batch was uploaded
+	u.deltaChangesMutex.Lock()
+	u.deltaChanges.add(delta.Path, false)
+	u.deltaChangesMutex.Unlock()
+
 	return stream.SendAndClose(&remote.Empty{})
 }
 
+// uploadFilePath returns the absolute path of a file relative to the upload path. Paths that point outside
+// of the upload path are rejected
+func (u *Upstream) uploadFilePath(relativePath string) (string, error) {
+	return containedPath(u.options.UploadPath, relativePath)
+}
+
+// containedPath joins the base path and the relative path and rejects the result if it points outside of the base path
+func containedPath(basePath, relativePath string) (string, error) {
+	absolutePath := path.Join(basePath, relativePath)
+	cleanBasePath := path.Clean(basePath)
+	if absolutePath != cleanBasePath && strings.HasPrefix(absolutePath, strings.TrimSuffix(cleanBasePath, "/")+"/") == false {
+		return "", errors.Errorf("path %s is outside of %s", relativePath, basePath)
+	}
+
+	return absolutePath, nil
+}
+
 func (u *Upstream) writeTar(writer io.WriteCloser, stream remote.Upstream_UploadServer) error {
 	defer writer.Close()
 
@@ -213,7 +424,7 @@ func (u *Upstream) writeTar(writer io.WriteCloser, stream remote.Upstream_Upload
 	}
 }
 
-func (u *Upstream) executeBatchCommand() error {
+func (u *Upstream) executeBatchCommand(changes changedPaths) error {
 	if u.options.BatchCmd != "" {
 		out, err := exec.Command(u.options.BatchCmd, u.options.BatchArgs...).CombinedOutput()
 		if err != nil {
@@ -221,5 +432,20 @@ func (u *Upstream) executeBatchCommand() error {
 		}
 	}
 
+	// every rule that matches one of the changed paths is executed
+	for _, rule := range u.options.UploadRules {
+		if rule.matches(changes) == false {
+			continue
+		} else if rule.Debounce > 0 {
+			rule.schedule(u.debouncedRuleExecuted)
+			continue
+		}
+
+		err := rule.execute()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
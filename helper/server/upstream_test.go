@@ -175,7 +175,7 @@ func TestUpstreamServer(t *testing.T) {
 	tarWriter := tar.NewWriter(gw)
 
 	writtenFiles := make(map[string]bool)
-	err = recursiveTar(fromDir, "", writtenFiles, tarWriter, false)
+	err = recursiveTar(fromDir, "", util.SymlinksFollow, writtenFiles, tarWriter, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -272,3 +272,199 @@ func TestUpstreamServer(t *testing.T) {
 		t.Fatalf("Expected empty toDir, but still has %d entries", len(files))
 	}
 }
+
+func TestUpstreamDelta(t *testing.T) {
+	fromDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(fromDir)
+	defer os.RemoveAll(toDir)
+
+	// The local file has a changed block in the middle and some appended data
+	oldContent := make([]byte, 512*1024)
+	rand.Read(oldContent)
+	newContent := append([]byte{}, oldContent...)
+	copy(newContent[200*1024:], []byte("changed content"))
+	newContent = append(newContent, []byte("appended content")...)
+
+	err = ioutil.WriteFile(filepath.Join(toDir, "file"), oldContent, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the local file became executable
+	err = ioutil.WriteFile(filepath.Join(fromDir, "file"), newContent, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		_ = StartUpstreamServer(serverReader, clientWriter, &UpstreamOptions{
+			UploadPath:         toDir,
+			ExitOnClose:        false,
+			PreserveExecutable: true,
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := remote.NewUpstreamClient(conn)
+	blockSize := util.DeltaBlockSize(int64(len(oldContent)))
+	signatureClient, err := client.Signature(context.Background(), &remote.SignatureRequest{
+		Path:      "/file",
+		BlockSize: blockSize,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := []*remote.BlockSignature{}
+	for {
+		signature, err := signatureClient.Recv()
+		if signature != nil {
+			blocks = append(blocks, signature.Blocks...)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deltaClient, err := client.ApplyDelta(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := 0
+	_, err = util.SendDelta(filepath.Join(fromDir, "file"), "/file", blockSize, blocks, func(delta *remote.Delta) error {
+		for _, operation := range delta.Operations {
+			sent += len(operation.Data)
+		}
+
+		return deltaClient.Send(delta)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = deltaClient.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent > 3*int(blockSize) {
+		t.Fatalf("Expected only changed blocks to be sent, but sent %d bytes", sent)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(toDir, "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(newContent) {
+		t.Fatal("Patched file does not match the local file")
+	}
+
+	stat, err := os.Stat(filepath.Join(toDir, "file"))
+	if err != nil {
+		t.Fatal(err)
+	} else if stat.Mode().Perm() != 0755 {
+		t.Fatalf("Expected the executable bits of the local file, got %v", stat.Mode().Perm())
+	}
+}
+
+func TestApplyDeltaOutsideUploadPath(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	uploadPath := filepath.Join(baseDir, "upload")
+	err = os.Mkdir(uploadPath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(baseDir, "outside"), []byte("outside"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+
+	go func() {
+		_ = StartUpstreamServer(serverReader, clientWriter, &UpstreamOptions{
+			UploadPath:  uploadPath,
+			ExitOnClose: false,
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := remote.NewUpstreamClient(conn)
+	signatureClient, err := client.Signature(context.Background(), &remote.SignatureRequest{
+		Path:      "../outside",
+		BlockSize: 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = signatureClient.Recv()
+	if err == nil || err == io.EOF {
+		t.Fatal("Expected an error for a signature of a file outside of the upload path")
+	}
+
+	deltaClient, err := client.ApplyDelta(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = deltaClient.Send(&remote.Delta{
+		Path: "../outside",
+		Operations: []*remote.DeltaOperation{
+			{Data: []byte("changed")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = deltaClient.CloseAndRecv()
+	if err == nil {
+		t.Fatal("Expected an error for a delta of a file outside of the upload path")
+	}
+
+	executeClient, err := client.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = executeClient.Send(&remote.Paths{Paths: []string{"../outside"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = executeClient.CloseAndRecv()
+	if err == nil {
+		t.Fatal("Expected an error for executing the commands of a file outside of the upload path")
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(baseDir, "outside"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(content) != "outside" {
+		t.Fatalf("File outside of the upload path was changed to %s", string(content))
+	}
+}
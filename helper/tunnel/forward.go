@@ -0,0 +1,199 @@
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/pkg/errors"
+)
+
+// forwardTarget holds the sockets the forward tunnel opened to the target port for every session
+type forwardTarget struct {
+	stream remote.Tunnel_ForwardServer
+	scheme string
+	host   string
+	port   int32
+
+	sendMutex sync.Mutex
+
+	connsMutex sync.Mutex
+	conns      map[string]net.Conn
+}
+
+// Forward implements the server. The first request selects the port and scheme and optionally a host,
+// which defaults to localhost. Every following request carries the data of a session, which the server
+// sends to the target over its own socket. Every read from that socket is sent back as its own response,
+// so that datagram boundaries are kept
+func (t *tunnelServer) Forward(stream remote.Tunnel_ForwardServer) error {
+	request, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed receiving initial forward request")
+	}
+	if request.GetPort() == 0 {
+		_ = stream.Send(&remote.SocketDataResponse{
+			HasErr: true,
+			LogMessage: &remote.LogMessage{
+				LogLevel: remote.LogLevel_ERROR,
+				Message:  "missing port",
+			},
+		})
+		return errors.New("missing port")
+	}
+
+	target := &forwardTarget{
+		stream: stream,
+		scheme: strings.ToLower(request.GetScheme().String()),
+		host:   request.GetHost(),
+		port:   request.GetPort(),
+		conns:  map[string]net.Conn{},
+	}
+	if target.host == "" {
+		target.host = "localhost"
+	}
+	defer target.closeAll()
+
+	for {
+		request, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		err = target.handle(request)
+		if err != nil {
+			target.send(&remote.SocketDataResponse{
+				HasErr: true,
+				LogMessage: &remote.LogMessage{
+					LogLevel: remote.LogLevel_WARNING,
+					Message:  err.Error(),
+				},
+				RequestId:   request.GetRequestId(),
+				ShouldClose: true,
+			})
+		}
+	}
+}
+
+func (f *forwardTarget) handle(request *remote.SocketDataRequest) error {
+	if request.GetShouldClose() {
+		f.close(request.GetRequestId())
+		return nil
+	}
+
+	conn, err := f.conn(request.GetRequestId())
+	if err != nil {
+		return err
+	}
+
+	if len(request.GetData()) > 0 {
+		_, err = conn.Write(request.GetData())
+		if err != nil {
+			f.close(request.GetRequestId())
+			return errors.Errorf("%s; failed writing to %s %s: %v", request.GetRequestId(), f.scheme, f.address(), err)
+		}
+	}
+
+	return nil
+}
+
+func (f *forwardTarget) address() string {
+	return net.JoinHostPort(f.host, strconv.Itoa(int(f.port)))
+}
+
+// conn returns the socket of the session or opens a new one. After a new socket was opened, an empty
+// response is sent, so that clients can wait until the target is reachable
+func (f *forwardTarget) conn(requestID string) (net.Conn, error) {
+	f.connsMutex.Lock()
+	defer f.connsMutex.Unlock()
+
+	if f.conns[requestID] != nil {
+		return f.conns[requestID], nil
+	}
+
+	conn, err := net.DialTimeout(f.scheme, f.address(), time.Second*5)
+	if err != nil {
+		return nil, errors.Errorf("%s; failed connecting to %s %s: %v", requestID, f.scheme, f.address(), err)
+	}
+
+	f.conns[requestID] = conn
+	f.send(&remote.SocketDataResponse{
+		RequestId: requestID,
+	})
+	go f.read(requestID, conn)
+	return conn, nil
+}
+
+// read sends everything that is read from the socket back to the client until the socket is closed
+func (f *forwardTarget) read(requestID string, conn net.Conn) {
+	buff := make([]byte, bufferSize)
+	for {
+		n, err := conn.Read(buff)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buff[:n])
+			f.send(&remote.SocketDataResponse{
+				RequestId: requestID,
+				Data:      data,
+			})
+		}
+		if err != nil {
+			// the session is closed on the client side as well if the socket was not closed by the client
+			if f.remove(requestID, conn) {
+				f.send(&remote.SocketDataResponse{
+					RequestId:   requestID,
+					ShouldClose: true,
+				})
+			}
+
+			return
+		}
+	}
+}
+
+func (f *forwardTarget) send(response *remote.SocketDataResponse) {
+	f.sendMutex.Lock()
+	defer f.sendMutex.Unlock()
+
+	err := f.stream.Send(response)
+	if err != nil {
+		logErrorf("%s; failed sending message to forward stream: %v", response.RequestId, err)
+	}
+}
+
+// remove removes the socket of the session and returns true if it was still registered
+func (f *forwardTarget) remove(requestID string, conn net.Conn) bool {
+	f.connsMutex.Lock()
+	defer f.connsMutex.Unlock()
+
+	_ = conn.Close()
+	if f.conns[requestID] != conn {
+		return false
+	}
+
+	delete(f.conns, requestID)
+	return true
+}
+
+func (f *forwardTarget) close(requestID string) {
+	f.connsMutex.Lock()
+	defer f.connsMutex.Unlock()
+
+	if f.conns[requestID] != nil {
+		_ = f.conns[requestID].Close()
+		delete(f.conns, requestID)
+	}
+}
+
+func (f *forwardTarget) closeAll() {
+	f.connsMutex.Lock()
+	defer f.connsMutex.Unlock()
+
+	for requestID, conn := range f.conns {
+		_ = conn.Close()
+		delete(f.conns, requestID)
+	}
+}
@@ -0,0 +1,24 @@
+package util
+
+const (
+	// FeatureChecksums is supported if the downstream server can calculate file checksums
+	FeatureChecksums = "checksums"
+	// FeatureChangesNotify is supported if the downstream server pushes remote changes to the client
+	FeatureChangesNotify = "changesNotify"
+	// FeatureDelta is supported if the servers can transfer only the changed blocks of files
+	FeatureDelta = "delta"
+	// FeatureSymlinks is supported if the servers can preserve or skip symlinks instead of following them
+	FeatureSymlinks = "symlinks"
+	// FeatureExecute is supported if the upstream server can run the upload commands for paths that were uploaded over other connections
+	FeatureExecute = "execute"
+)
+
+// SupportedFeatures are the optional protocol features this helper supports
+var SupportedFeatures = []string{FeatureChecksums, FeatureChangesNotify, FeatureDelta, FeatureSymlinks, FeatureExecute}
+
+const (
+	// WatchModeInotify means remote changes are detected through filesystem events
+	WatchModeInotify = "inotify"
+	// WatchModePolling means remote changes are detected by walking the remote path periodically
+	WatchModePolling = "polling"
+)
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CompressionGzip is the compression every helper understands and is used if nothing else was negotiated
+	CompressionGzip = "gzip"
+	// CompressionZstd is faster and compresses better than gzip
+	CompressionZstd = "zstd"
+)
+
+// SupportedCompressions are the compressions this binary supports ordered by preference
+var SupportedCompressions = []string{CompressionZstd, CompressionGzip}
+
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// NegotiateCompression returns the most preferred compression that is supported by both sides. If the other
+// side does not support any known compression, gzip is returned
+func NegotiateCompression(remoteCompressions []string) string {
+	for _, compression := range SupportedCompressions {
+		for _, remoteCompression := range remoteCompressions {
+			if compression == remoteCompression {
+				return compression
+			}
+		}
+	}
+
+	return CompressionGzip
+}
+
+// NewCompressionWriter returns a writer that compresses the written data with the given compression
+func NewCompressionWriter(writer io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionZstd:
+		return zstd.NewWriter(writer)
+	case CompressionGzip, "":
+		return gzip.NewWriter(writer), nil
+	}
+
+	return nil, errors.Errorf("unsupported compression %s", compression)
+}
+
+// NewDecompressionReader returns a reader that decompresses the data of the given reader. The compression
+// is detected from the first bytes of the stream, so that the receiver doesn't need to know it in advance
+func NewDecompressionReader(reader io.Reader) (io.ReadCloser, error) {
+	bufferedReader := bufio.NewReader(reader)
+	magic, err := bufferedReader.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if bytes.Equal(magic, zstdMagic) {
+		decoder, err := zstd.NewReader(bufferedReader)
+		if err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+	}
+
+	return gzip.NewReader(bufferedReader)
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	testCases := map[string]struct {
+		remote   []string
+		expected string
+	}{
+		"old helper":     {remote: nil, expected: CompressionGzip},
+		"gzip only":      {remote: []string{CompressionGzip}, expected: CompressionGzip},
+		"zstd preferred": {remote: []string{CompressionGzip, CompressionZstd}, expected: CompressionZstd},
+		"unknown":        {remote: []string{"lz4"}, expected: CompressionGzip},
+	}
+
+	for name, testCase := range testCases {
+		compression := NegotiateCompression(testCase.remote)
+		if compression != testCase.expected {
+			t.Fatalf("Test case %s: expected %s, got %s", name, testCase.expected, compression)
+		}
+	}
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	content := bytes.Repeat([]byte("devspace sync "), 4096)
+	for _, compression := range SupportedCompressions {
+		buffer := &bytes.Buffer{}
+		writer, err := NewCompressionWriter(buffer, compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = writer.Write(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = writer.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buffer.Len() >= len(content) {
+			t.Fatalf("Expected %s to compress the content", compression)
+		}
+
+		reader, err := NewDecompressionReader(buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader.Close()
+		if bytes.Equal(out, content) == false {
+			t.Fatalf("Unexpected content after %s round trip", compression)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/pkg/errors"
+)
+
+const (
+	minDeltaBlockSize = 2 * 1024
+	maxDeltaBlockSize = 64 * 1024
+
+	// maxDeltaMessageSize is the maximum amount of literal data sent in a single delta message
+	maxDeltaMessageSize = 512 * 1024
+)
+
+// DeltaBlockSize returns the block size that should be used to compare a file with the given size
+func DeltaBlockSize(size int64) int64 {
+	blockSize := int64(math.Sqrt(float64(size)))
+	if blockSize < minDeltaBlockSize {
+		return minDeltaBlockSize
+	} else if blockSize > maxDeltaBlockSize {
+		return maxDeltaBlockSize
+	}
+
+	return blockSize
+}
+
+// weakChecksum is the rsync rolling checksum of a block
+type weakChecksum struct {
+	a, b uint32
+	n    uint32
+}
+
+func newWeakChecksum(block []byte) *weakChecksum {
+	w := &weakChecksum{n: uint32(len(block))}
+	for i, c := range block {
+		w.a += uint32(c)
+		w.b += uint32(len(block)-i) * uint32(c)
+	}
+
+	return w
+}
+
+// roll removes the byte out from the front of the block and appends the byte in
+func (w *weakChecksum) roll(out, in byte) {
+	w.a = w.a - uint32(out) + uint32(in)
+	w.b = w.b - w.n*uint32(out) + w.a
+}
+
+// shrink removes the byte out from the front of the block
+func (w *weakChecksum) shrink(out byte) {
+	w.b -= w.n * uint32(out)
+	w.a -= uint32(out)
+	w.n--
+}
+
+func (w *weakChecksum) sum() uint32 {
+	return (w.a & 0xffff) | (w.b << 16)
+}
+
+func strongChecksum(block []byte) []byte {
+	hash := md5.Sum(block)
+	return hash[:]
+}
+
+// ComputeSignature reads the given reader in blocks of blockSize and calls send with the signatures of the blocks
+func ComputeSignature(reader io.Reader, blockSize int64, send func(blocks []*remote.BlockSignature) error) error {
+	buffer := make([]byte, blockSize)
+	blocks := make([]*remote.BlockSignature, 0, 1024)
+	for index := int64(0); ; index++ {
+		n, err := io.ReadFull(reader, buffer)
+		if n > 0 {
+			blocks = append(blocks, &remote.BlockSignature{
+				Index:  index,
+				Weak:   newWeakChecksum(buffer[:n]).sum(),
+				Strong: strongChecksum(buffer[:n]),
+			})
+
+			if len(blocks) >= cap(blocks) {
+				err := send(blocks)
+				if err != nil {
+					return err
+				}
+
+				blocks = make([]*remote.BlockSignature, 0, 1024)
+			}
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return send(blocks)
+}
+
+// ComputeDelta compares the data of the reader with the block signatures of the old file and calls send with
+// the operations that are needed to recreate the data from the old file
+func ComputeDelta(reader io.Reader, blockSize int64, signatures []*remote.BlockSignature, send func(operations []*remote.DeltaOperation) error) error {
+	weakIndex := make(map[uint32][]*remote.BlockSignature, len(signatures))
+	for _, signature := range signatures {
+		weakIndex[signature.Weak] = append(weakIndex[signature.Weak], signature)
+	}
+
+	var (
+		bufferedReader = bufio.NewReaderSize(reader, int(blockSize)*4)
+		operations     = []*remote.DeltaOperation{}
+		literal        = []byte{}
+		literalSize    = 0
+	)
+
+	flush := func(force bool) error {
+		if len(literal) > 0 {
+			operations = append(operations, &remote.DeltaOperation{BlockIndex: -1, Data: literal})
+			literalSize += len(literal)
+			literal = []byte{}
+		}
+		if len(operations) > 0 && (force || literalSize >= maxDeltaMessageSize || len(operations) >= 1024) {
+			err := send(operations)
+			if err != nil {
+				return err
+			}
+
+			operations = []*remote.DeltaOperation{}
+			literalSize = 0
+		}
+
+		return nil
+	}
+
+	// window holds the current block, the buffer is bigger so that we don't
+	// have to move the window with every byte
+	buffer := make([]byte, blockSize*4)
+	start, end := 0, 0
+	fill := func() error {
+		start, end = 0, 0
+		n, err := io.ReadFull(bufferedReader, buffer[:blockSize])
+		end = n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+
+		return err
+	}
+
+	err := fill()
+	if err != nil {
+		return err
+	}
+
+	weak := newWeakChecksum(buffer[start:end])
+	for end > start {
+		window := buffer[start:end]
+		if match := findBlock(weakIndex[weak.sum()], window); match != nil {
+			err = flush(false)
+			if err != nil {
+				return err
+			}
+
+			operations = append(operations, &remote.DeltaOperation{BlockIndex: match.Index})
+			err = fill()
+			if err != nil {
+				return err
+			}
+
+			weak = newWeakChecksum(buffer[start:end])
+			continue
+		}
+
+		// No match, so the first byte of the window is new data
+		out := buffer[start]
+		literal = append(literal, out)
+		if len(literal) >= maxDeltaMessageSize {
+			err = flush(false)
+			if err != nil {
+				return err
+			}
+		}
+
+		in, err := bufferedReader.ReadByte()
+		if err == io.EOF {
+			weak.shrink(out)
+			start++
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		// Move the window back to the start of the buffer if there is no space left
+		if end == len(buffer) {
+			copy(buffer, buffer[start:end])
+			end -= start
+			start = 0
+		}
+
+		weak.roll(out, in)
+		buffer[end] = in
+		start++
+		end++
+	}
+
+	return flush(true)
+}
+
+func findBlock(candidates []*remote.BlockSignature, window []byte) *remote.BlockSignature {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	strong := strongChecksum(window)
+	for _, candidate := range candidates {
+		if bytes.Equal(candidate.Strong, strong) {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// ApplyDelta writes the data described by the operations to the writer and copies matched blocks from the old file
+func ApplyDelta(old io.ReaderAt, blockSize int64, operations []*remote.DeltaOperation, writer io.Writer) error {
+	buffer := make([]byte, blockSize)
+	for _, operation := range operations {
+		if operation.BlockIndex < 0 {
+			_, err := writer.Write(operation.Data)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		n, err := old.ReadAt(buffer, operation.BlockIndex*blockSize)
+		if err != nil && err != io.EOF {
+			return errors.Wrapf(err, "read block %d", operation.BlockIndex)
+		} else if n == 0 {
+			return errors.Errorf("block %d does not exist", operation.BlockIndex)
+		}
+
+		_, err = writer.Write(buffer[:n])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PatchFile recreates the file at filename from the received delta operations that were computed against it. The
+// new contents are written to a temporary file next to it first, which only replaces the file if size and checksum
+// match the delta
+func PatchFile(filename string, delta *remote.Delta, recv func() (*remote.Delta, error)) error {
+	old, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer old.Close()
+
+	stat, err := old.Stat()
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(filepath.Dir(filename), ".devspace-delta-")
+	if err != nil {
+		return errors.Wrap(err, "create temp file")
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	var (
+		hash      = sha256.New()
+		writer    = io.MultiWriter(tempFile, hash)
+		blockSize = delta.BlockSize
+		size      = delta.Size
+		checksum  = delta.Sha256
+	)
+	for {
+		err = ApplyDelta(old, blockSize, delta.Operations, writer)
+		if err != nil {
+			return errors.Wrap(err, "apply delta")
+		}
+
+		delta, err = recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		return err
+	}
+
+	tempStat, err := os.Stat(tempFile.Name())
+	if err != nil {
+		return err
+	} else if tempStat.Size() != size {
+		return errors.Errorf("patched file size %d does not match expected size %d", tempStat.Size(), size)
+	} else if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		return errors.New("patched file checksum does not match")
+	}
+
+	_ = os.Chmod(tempFile.Name(), stat.Mode())
+	return os.Rename(tempFile.Name(), filename)
+}
+
+// SendDelta computes the delta of the file at filename against the block signatures of the old file and sends it.
+// The first sent delta also holds the path, mtime, size and checksum of the file
+func SendDelta(filename, relativePath string, blockSize int64, signatures []*remote.BlockSignature, send func(delta *remote.Delta) error) (os.FileInfo, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := FileChecksum(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "checksum")
+	}
+
+	header := &remote.Delta{
+		Path:      relativePath,
+		MtimeUnix: stat.ModTime().Unix(),
+		Size:      stat.Size(),
+		Sha256:    checksum,
+		BlockSize: blockSize,
+		Mode:      uint32(stat.Mode().Perm()),
+	}
+	err = ComputeDelta(file, blockSize, signatures, func(operations []*remote.DeltaOperation) error {
+		if header != nil {
+			delta := header
+			delta.Operations = operations
+			header = nil
+			return send(delta)
+		}
+
+		return send(&remote.Delta{Operations: operations})
+	})
+	if err != nil {
+		return nil, err
+	} else if header != nil {
+		// The file is empty, so there were no operations
+		err = send(header)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stat, nil
+}
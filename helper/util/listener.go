@@ -1,33 +1,50 @@
 package util
 
 import (
+	"errors"
 	"net"
+	"sync"
 )
 
 // NewStdinListener creates a new stdin listener
 func NewStdinListener() *StdinListener {
 	return &StdinListener{
 		connChan: make(chan net.Conn),
+		closed:   make(chan struct{}),
 	}
 }
 
 // StdinListener implements the listener interface
 type StdinListener struct {
 	connChan chan net.Conn
+
+	closeOnce sync.Once
+	closed    chan struct{}
 }
 
 // Ready implements interface
 func (lis *StdinListener) Ready(conn net.Conn) {
-	lis.connChan <- conn
+	select {
+	case lis.connChan <- conn:
+	case <-lis.closed:
+	}
 }
 
 // Accept implements interface
 func (lis *StdinListener) Accept() (net.Conn, error) {
-	return <-lis.connChan, nil
+	select {
+	case conn := <-lis.connChan:
+		return conn, nil
+	case <-lis.closed:
+		return nil, errors.New("listener closed")
+	}
 }
 
 // Close implements interface
 func (lis *StdinListener) Close() error {
+	lis.closeOnce.Do(func() {
+		close(lis.closed)
+	})
 	return nil
 }
 
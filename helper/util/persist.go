@@ -0,0 +1,134 @@
+package util
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// PopulatePersistentPath copies the path of the container into the volume path within the volume if the volume
+// path does not exist yet. The path is copied to the staging path within the volume first and then moved to the
+// volume path, so that an interrupted copy is started over. Directories are copied with their content, paths that
+// do not exist are created as empty directories
+func PopulatePersistentPath(sourcePath, volume, volumePath, stagingPath string) error {
+	target := filepath.Join(volume, volumePath)
+	_, err := os.Lstat(target)
+	if err == nil {
+		return nil
+	} else if os.IsNotExist(err) == false {
+		return errors.Wrapf(err, "stat %s", target)
+	}
+
+	staging := filepath.Join(volume, stagingPath, volumePath)
+	err = os.RemoveAll(staging)
+	if err != nil {
+		return errors.Wrapf(err, "remove %s", staging)
+	}
+	err = os.MkdirAll(filepath.Dir(staging), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "create %s", filepath.Dir(staging))
+	}
+
+	_, err = os.Lstat(sourcePath)
+	if os.IsNotExist(err) {
+		err = os.Mkdir(staging, 0755)
+	} else if err == nil {
+		err = copyPersistentPath(sourcePath, staging)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "copy %s", sourcePath)
+	}
+
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "create %s", filepath.Dir(target))
+	}
+
+	return os.Rename(staging, target)
+}
+
+// copyPersistentPath copies the file, directory or symlink with its mode and, if possible, its owner
+func copyPersistentPath(source, target string) error {
+	stat, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case stat.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(source)
+		if err != nil {
+			return err
+		}
+
+		err = os.Symlink(link, target)
+		if err != nil {
+			return err
+		}
+	case stat.IsDir():
+		err = os.Mkdir(target, stat.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		names, err := f.Readdirnames(-1)
+		f.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			err = copyPersistentPath(filepath.Join(source, name), filepath.Join(target, name))
+			if err != nil {
+				return err
+			}
+		}
+
+		// the mode is set again in case the umask removed permissions
+		err = os.Chmod(target, stat.Mode().Perm())
+		if err != nil {
+			return err
+		}
+	case stat.Mode().IsRegular():
+		err = copyPersistentFile(source, target, stat.Mode().Perm())
+		if err != nil {
+			return err
+		}
+	default:
+		// devices, sockets and pipes are not persisted
+		return nil
+	}
+
+	return preserveOwner(target, stat)
+}
+
+func copyPersistentFile(source, target string, mode os.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	err = out.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Chmod(target, mode)
+}
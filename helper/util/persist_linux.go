@@ -0,0 +1,19 @@
+// +build linux
+
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// preserveOwner changes the owner of the copied path to the owner of the source if the helper runs as root.
+// Otherwise the copied path belongs to the user the helper runs as
+func preserveOwner(target string, source os.FileInfo) error {
+	stat, ok := source.Sys().(*syscall.Stat_t)
+	if ok == false || os.Geteuid() != 0 {
+		return nil
+	}
+
+	return os.Lchown(target, int(stat.Uid), int(stat.Gid))
+}
@@ -0,0 +1,9 @@
+// +build !linux
+
+package util
+
+import "os"
+
+func preserveOwner(target string, source os.FileInfo) error {
+	return nil
+}
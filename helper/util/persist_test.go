@@ -0,0 +1,79 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPopulatePersistentPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	image := filepath.Join(dir, "image")
+	volume := filepath.Join(dir, "volume")
+	for name, content := range map[string]string{
+		"app/node_modules/module/index.js": "module",
+		"root/.bash_history":               "ls",
+	} {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(image, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(image, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Symlink("module/index.js", filepath.Join(image, "app/node_modules/link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// directories are copied with their content, files as files and missing paths are created as directories
+	for source, volumePath := range map[string]string{
+		"app/node_modules":   "app/node_modules",
+		"root/.bash_history": "history",
+		"missing":            "missing",
+	} {
+		err = PopulatePersistentPath(filepath.Join(image, source), volume, volumePath, ".devspace-staging")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(volume, "app/node_modules/module/index.js"))
+	if err != nil || string(content) != "module" {
+		t.Fatalf("Unexpected content %q: %v", content, err)
+	}
+	link, err := os.Readlink(filepath.Join(volume, "app/node_modules/link"))
+	if err != nil || link != "module/index.js" {
+		t.Fatalf("Unexpected symlink %q: %v", link, err)
+	}
+	stat, err := os.Stat(filepath.Join(volume, "history"))
+	if err != nil || stat.Mode().IsRegular() == false || stat.Mode().Perm() != 0600 {
+		t.Fatalf("Unexpected history file %v: %v", stat, err)
+	}
+	stat, err = os.Stat(filepath.Join(volume, "missing"))
+	if err != nil || stat.IsDir() == false {
+		t.Fatalf("Expected missing path to be created as directory: %v", err)
+	}
+
+	// existing volume paths are not populated again
+	err = ioutil.WriteFile(filepath.Join(volume, "history"), []byte("changed"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = PopulatePersistentPath(filepath.Join(image, "root/.bash_history"), volume, "history", ".devspace-staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err = ioutil.ReadFile(filepath.Join(volume, "history"))
+	if err != nil || string(content) != "changed" {
+		t.Fatalf("Expected history to be kept, got %q: %v", content, err)
+	}
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// SizeRule limits the size of files that match the path pattern in .gitignore syntax
+type SizeRule struct {
+	Path    string `json:"path"`
+	MaxSize int64  `json:"maxSize"`
+}
+
+// ParseSizeRule parses a size rule from its json representation
+func ParseSizeRule(rule string) (SizeRule, error) {
+	sizeRule := SizeRule{}
+	err := json.Unmarshal([]byte(rule), &sizeRule)
+	if err != nil {
+		return sizeRule, errors.Wrapf(err, "parse size rule %s", rule)
+	} else if sizeRule.Path == "" {
+		return sizeRule, errors.Errorf("size rule %s has no path", rule)
+	}
+
+	return sizeRule, nil
+}
+
+// SizeLimits decides which files are too large to be synced. A nil SizeLimits has no limits
+type SizeLimits struct {
+	maxFileSize int64
+	rules       []SizeRule
+	matchers    []gitignore.IgnoreParser
+}
+
+// NewSizeLimits creates the size limits for the given max file size and rules, where the last
+// matching rule wins over earlier rules and the max file size. If there are no limits, nil is returned
+func NewSizeLimits(maxFileSize int64, rules []SizeRule) (*SizeLimits, error) {
+	if maxFileSize <= 0 && len(rules) == 0 {
+		return nil, nil
+	}
+
+	limits := &SizeLimits{
+		maxFileSize: maxFileSize,
+		rules:       rules,
+		matchers:    make([]gitignore.IgnoreParser, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		matcher, err := gitignore.CompileIgnoreLines(rule.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "compile size rule %s", rule.Path)
+		}
+
+		limits.matchers = append(limits.matchers, matcher)
+	}
+
+	return limits, nil
+}
+
+// MaxSize returns the max size of the file at the given relative path or 0 if it has no limit
+func (l *SizeLimits) MaxSize(relativePath string) int64 {
+	if l == nil {
+		return 0
+	}
+
+	for i := len(l.rules) - 1; i >= 0; i-- {
+		if MatchesPath(l.matchers[i], relativePath, false) {
+			return l.rules[i].MaxSize
+		}
+	}
+
+	return l.maxFileSize
+}
+
+// Exceeds checks if the file at the given relative path is too large to be synced
+func (l *SizeLimits) Exceeds(relativePath string, size int64) bool {
+	maxSize := l.MaxSize(relativePath)
+	return maxSize > 0 && size > maxSize
+}
@@ -0,0 +1,35 @@
+package util
+
+import "testing"
+
+func TestSizeLimits(t *testing.T) {
+	testCases := map[string]struct {
+		maxFileSize  int64
+		rules        []SizeRule
+		relativePath string
+		size         int64
+		expected     bool
+	}{
+		"no limits":          {relativePath: "/file", size: 1 << 30, expected: false},
+		"below max":          {maxFileSize: 100, relativePath: "/file", size: 100, expected: false},
+		"above max":          {maxFileSize: 100, relativePath: "/file", size: 101, expected: true},
+		"rule overrides max": {maxFileSize: 100, rules: []SizeRule{{Path: "*.bin", MaxSize: 1000}}, relativePath: "/a/file.bin", size: 500, expected: false},
+		"rule not matching":  {maxFileSize: 100, rules: []SizeRule{{Path: "*.bin", MaxSize: 1000}}, relativePath: "/a/file.txt", size: 500, expected: true},
+		"rule without max":   {rules: []SizeRule{{Path: "/assets/", MaxSize: 10}}, relativePath: "/assets/logo.png", size: 11, expected: true},
+		"unlimited rule":     {maxFileSize: 100, rules: []SizeRule{{Path: "*.db", MaxSize: 0}}, relativePath: "/data.db", size: 1 << 30, expected: false},
+		"last rule wins":     {rules: []SizeRule{{Path: "*.log", MaxSize: 10}, {Path: "/logs/", MaxSize: 1000}}, relativePath: "/logs/app.log", size: 500, expected: false},
+		"earlier rule wins":  {rules: []SizeRule{{Path: "/logs/", MaxSize: 1000}, {Path: "*.log", MaxSize: 10}}, relativePath: "/logs/app.log", size: 500, expected: true},
+	}
+
+	for name, testCase := range testCases {
+		sizeLimits, err := NewSizeLimits(testCase.maxFileSize, testCase.rules)
+		if err != nil {
+			t.Fatalf("Test case %s: %v", name, err)
+		}
+
+		exceeds := sizeLimits.Exceeds(testCase.relativePath, testCase.size)
+		if exceeds != testCase.expected {
+			t.Fatalf("Test case %s: expected %v, got %v", name, testCase.expected, exceeds)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SymlinksFollow means symlinks are resolved and the files they point to are synced
+	SymlinksFollow = "follow"
+	// SymlinksPreserve means symlinks are synced as symlinks
+	SymlinksPreserve = "preserve"
+	// SymlinksSkip means symlinks are not synced at all
+	SymlinksSkip = "skip"
+)
+
+// SymlinkWithinRoot checks if the target of the symlink at the given path relative to the sync root points to a path
+// within the sync root. Absolute targets are never within the root, because they would point to different files
+// locally and in the container. Symlinks on the way to the target are resolved, so a target that points into a
+// symlinked folder outside of the root is not within the root either
+func SymlinkWithinRoot(rootPath, relativePath, target string) bool {
+	if target == "" || filepath.IsAbs(target) || path.IsAbs(filepath.ToSlash(target)) {
+		return false
+	}
+
+	resolved := path.Join(path.Dir(strings.TrimPrefix(filepath.ToSlash(relativePath), "/")), filepath.ToSlash(target))
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return false
+	}
+
+	root, err := filepath.EvalSymlinks(rootPath)
+	if err != nil {
+		return false
+	}
+
+	// The target may not exist yet, then the closest existing folder on the way to it is resolved
+	realPath := filepath.Join(rootPath, filepath.FromSlash(resolved))
+	for {
+		evaluated, err := filepath.EvalSymlinks(realPath)
+		if err == nil {
+			realPath = evaluated
+			break
+		} else if os.IsNotExist(err) == false {
+			return false
+		}
+
+		realPath = filepath.Dir(realPath)
+	}
+
+	relative, err := filepath.Rel(root, realPath)
+	return err == nil && relative != ".." && strings.HasPrefix(relative, ".."+string(filepath.Separator)) == false
+}
@@ -0,0 +1,67 @@
+// +build !windows
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSymlinkWithinRoot(t *testing.T) {
+	root, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	outside, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+
+	// folders within the root that point inside and outside of it
+	err = os.Mkdir(filepath.Join(root, "inside"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(filepath.Join(root, "inside"), filepath.Join(root, "insideLink"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(outside, filepath.Join(root, "outsideLink"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		relativePath string
+		target       string
+		expected     bool
+	}{
+		"sibling":              {relativePath: "/current", target: "releases/v2", expected: true},
+		"parent in root":       {relativePath: "/a/b/link", target: "../c", expected: true},
+		"dot":                  {relativePath: "/a/link", target: ".", expected: true},
+		"root":                 {relativePath: "/a/link", target: "..", expected: true},
+		"escapes root":         {relativePath: "/link", target: "../outside", expected: false},
+		"escapes from sub":     {relativePath: "/a/link", target: "../../outside", expected: false},
+		"parent of root":       {relativePath: "/link", target: "..", expected: false},
+		"escapes and back":     {relativePath: "/link", target: "../module/file", expected: false},
+		"absolute":             {relativePath: "/link", target: "/etc/passwd", expected: false},
+		"empty":                {relativePath: "/link", target: "", expected: false},
+		"without slash":        {relativePath: "a/link", target: "b", expected: true},
+		"dots in file name":    {relativePath: "/link", target: "..file", expected: true},
+		"symlink inside":       {relativePath: "/link", target: "insideLink/file", expected: true},
+		"symlink outside":      {relativePath: "/link", target: "outsideLink", expected: false},
+		"through symlink":      {relativePath: "/link", target: "outsideLink/file", expected: false},
+		"deep through symlink": {relativePath: "/a/link", target: "../outsideLink/a/b", expected: false},
+	}
+
+	for name, testCase := range testCases {
+		withinRoot := SymlinkWithinRoot(root, testCase.relativePath, testCase.target)
+		if withinRoot != testCase.expected {
+			t.Fatalf("Test case %s: expected %v, got %v", name, testCase.expected, withinRoot)
+		}
+	}
+}
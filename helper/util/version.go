@@ -0,0 +1,13 @@
+package util
+
+// version is set during the build of the helper binary
+var version string
+
+// GetVersion returns the version of the helper binary
+func GetVersion() string {
+	if version == "" {
+		return "latest"
+	}
+
+	return version
+}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable"
@@ -23,6 +24,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/kubeconfig"
 	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/port"
 
 	version "github.com/hashicorp/go-version"
 )
@@ -238,9 +240,6 @@ func (l *configLoader) parseConfig(rawConfig map[interface{}]interface{}, parser
 		options.Profile = generatedConfig.ActiveProfile
 	}
 
-	// create a new variable resolver
-	resolver := l.newVariableResolver(generatedConfig, options, log)
-
 	// copy raw config
 	copiedRawConfig, err := copyRaw(rawConfig)
 	if err != nil {
@@ -253,6 +252,15 @@ func (l *configLoader) parseConfig(rawConfig map[interface{}]interface{}, parser
 		return nil, nil, nil, err
 	}
 
+	// the ports of named port mappings are available as variables before they are forwarded
+	localPorts, err := namedLocalPorts(copiedRawConfig, generatedConfig.Vars)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// create a new variable resolver
+	resolver := l.newVariableResolver(generatedConfig, localPorts, options, log)
+
 	// Load defined variables
 	vars, err := versions.ParseVariables(copiedRawConfig, log)
 	if err != nil {
@@ -335,7 +343,7 @@ func (l *configLoader) applyProfiles(data map[interface{}]interface{}, options *
 	return data, nil
 }
 
-func (l *configLoader) newVariableResolver(generatedConfig *generated.Config, options *ConfigOptions, log log.Logger) variable.Resolver {
+func (l *configLoader) newVariableResolver(generatedConfig *generated.Config, localPorts map[string]string, options *ConfigOptions, log log.Logger) variable.Resolver {
 	return variable.NewResolver(generatedConfig.Vars, &variable.PredefinedVariableOptions{
 		BasePath:         options.BasePath,
 		ConfigPath:       ConfigPath(l.configPath),
@@ -343,9 +351,84 @@ func (l *configLoader) newVariableResolver(generatedConfig *generated.Config, op
 		NamespaceFlag:    options.Namespace,
 		KubeConfigLoader: l.kubeConfigLoader,
 		Profile:          options.Profile,
+		LocalPorts:       localPorts,
 	}, log)
 }
 
+// namedLocalPorts returns the local ports of the named port mappings in dev.ports keyed by their variable name.
+// The port chosen before is only used if it still applies to the port mapping, which is the case for auto
+// ports and for port mappings with a fallback whose configured port is in use. Port mappings with a port
+// that is not a number or auto are returned with an empty port
+func namedLocalPorts(rawConfig map[interface{}]interface{}, cache map[string]string) (map[string]string, error) {
+	localPorts := map[string]string{}
+	dev, ok := rawConfig["dev"].(map[interface{}]interface{})
+	if ok == false {
+		return localPorts, nil
+	}
+	ports, ok := dev["ports"].([]interface{})
+	if ok == false {
+		return localPorts, nil
+	}
+
+	for _, portForwarding := range ports {
+		portForwardingMap, ok := portForwarding.(map[interface{}]interface{})
+		if ok == false {
+			continue
+		}
+		portMappings, ok := portForwardingMap["forward"].([]interface{})
+		if ok == false {
+			continue
+		}
+
+		for _, portMapping := range portMappings {
+			portMappingMap, ok := portMapping.(map[interface{}]interface{})
+			if ok == false {
+				continue
+			}
+			name, ok := portMappingMap["name"].(string)
+			if ok == false || name == "" {
+				continue
+			}
+
+			bindAddress, _ := portMappingMap["bindAddress"].(string)
+			if bindAddress == "" || bindAddress == "localhost" {
+				bindAddress = "127.0.0.1"
+			}
+
+			varName := variable.PortVariableName(name)
+			switch localPort := portMappingMap["port"].(type) {
+			case int:
+				localPorts[varName] = strconv.Itoa(localPort)
+
+				// the fallback port is only forwarded if the configured port is in use
+				fallback, _ := portMappingMap["portFallback"].(string)
+				if fallback != "" && cache[varName] != "" {
+					if free, _ := port.CheckHostPort(bindAddress, localPort); free == false {
+						localPorts[varName] = cache[varName]
+					}
+				}
+			case string:
+				if localPort != latest.LocalPortAuto {
+					localPorts[varName] = ""
+					continue
+				} else if cache[varName] != "" {
+					localPorts[varName] = cache[varName]
+					continue
+				}
+
+				freePort, err := port.FreeHostPort(bindAddress)
+				if err != nil {
+					return nil, errors.Errorf("error finding a free local port for port %s: %v", name, err)
+				}
+
+				localPorts[varName] = strconv.Itoa(freePort)
+			}
+		}
+	}
+
+	return localPorts, nil
+}
+
 // configExistsInPath checks whether a devspace configuration exists at a certain path
 func configExistsInPath(path string) bool {
 	// check devspace.yaml
@@ -1050,6 +1050,89 @@ profiles:
 				},
 			},
 		},
+		"Port variables": {
+			in: &parseTestCaseInput{
+				config: `
+version: v1beta10
+dev:
+  ports:
+  - labelSelector:
+      app: test
+    forward:
+    - port: 8080
+      name: api
+    - port: auto
+      remotePort: 3000
+      name: web
+  open:
+  - url: http://localhost:${DEVSPACE_PORT_API}/${DEVSPACE_PORT_WEB}`,
+				options: &ConfigOptions{},
+				generatedConfig: &generated.Config{Vars: map[string]string{
+					"DEVSPACE_PORT_API": "9090",
+					"DEVSPACE_PORT_WEB": "4000",
+				}},
+			},
+			expected: &latest.Config{
+				Version: latest.Version,
+				Dev: latest.DevConfig{
+					Ports: []*latest.PortForwardingConfig{
+						{
+							LabelSelector: map[string]string{"app": "test"},
+							PortMappings: []*latest.PortMapping{
+								{
+									LocalPort: ptr.Int(8080),
+									Name:      "api",
+								},
+								{
+									AutoLocalPort: true,
+									RemotePort:    ptr.Int(3000),
+									Name:          "web",
+								},
+							},
+						},
+					},
+					Open: []*latest.OpenConfig{
+						{
+							URL: "http://localhost:8080/4000",
+						},
+					},
+				},
+			},
+		},
+		"Port variable without port mapping": {
+			in: &parseTestCaseInput{
+				config: `
+version: v1beta10
+dev:
+  open:
+  - url: http://localhost:${DEVSPACE_PORT_API}`,
+				options:         &ConfigOptions{},
+				generatedConfig: &generated.Config{Vars: map[string]string{}},
+			},
+			expectedErr: true,
+		},
+		"Port variable of a port mapping with a variable port": {
+			in: &parseTestCaseInput{
+				config: `
+version: v1beta10
+vars:
+- name: PORT
+  source: none
+  default: "8080"
+dev:
+  ports:
+  - labelSelector:
+      app: test
+    forward:
+    - port: ${PORT}
+      name: api
+  open:
+  - url: http://localhost:${DEVSPACE_PORT_API}`,
+				options:         &ConfigOptions{},
+				generatedConfig: &generated.Config{Vars: map[string]string{}},
+			},
+			expectedErr: true,
+		},
 	}
 
 	// Execute test cases
@@ -9,7 +9,10 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -24,6 +27,85 @@ func ValidInitialSyncStrategy(strategy latest.InitialSyncStrategy) bool {
 		strategy == latest.InitialSyncStrategyPreferNewest
 }
 
+// ValidInitialSyncCompareBy checks if compare by is valid
+func ValidInitialSyncCompareBy(compareBy latest.InitialSyncCompareBy) bool {
+	return compareBy == "" ||
+		compareBy == latest.InitialSyncCompareByMTime ||
+		compareBy == latest.InitialSyncCompareBySize ||
+		compareBy == latest.InitialSyncCompareByChecksum
+}
+
+// ValidConflictStrategy checks if conflict strategy is valid
+func ValidConflictStrategy(strategy latest.ConflictStrategy) bool {
+	return strategy == "" ||
+		strategy == latest.ConflictStrategyPreferNewest ||
+		strategy == latest.ConflictStrategyPreferLocal ||
+		strategy == latest.ConflictStrategyPreferRemote ||
+		strategy == latest.ConflictStrategyKeepBoth
+}
+
+// ValidSymlinksMode checks if symlinks mode is valid
+func ValidSymlinksMode(mode latest.SymlinksMode) bool {
+	return mode == "" ||
+		mode == latest.SymlinksModeFollow ||
+		mode == latest.SymlinksModePreserve ||
+		mode == latest.SymlinksModeSkip
+}
+
+// ValidSyncTransportType checks if the sync transport type is valid
+func ValidSyncTransportType(transportType latest.SyncTransportType) bool {
+	return transportType == "" ||
+		transportType == latest.SyncTransportTypeExec ||
+		transportType == latest.SyncTransportTypePortForward ||
+		transportType == latest.SyncTransportTypeLocal
+}
+
+// ValidPortProtocol checks if the port protocol is valid
+func ValidPortProtocol(protocol latest.PortProtocol) bool {
+	return protocol == "" ||
+		protocol == latest.PortProtocolTCP ||
+		protocol == latest.PortProtocolUDP
+}
+
+// ValidPortName checks if the port name only contains letters, digits and underscores, so
+// that it can be used in the name of a variable
+func ValidPortName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidPortFallback checks if the port fallback of a port mapping is auto or a port range such as 8081-8090
+func ValidPortFallback(fallback string) bool {
+	if fallback == latest.PortFallbackAuto {
+		return true
+	}
+
+	splitted := strings.Split(fallback, "-")
+	if len(splitted) != 2 {
+		return false
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(splitted[0]))
+	if err != nil {
+		return false
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(splitted[1]))
+	if err != nil {
+		return false
+	}
+
+	return start > 0 && end <= 65535 && start <= end
+}
+
 // ValidContainerArch checks if the target container arch is valid
 func ValidContainerArch(arch latest.ContainerArchitecture) bool {
 	return arch == "" ||
@@ -31,6 +113,26 @@ func ValidContainerArch(arch latest.ContainerArchitecture) bool {
 		arch == latest.ContainerArchitectureArm64
 }
 
+// ValidFileMode checks if the file mode is an octal permission mode such as 0644
+func ValidFileMode(mode string) bool {
+	if mode == "" {
+		return true
+	}
+
+	parsed, err := strconv.ParseUint(mode, 8, 32)
+	return err == nil && parsed <= 0777
+}
+
+// ValidFileSize checks if the file size is a non negative quantity such as 100Mi
+func ValidFileSize(size string) bool {
+	if size == "" {
+		return true
+	}
+
+	quantity, err := resource.ParseQuantity(size)
+	return err == nil && quantity.Sign() >= 0
+}
+
 func validate(config *latest.Config, log log.Logger) error {
 	err := validateRequire(config)
 	if err != nil {
@@ -295,6 +397,108 @@ func validateImages(config *latest.Config) error {
 	return nil
 }
 
+func validatePersistPaths(index int, rp *latest.ReplacePod) error {
+	volumePaths := map[string]bool{}
+	for pathIndex, persistPath := range rp.PersistPaths {
+		if persistPath.Path == "" {
+			return errors.Errorf("Error in config: dev.replacePods[%d].persistPaths[%d].path is empty", index, pathIndex)
+		} else if path.IsAbs(persistPath.Path) == false || path.Clean(persistPath.Path) == "/" {
+			return errors.Errorf("Error in config: dev.replacePods[%d].persistPaths[%d].path '%s' has to be an absolute path other than /", index, pathIndex, persistPath.Path)
+		}
+
+		volumePath := strings.TrimPrefix(path.Clean(persistPath.Path), "/")
+		if persistPath.VolumePath != "" {
+			volumePath = path.Clean(persistPath.VolumePath)
+		}
+		if path.IsAbs(persistPath.VolumePath) || volumePath == "." || volumePath == ".." || strings.HasPrefix(volumePath, "../") {
+			return errors.Errorf("Error in config: dev.replacePods[%d].persistPaths[%d].volumePath '%s' has to be a relative path within the volume", index, pathIndex, persistPath.VolumePath)
+		} else if volumePaths[volumePath] {
+			return errors.Errorf("Error in config: dev.replacePods[%d].persistPaths[%d].volumePath '%s' is used twice", index, pathIndex, volumePath)
+		}
+
+		volumePaths[volumePath] = true
+	}
+
+	if rp.PersistenceOptions != nil {
+		if len(rp.PersistPaths) == 0 {
+			return errors.Errorf("Error in config: dev.replacePods[%d].persistenceOptions requires persistPaths", index)
+		}
+		if rp.PersistenceOptions.Size != "" {
+			_, err := resource.ParseQuantity(rp.PersistenceOptions.Size)
+			if err != nil {
+				return errors.Errorf("Error in config: dev.replacePods[%d].persistenceOptions.size '%s' is not a valid quantity: %v", index, rp.PersistenceOptions.Size, err)
+			}
+		}
+		for _, accessMode := range rp.PersistenceOptions.AccessModes {
+			if accessMode != string(k8sv1.ReadWriteOnce) && accessMode != string(k8sv1.ReadOnlyMany) && accessMode != string(k8sv1.ReadWriteMany) {
+				return errors.Errorf("Error in config: dev.replacePods[%d].persistenceOptions.accessModes '%s' is not valid", index, accessMode)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateReplaceContainers(index int, rp *latest.ReplacePod) error {
+	containerNames := map[string]bool{}
+	imageNames := map[string]bool{}
+	imageSelectors := map[string]bool{}
+	if rp.ReplaceImage != "" && len(rp.LabelSelector) == 0 {
+		if rp.ImageName != "" {
+			imageNames[rp.ImageName] = true
+		}
+		if rp.ImageSelector != "" {
+			imageSelectors[rp.ImageSelector] = true
+		}
+	}
+
+	for containerIndex, container := range rp.Containers {
+		if container == nil {
+			return errors.Errorf("Error in config: dev.replacePods[%d].containers[%d] is empty", index, containerIndex)
+		}
+
+		definedSelectors := 0
+		if container.ContainerName != "" {
+			definedSelectors++
+		}
+		if container.ImageName != "" {
+			definedSelectors++
+		}
+		if container.ImageSelector != "" {
+			definedSelectors++
+		}
+		if definedSelectors != 1 {
+			return errors.Errorf("Error in config: dev.replacePods[%d].containers[%d] needs exactly one of containerName, imageName or imageSelector", index, containerIndex)
+		}
+
+		if container.ContainerName != "" {
+			if containerNames[container.ContainerName] {
+				return errors.Errorf("Error in config: dev.replacePods[%d].containers[%d].containerName '%s' is used twice", index, containerIndex, container.ContainerName)
+			} else if container.ContainerName == rp.ContainerName && rp.ReplaceImage != "" {
+				return errors.Errorf("Error in config: dev.replacePods[%d].containers[%d].containerName '%s' is already replaced by dev.replacePods[%d].replaceImage", index, containerIndex, container.ContainerName, index)
+			}
+
+			containerNames[container.ContainerName] = true
+		}
+		if container.ImageName != "" {
+			if imageNames[container.ImageName] {
+				return errors.Errorf("Error in config: dev.replacePods[%d].containers[%d].imageName '%s' is already selected by another container or dev.replacePods[%d].imageName", index, containerIndex, container.ImageName, index)
+			}
+
+			imageNames[container.ImageName] = true
+		}
+		if container.ImageSelector != "" {
+			if imageSelectors[container.ImageSelector] {
+				return errors.Errorf("Error in config: dev.replacePods[%d].containers[%d].imageSelector '%s' is already selected by another container or dev.replacePods[%d].imageSelector", index, containerIndex, container.ImageSelector, index)
+			}
+
+			imageSelectors[container.ImageSelector] = true
+		}
+	}
+
+	return nil
+}
+
 func isReplacePodsUnique(index int, rp *latest.ReplacePod, rps []*latest.ReplacePod) bool {
 	for i, r := range rps {
 		if i == index {
@@ -348,12 +552,27 @@ func validateDev(config *latest.Config) error {
 		if isReplacePodsUnique(index, rp, config.Dev.ReplacePods) == false {
 			return errors.Errorf("Error in config: image selector or label selector is not unique in replace pods at index %d", index)
 		}
+		err := validatePersistPaths(index, rp)
+		if err != nil {
+			return err
+		}
+		err = validateReplaceContainers(index, rp)
+		if err != nil {
+			return err
+		}
 	}
 
 	if config.Dev.Ports != nil {
+		portNames := map[string]bool{}
 		for index, port := range config.Dev.Ports {
 			// Validate imageName and label selector
-			if port.ImageName == "" && len(port.LabelSelector) == 0 && port.ImageSelector == "" {
+			if port.ServiceName != "" {
+				if port.ImageName != "" || len(port.LabelSelector) > 0 || port.ImageSelector != "" {
+					return errors.Errorf("Error in config: dev.ports[%d].serviceName cannot be combined with an image selector or label selector", index)
+				} else if len(port.PortMappingsReverse) > 0 {
+					return errors.Errorf("Error in config: dev.ports[%d].serviceName cannot be used with reverseForward", index)
+				}
+			} else if port.ImageName == "" && len(port.LabelSelector) == 0 && port.ImageSelector == "" {
 				return errors.Errorf("Error in config: image selector and label selector are nil in ports config at index %d", index)
 			} else if port.ImageName != "" && findImageName(config, port.ImageName) == false {
 				return errors.Errorf("Error in config: dev.ports[%d].imageName '%s' couldn't be found. Please make sure the image name exists under 'images'", index, port.ImageName)
@@ -362,12 +581,72 @@ func validateDev(config *latest.Config) error {
 			if len(port.PortMappings) == 0 && len(port.PortMappingsReverse) == 0 {
 				return errors.Errorf("Error in config: portMappings is empty in port config at index %d", index)
 			}
+			for mappingIndex, portMapping := range port.PortMappings {
+				if ValidPortProtocol(portMapping.Protocol) == false {
+					return errors.Errorf("Error in config: dev.ports[%d].forward[%d].protocol is not valid '%s'", index, mappingIndex, portMapping.Protocol)
+				}
+				if port.ServiceName != "" && portMapping.Protocol == latest.PortProtocolUDP {
+					return errors.Errorf("Error in config: dev.ports[%d].forward[%d].protocol udp is not supported with serviceName", index, mappingIndex)
+				}
+				if portMapping.Name != "" {
+					if ValidPortName(portMapping.Name) == false {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].name '%s' may only contain letters, digits and underscores", index, mappingIndex, portMapping.Name)
+					} else if portNames[strings.ToUpper(portMapping.Name)] {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].name '%s' is not unique", index, mappingIndex, portMapping.Name)
+					}
+
+					portNames[strings.ToUpper(portMapping.Name)] = true
+				}
+				if portMapping.Inspect && portMapping.Protocol == latest.PortProtocolUDP {
+					return errors.Errorf("Error in config: dev.ports[%d].forward[%d].inspect is not supported for udp ports", index, mappingIndex)
+				}
+				if portMapping.AutoLocalPort {
+					if portMapping.Name == "" {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].port auto requires a name to store the chosen port under", index, mappingIndex)
+					} else if portMapping.Protocol == latest.PortProtocolUDP {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].port auto is not supported for udp ports", index, mappingIndex)
+					} else if portMapping.RemotePort == nil {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].remotePort is required if port is auto", index, mappingIndex)
+					} else if portMapping.PortFallback != "" {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].portFallback cannot be used if port is auto", index, mappingIndex)
+					}
+				}
+				if portMapping.PortFallback != "" {
+					if portMapping.Name == "" {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].portFallback requires a name to store the chosen port under", index, mappingIndex)
+					} else if portMapping.Protocol == latest.PortProtocolUDP {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].portFallback is not supported for udp ports", index, mappingIndex)
+					}
+					if ValidPortFallback(portMapping.PortFallback) == false {
+						return errors.Errorf("Error in config: dev.ports[%d].forward[%d].portFallback '%s' is neither %s nor a port range such as 8081-8090", index, mappingIndex, portMapping.PortFallback, latest.PortFallbackAuto)
+					}
+				}
+			}
+			for mappingIndex, portMapping := range port.PortMappingsReverse {
+				if portMapping.Protocol != "" && portMapping.Protocol != latest.PortProtocolTCP {
+					return errors.Errorf("Error in config: dev.ports[%d].reverseForward[%d].protocol '%s' is not supported, only tcp ports can be reverse forwarded", index, mappingIndex, portMapping.Protocol)
+				}
+			}
 			if ValidContainerArch(port.Arch) == false {
 				return errors.Errorf("Error in config: ports.arch is not valid '%s' at index %d", port.Arch, index)
 			}
 		}
 	}
 
+	if config.Dev.Proxy != nil {
+		if config.Dev.Proxy.ImageName == "" && len(config.Dev.Proxy.LabelSelector) == 0 && config.Dev.Proxy.ImageSelector == "" {
+			return errors.Errorf("Error in config: image selector and label selector are nil in dev.proxy")
+		} else if config.Dev.Proxy.ImageName != "" && findImageName(config, config.Dev.Proxy.ImageName) == false {
+			return errors.Errorf("Error in config: dev.proxy.imageName '%s' couldn't be found. Please make sure the image name exists under 'images'", config.Dev.Proxy.ImageName)
+		}
+		if config.Dev.Proxy.Port != nil && (*config.Dev.Proxy.Port <= 0 || *config.Dev.Proxy.Port > 65535) {
+			return errors.Errorf("Error in config: dev.proxy.port %d is not a valid port", *config.Dev.Proxy.Port)
+		}
+		if ValidContainerArch(config.Dev.Proxy.Arch) == false {
+			return errors.Errorf("Error in config: dev.proxy.arch is not valid '%s'", config.Dev.Proxy.Arch)
+		}
+	}
+
 	if config.Dev.Sync != nil {
 		for index, sync := range config.Dev.Sync {
 			// Validate imageName and label selector
@@ -381,9 +660,77 @@ func validateDev(config *latest.Config) error {
 			if ValidInitialSyncStrategy(sync.InitialSync) == false {
 				return errors.Errorf("Error in config: sync.initialSync is not valid '%s' at index %d", sync.InitialSync, index)
 			}
+			if ValidInitialSyncCompareBy(sync.InitialSyncCompareBy) == false {
+				return errors.Errorf("Error in config: sync.initialSyncCompareBy is not valid '%s' at index %d", sync.InitialSyncCompareBy, index)
+			}
+			if ValidConflictStrategy(sync.ConflictStrategy) == false {
+				return errors.Errorf("Error in config: sync.conflictStrategy is not valid '%s' at index %d", sync.ConflictStrategy, index)
+			}
+			if ValidSymlinksMode(sync.Symlinks) == false {
+				return errors.Errorf("Error in config: sync.symlinks is not valid '%s' at index %d", sync.Symlinks, index)
+			}
 			if ValidContainerArch(sync.Arch) == false {
 				return errors.Errorf("Error in config: sync.arch is not valid '%s' at index %d", sync.Arch, index)
 			}
+			if sync.Permissions != nil {
+				if ValidFileMode(sync.Permissions.FileMode) == false {
+					return errors.Errorf("Error in config: sync.permissions.fileMode is not a valid octal mode '%s' at index %d", sync.Permissions.FileMode, index)
+				}
+				if ValidFileMode(sync.Permissions.DirMode) == false {
+					return errors.Errorf("Error in config: sync.permissions.dirMode is not a valid octal mode '%s' at index %d", sync.Permissions.DirMode, index)
+				}
+			}
+			if ValidFileSize(sync.MaxFileSize) == false {
+				return errors.Errorf("Error in config: sync.maxFileSize is not a valid size '%s' at index %d", sync.MaxFileSize, index)
+			}
+			for ruleIndex, rule := range sync.SizeRules {
+				if rule == nil || rule.Path == "" {
+					return errors.Errorf("Error in config: sync.sizeRules[%d].path is required at index %d", ruleIndex, index)
+				}
+				if rule.MaxSize == "" || ValidFileSize(rule.MaxSize) == false {
+					return errors.Errorf("Error in config: sync.sizeRules[%d].maxSize is not a valid size '%s' at index %d", ruleIndex, rule.MaxSize, index)
+				}
+			}
+			if sync.ParallelUpload != nil {
+				if sync.ParallelUpload.Connections != nil && *sync.ParallelUpload.Connections <= 0 {
+					return errors.Errorf("Error in config: sync.parallelUpload.connections has to be greater than zero at index %d", index)
+				}
+				if sync.ParallelUpload.BatchFiles != nil && *sync.ParallelUpload.BatchFiles <= 0 {
+					return errors.Errorf("Error in config: sync.parallelUpload.batchFiles has to be greater than zero at index %d", index)
+				}
+				if ValidFileSize(sync.ParallelUpload.BatchSize) == false {
+					return errors.Errorf("Error in config: sync.parallelUpload.batchSize is not a valid size '%s' at index %d", sync.ParallelUpload.BatchSize, index)
+				}
+			}
+			if sync.OnUpload != nil {
+				for ruleIndex, rule := range sync.OnUpload.Rules {
+					if rule == nil || rule.Path == "" {
+						return errors.Errorf("Error in config: sync.onUpload.rules[%d].path is required at index %d", ruleIndex, index)
+					}
+					if rule.Command == "" && rule.RestartContainer == false {
+						return errors.Errorf("Error in config: sync.onUpload.rules[%d] needs a command or restartContainer at index %d", ruleIndex, index)
+					}
+					if rule.Debounce != nil && *rule.Debounce < 0 {
+						return errors.Errorf("Error in config: sync.onUpload.rules[%d].debounce cannot be negative at index %d", ruleIndex, index)
+					}
+				}
+			}
+			if sync.Transport != nil {
+				if ValidSyncTransportType(sync.Transport.Type) == false {
+					return errors.Errorf("Error in config: sync.transport.type is not valid '%s' at index %d", sync.Transport.Type, index)
+				}
+				if sync.Transport.Port != nil && (*sync.Transport.Port <= 0 || *sync.Transport.Port > 65535) {
+					return errors.Errorf("Error in config: sync.transport.port is not a valid port '%d' at index %d", *sync.Transport.Port, index)
+				}
+				if sync.Transport.Type == latest.SyncTransportTypeLocal {
+					if sync.Transport.LocalPath == "" {
+						return errors.Errorf("Error in config: sync.transport.localPath is required for the local transport at index %d", index)
+					}
+					if sync.SyncAllReplicas {
+						return errors.Errorf("Error in config: sync.syncAllReplicas cannot be used with the local transport at index %d", index)
+					}
+				}
+			}
 		}
 	}
 
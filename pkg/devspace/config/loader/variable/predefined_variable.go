@@ -30,6 +30,18 @@ type PredefinedVariableOptions struct {
 	KubeConfigLoader kubeconfig.Loader
 
 	Profile string
+
+	// LocalPorts are the local ports of named port mappings keyed by their variable name. The port is
+	// empty if the port mapping uses a port that is not a number or auto
+	LocalPorts map[string]string
+}
+
+// PortVariablePrefix is the prefix of the variables that hold the forwarded local ports of named port mappings
+const PortVariablePrefix = "DEVSPACE_PORT_"
+
+// PortVariableName returns the name of the variable that holds the forwarded local port of the port mapping
+func PortVariableName(name string) string {
+	return PortVariablePrefix + strings.ToUpper(name)
 }
 
 // PredefinedVariableFunction is the definition of a predefined variable
@@ -149,6 +161,23 @@ func NewPredefinedVariable(name string, cache map[string]string, options *Predef
 			return NewCachedValueVariable(name), nil
 		}
 
+		// Load the local port of a named port mapping
+		if strings.HasPrefix(name, PortVariablePrefix) {
+			portName := strings.ToLower(strings.TrimPrefix(name, PortVariablePrefix))
+			if options == nil {
+				return nil, fmt.Errorf("predefined variable %s not found, there is no port mapping with name %s in dev.ports", name, portName)
+			}
+
+			localPort, ok := options.LocalPorts[name]
+			if ok == false {
+				return nil, fmt.Errorf("predefined variable %s not found, there is no port mapping with name %s in dev.ports", name, portName)
+			} else if localPort == "" {
+				return nil, fmt.Errorf("predefined variable %s cannot be used, because the port of the port mapping with name %s in dev.ports is neither a number nor %s", name, portName, latest.LocalPortAuto)
+			}
+
+			return NewCachedValueVariable(localPort), nil
+		}
+
 		return nil, errors.New("predefined variable " + name + " not found")
 	}
 
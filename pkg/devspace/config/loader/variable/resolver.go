@@ -281,6 +281,9 @@ func (r *resolver) fillVariable(name string, definition *latest.Variable) (inter
 	variable, err := NewPredefinedVariable(name, r.persistentCache, r.options)
 	if err == nil {
 		return variable.Load(definition)
+	} else if strings.HasPrefix(strings.ToUpper(name), PortVariablePrefix) {
+		// the port variables are reserved for the port mappings in dev.ports
+		return nil, err
 	}
 
 	// fill variable without definition
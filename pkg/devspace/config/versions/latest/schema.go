@@ -2,6 +2,7 @@ package latest
 
 import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/config"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // Version is the current api version
@@ -678,6 +679,10 @@ type DevConfig struct {
 	AutoReload *AutoReloadConfig       `yaml:"autoReload,omitempty" json:"autoReload,omitempty"`
 	Terminal   *Terminal               `yaml:"terminal,omitempty" json:"terminal,omitempty"`
 
+	// Proxy starts a local SOCKS5 and HTTP CONNECT proxy that opens connections from the selected container,
+	// which makes every host and port of the cluster network reachable through a single local port
+	Proxy *ProxyConfig `yaml:"proxy,omitempty" json:"proxy,omitempty"`
+
 	// Replace pods will replace the selected target pod/container with a new image and optionally apply
 	// pod patches.
 	ReplacePods []*ReplacePod `yaml:"replacePods,omitempty" json:"replacePods,omitempty"`
@@ -699,6 +704,54 @@ type ReplacePod struct {
 
 	ReplaceImage string         `yaml:"replaceImage,omitempty" json:"replaceImage,omitempty"`
 	Patches      []*PatchConfig `yaml:"patches,omitempty" json:"patches,omitempty"`
+
+	// Containers modify further containers of the replaced pod, each with its own image and patches
+	Containers []*ReplaceContainer `yaml:"containers,omitempty" json:"containers,omitempty"`
+
+	// PersistPaths are mounted from a persistent volume claim into the replaced container, so that their
+	// content survives a recreation of the replaced pod. They are not mounted into the other Containers
+	PersistPaths       []PersistentPath    `yaml:"persistPaths,omitempty" json:"persistPaths,omitempty"`
+	PersistenceOptions *PersistenceOptions `yaml:"persistenceOptions,omitempty" json:"persistenceOptions,omitempty"`
+}
+
+// ReplaceContainer describes how a single container of the replaced pod is modified
+type ReplaceContainer struct {
+	// ContainerName, ImageName or ImageSelector select the container in the replaced pod
+	ContainerName string `yaml:"containerName,omitempty" json:"containerName,omitempty"`
+	ImageName     string `yaml:"imageName,omitempty" json:"imageName,omitempty"`
+	ImageSelector string `yaml:"imageSelector,omitempty" json:"imageSelector,omitempty"`
+
+	ReplaceImage string `yaml:"replaceImage,omitempty" json:"replaceImage,omitempty"`
+
+	// Entrypoint and Cmd override the command and args of the container
+	Entrypoint []string `yaml:"entrypoint,omitempty" json:"entrypoint,omitempty"`
+	Cmd        []string `yaml:"cmd,omitempty" json:"cmd,omitempty"`
+
+	// Patches are applied on the container, e.g. with the path env
+	Patches []*PatchConfig `yaml:"patches,omitempty" json:"patches,omitempty"`
+}
+
+// PersistentPath is a path of the replaced container that is persisted
+type PersistentPath struct {
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// VolumePath is the sub path in the persistent volume claim. Defaults to the path
+	VolumePath string `yaml:"volumePath,omitempty" json:"volumePath,omitempty"`
+}
+
+// PersistenceOptions configure the persistent volume claim of the persisted paths
+type PersistenceOptions struct {
+	// Size of the persistent volume claim. Defaults to 10Gi
+	Size             string   `yaml:"size,omitempty" json:"size,omitempty"`
+	StorageClassName string   `yaml:"storageClassName,omitempty" json:"storageClassName,omitempty"`
+	AccessModes      []string `yaml:"accessModes,omitempty" json:"accessModes,omitempty"`
+
+	// Name of the persistent volume claim. Defaults to the name of the replaced pod's parent with a -devspace suffix
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// HelperImage provides the devspace helper at /devspacehelper that populates the persistent volume claim.
+	// Defaults to devspacesh/devspacehelper with the version of DevSpace as tag
+	HelperImage string `yaml:"helperImage,omitempty" json:"helperImage,omitempty"`
 }
 
 // PortForwardingConfig defines the ports for a port forwarding to a DevSpace
@@ -709,6 +762,9 @@ type PortForwardingConfig struct {
 	ContainerName string            `yaml:"containerName,omitempty" json:"containerName,omitempty"`
 	Namespace     string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`
 
+	// ServiceName forwards the ports to the ready endpoints of the service instead of a single pod
+	ServiceName string `yaml:"serviceName,omitempty" json:"serviceName,omitempty"`
+
 	// Target Container architecture to use for the devspacehelper (currently amd64 or arm64). Defaults to amd64
 	Arch ContainerArchitecture `yaml:"arch,omitempty" json:"arch,omitempty"`
 
@@ -716,13 +772,117 @@ type PortForwardingConfig struct {
 	PortMappingsReverse []*PortMapping `yaml:"reverseForward,omitempty" json:"reverseForward,omitempty"`
 }
 
+// ProxyConfig defines the container the proxy connects from and the local port it listens on
+type ProxyConfig struct {
+	ImageSelector string            `yaml:"imageSelector,omitempty" json:"imageSelector,omitempty"`
+	ImageName     string            `yaml:"imageName,omitempty" json:"imageName,omitempty"`
+	LabelSelector map[string]string `yaml:"labelSelector,omitempty" json:"labelSelector,omitempty"`
+	ContainerName string            `yaml:"containerName,omitempty" json:"containerName,omitempty"`
+	Namespace     string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Target Container architecture to use for the devspacehelper (currently amd64 or arm64). Defaults to amd64
+	Arch ContainerArchitecture `yaml:"arch,omitempty" json:"arch,omitempty"`
+
+	// Port is the local port of the proxy. Defaults to 1080
+	Port        *int   `yaml:"port,omitempty" json:"port,omitempty"`
+	BindAddress string `yaml:"bindAddress,omitempty" json:"bindAddress,omitempty"`
+}
+
 // PortMapping defines the ports for a PortMapping
 type PortMapping struct {
 	LocalPort   *int   `yaml:"port" json:"port"`
 	RemotePort  *int   `yaml:"remotePort,omitempty" json:"remotePort,omitempty"`
 	BindAddress string `yaml:"bindAddress,omitempty" json:"bindAddress,omitempty"`
+
+	// AutoLocalPort is set if the port is auto, in which case the local port is nil until a free one was chosen
+	AutoLocalPort bool `yaml:"-" json:"-"`
+
+	// Name of the port mapping, the forwarded local port is available as DEVSPACE_PORT_<NAME> variable
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// PortFallback is either auto or a port range such as 8081-8090 and is used if the local port is already in use
+	PortFallback string `yaml:"portFallback,omitempty" json:"portFallback,omitempty"`
+
+	// Inspect captures the http requests and responses of the port, which are shown in the ui
+	Inspect bool `yaml:"inspect,omitempty" json:"inspect,omitempty"`
+
+	// Protocol of the forwarded port. Udp ports are forwarded via the devspace helper. Defaults to tcp
+	Protocol PortProtocol `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+}
+
+// PortFallbackAuto lets the operating system choose a free local port if the local port is already in use
+const PortFallbackAuto = "auto"
+
+// LocalPortAuto as port lets the operating system choose a free local port
+const LocalPortAuto = "auto"
+
+// UnmarshalYAML implements yaml.Unmarshaler, because the port is either a number or auto
+func (p *PortMapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type portMapping PortMapping
+	mapping := portMapping{}
+	err := unmarshal(&mapping)
+	if err == nil {
+		*p = PortMapping(mapping)
+		return nil
+	}
+
+	// replace auto by an empty port and unmarshal again
+	raw := map[string]interface{}{}
+	if unmarshal(&raw) != nil || raw["port"] != LocalPortAuto {
+		return err
+	}
+	delete(raw, "port")
+
+	out, err := yaml.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	mapping = portMapping{}
+	err = yaml.UnmarshalStrict(out, &mapping)
+	if err != nil {
+		return err
+	}
+
+	*p = PortMapping(mapping)
+	p.AutoLocalPort = true
+	return nil
 }
 
+// MarshalYAML implements yaml.Marshaler, so that an auto port is written as auto
+func (p PortMapping) MarshalYAML() (interface{}, error) {
+	type portMapping PortMapping
+	if p.AutoLocalPort == false || p.LocalPort != nil {
+		return portMapping(p), nil
+	}
+
+	out, err := yaml.Marshal(portMapping(p))
+	if err != nil {
+		return nil, err
+	}
+
+	raw := yaml.MapSlice{}
+	err = yaml.Unmarshal(out, &raw)
+	if err != nil {
+		return nil, err
+	}
+	for index := range raw {
+		if raw[index].Key == "port" {
+			raw[index].Value = LocalPortAuto
+		}
+	}
+
+	return raw, nil
+}
+
+// PortProtocol is the protocol of a forwarded port
+type PortProtocol string
+
+// List of port protocols
+const (
+	PortProtocolTCP PortProtocol = "tcp"
+	PortProtocolUDP PortProtocol = "udp"
+)
+
 // OpenConfig defines what to open after services have been started
 type OpenConfig struct {
 	URL string `yaml:"url,omitempty" json:"url,omitempty"`
@@ -742,15 +902,33 @@ type SyncConfig struct {
 	UploadExcludePaths   []string             `yaml:"uploadExcludePaths,omitempty" json:"uploadExcludePaths,omitempty"`
 	InitialSync          InitialSyncStrategy  `yaml:"initialSync,omitempty" json:"initialSync,omitempty"`
 	InitialSyncCompareBy InitialSyncCompareBy `yaml:"initialSyncCompareBy,omitempty" json:"initialSyncCompareBy,omitempty"`
+	ConflictStrategy     ConflictStrategy     `yaml:"conflictStrategy,omitempty" json:"conflictStrategy,omitempty"`
+	Symlinks             SymlinksMode         `yaml:"symlinks,omitempty" json:"symlinks,omitempty"`
 
 	DisableDownload *bool `yaml:"disableDownload,omitempty" json:"disableDownload,omitempty"`
 	DisableUpload   *bool `yaml:"disableUpload,omitempty" json:"disableUpload,omitempty"`
 
 	Polling bool `yaml:"polling,omitempty" json:"polling,omitempty"`
 
+	// If true, files are uploaded to all containers that match the selector and not only to the selected one
+	SyncAllReplicas bool `yaml:"syncAllReplicas,omitempty" json:"syncAllReplicas,omitempty"`
+
 	WaitInitialSync *bool            `yaml:"waitInitialSync,omitempty" json:"waitInitialSync,omitempty"`
 	BandwidthLimits *BandwidthLimits `yaml:"bandwidthLimits,omitempty" json:"bandwidthLimits,omitempty"`
 
+	// ParallelUpload splits large uploads such as the initial sync into batches that are uploaded over several connections
+	ParallelUpload *SyncParallelUpload `yaml:"parallelUpload,omitempty" json:"parallelUpload,omitempty"`
+
+	// Owner and permissions of the files and folders the sync creates in the container
+	Permissions *SyncPermissions `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+
+	// Files larger than MaxFileSize (e.g. 100Mi) are not synced, SizeRules override it for matching paths
+	MaxFileSize string          `yaml:"maxFileSize,omitempty" json:"maxFileSize,omitempty"`
+	SizeRules   []*SyncSizeRule `yaml:"sizeRules,omitempty" json:"sizeRules,omitempty"`
+
+	// Transport defines how the sync connects to the devspace helper. Defaults to kubectl exec
+	Transport *SyncTransport `yaml:"transport,omitempty" json:"transport,omitempty"`
+
 	// Target Container architecture to use for the devspacehelper (currently amd64 or arm64). Defaults to amd64
 	Arch ContainerArchitecture `yaml:"arch,omitempty" json:"arch,omitempty"`
 
@@ -778,6 +956,25 @@ type SyncOnUpload struct {
 	// Defines what commands should be executed on the container side if a change is uploaded and applied in the target
 	// container
 	ExecRemote *SyncExecCommand `yaml:"execRemote,omitempty" json:"execRemote,omitempty"`
+
+	// Rules execute a command or restart the container only if a batch of uploaded or removed paths
+	// contains a path that matches the rule
+	Rules []*SyncOnUploadRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// SyncOnUploadRule defines what should happen in the container after a batch of changes that contains a matching path
+type SyncOnUploadRule struct {
+	// Path is a pattern in .gitignore syntax, such as *.go or /src/styles/
+	Path string `yaml:"path" json:"path"`
+
+	Command string   `yaml:"command,omitempty" json:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
+
+	// If true, the container is restarted after the command, which requires the restart helper like onUpload.restartContainer
+	RestartContainer bool `yaml:"restartContainer,omitempty" json:"restartContainer,omitempty"`
+
+	// Debounce is the amount of milliseconds to wait for further matching changes before the rule is executed
+	Debounce *int64 `yaml:"debounce,omitempty" json:"debounce,omitempty"`
 }
 
 // SyncOnDownload defines the struct for the command that should be executed when files / folders are downloaded
@@ -828,8 +1025,30 @@ type InitialSyncCompareBy string
 
 // List of values that compare by can take
 const (
-	InitialSyncCompareByMTime InitialSyncCompareBy = "mtime"
-	InitialSyncCompareBySize  InitialSyncCompareBy = "size"
+	InitialSyncCompareByMTime    InitialSyncCompareBy = "mtime"
+	InitialSyncCompareBySize     InitialSyncCompareBy = "size"
+	InitialSyncCompareByChecksum InitialSyncCompareBy = "checksum"
+)
+
+// ConflictStrategy is the type of how a file should be resolved that was changed locally and remotely since it was last synced
+type ConflictStrategy string
+
+// List of values that conflict strategy can take
+const (
+	ConflictStrategyPreferNewest ConflictStrategy = "preferNewest"
+	ConflictStrategyPreferLocal  ConflictStrategy = "preferLocal"
+	ConflictStrategyPreferRemote ConflictStrategy = "preferRemote"
+	ConflictStrategyKeepBoth     ConflictStrategy = "keepBoth"
+)
+
+// SymlinksMode is the type of how symlinks are synced
+type SymlinksMode string
+
+// List of values that symlinks mode can take
+const (
+	SymlinksModeFollow   SymlinksMode = "follow"
+	SymlinksModePreserve SymlinksMode = "preserve"
+	SymlinksModeSkip     SymlinksMode = "skip"
 )
 
 // BandwidthLimits defines the struct for specifying the sync bandwidth limits
@@ -838,6 +1057,50 @@ type BandwidthLimits struct {
 	Upload   *int64 `yaml:"upload,omitempty" json:"upload,omitempty"`
 }
 
+// SyncPermissions defines the owner and modes of uploaded files and created folders in the container
+type SyncPermissions struct {
+	Owner              string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Group              string `yaml:"group,omitempty" json:"group,omitempty"`
+	FileMode           string `yaml:"fileMode,omitempty" json:"fileMode,omitempty"`
+	DirMode            string `yaml:"dirMode,omitempty" json:"dirMode,omitempty"`
+	PreserveExecutable *bool  `yaml:"preserveExecutable,omitempty" json:"preserveExecutable,omitempty"`
+}
+
+// SyncParallelUpload defines how large uploads are split into batches and how many are uploaded at once
+type SyncParallelUpload struct {
+	Connections *int   `yaml:"connections,omitempty" json:"connections,omitempty"`
+	BatchFiles  *int   `yaml:"batchFiles,omitempty" json:"batchFiles,omitempty"`
+	BatchSize   string `yaml:"batchSize,omitempty" json:"batchSize,omitempty"`
+}
+
+// SyncSizeRule limits the size of synced files that match the path, where the last matching rule wins
+// and a max size of 0 means that matching files are synced regardless of their size
+type SyncSizeRule struct {
+	Path    string `yaml:"path" json:"path"`
+	MaxSize string `yaml:"maxSize" json:"maxSize"`
+}
+
+// SyncTransport defines how the sync connects to the devspace helper
+type SyncTransport struct {
+	Type SyncTransportType `yaml:"type,omitempty" json:"type,omitempty"`
+
+	// Port is the port the long-running helper listens on in the container for the portForward transport
+	Port *int `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// LocalPath is the directory the local transport syncs with instead of a container path
+	LocalPath string `yaml:"localPath,omitempty" json:"localPath,omitempty"`
+}
+
+// SyncTransportType is the type of the sync transport
+type SyncTransportType string
+
+// List of values that the sync transport type can take
+const (
+	SyncTransportTypeExec        SyncTransportType = "exec"
+	SyncTransportTypePortForward SyncTransportType = "portForward"
+	SyncTransportTypeLocal       SyncTransportType = "local"
+)
+
 // LogsConfig specifies the logs options for devspace dev
 type LogsConfig struct {
 	Disabled  *bool          `yaml:"disabled,omitempty" json:"disabled,omitempty"`
@@ -188,7 +188,11 @@ func NewOnAddresses(dialer httpstream.Dialer, addresses []string, ports []string
 
 func (pf *PortForwarder) raiseError(err error) {
 	if pf.errChan != nil {
-		pf.errChan <- err
+		// the error is dropped instead of blocking the connection if nobody receives the errors anymore
+		select {
+		case pf.errChan <- err:
+		default:
+		}
 	}
 }
 
@@ -207,6 +211,38 @@ func (pf *PortForwarder) ForwardPorts() error {
 	return pf.forward()
 }
 
+// Connect dials the pod and keeps the connection open until stopChan is closed or the connection to
+// the pod is lost. In contrast to ForwardPorts no listeners are created, the caller accepts the local
+// connections itself and passes them to HandleConnection. This allows the caller to keep its listeners
+// bound while the connection to the pod is renewed
+func (pf *PortForwarder) Connect() error {
+	var err error
+	pf.streamConn, _, err = pf.dialer.Dial(PortForwardProtocolV1Name)
+	if err != nil {
+		return fmt.Errorf("error upgrading connection: %s", err)
+	}
+	defer pf.streamConn.Close()
+
+	if pf.Ready != nil {
+		close(pf.Ready)
+	}
+
+	// wait for interrupt or conn closure
+	select {
+	case <-pf.stopChan:
+	case <-pf.streamConn.CloseChan():
+		return errors.New("lost connection to pod")
+	}
+
+	return nil
+}
+
+// HandleConnection forwards the local connection to the remote port of the pod. The port forwarder
+// has to be connected via Connect before
+func (pf *PortForwarder) HandleConnection(conn net.Conn, port ForwardedPort) {
+	pf.handleConnection(conn, port)
+}
+
 // forward dials the remote host specific in req, upgrades the request, starts
 // listeners for each port specified in ports, and forwards local connections
 // to the remote host via streams.
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/loft-sh/devspace/pkg/devspace/services/inspect"
+)
+
+// redactedHeaders are the headers whose values are not returned by the /api/inspect request
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// ReplayResult is the struct that is returned by the /api/inspect/replay request
+type ReplayResult struct {
+	StatusCode int    `json:"statusCode"`
+	Status     string `json:"status"`
+}
+
+// inspect returns the captured http exchanges of the inspected ports, optionally only the ones of a local port
+func (h *handler) inspect(w http.ResponseWriter, r *http.Request) {
+	exchanges := inspect.Default.Exchanges()
+
+	port, ok := r.URL.Query()["port"]
+	if ok && len(port) == 1 && port[0] != "" {
+		localPort, err := strconv.Atoi(port[0])
+		if err != nil {
+			http.Error(w, "port is not a number", http.StatusBadRequest)
+			return
+		}
+
+		filtered := []*inspect.Exchange{}
+		for _, exchange := range exchanges {
+			if exchange.LocalPort() == localPort {
+				filtered = append(filtered, exchange)
+			}
+		}
+
+		exchanges = filtered
+	}
+
+	redacted := make([]*inspect.Exchange, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		redacted = append(redacted, redactExchange(exchange))
+	}
+
+	b, err := json.Marshal(redacted)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// redactExchange returns a copy of the exchange without the values of the redacted headers. The captured
+// exchange is not changed, so that it can still be replayed
+func redactExchange(exchange *inspect.Exchange) *inspect.Exchange {
+	redacted := *exchange
+	if exchange.Request != nil {
+		request := *exchange.Request
+		request.Header = redactHeader(request.Header)
+		redacted.Request = &request
+	}
+	if exchange.Response != nil {
+		response := *exchange.Response
+		response.Header = redactHeader(response.Header)
+		redacted.Response = &response
+	}
+
+	return &redacted
+}
+
+func redactHeader(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if _, ok := redacted[name]; ok {
+			redacted[name] = []string{"[redacted]"}
+		}
+	}
+
+	return redacted
+}
+
+// replay sends a captured request again, the replayed exchange is captured as well
+func (h *handler) replay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method "+r.Method+" is not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := r.URL.Query()["id"]
+	if !ok || len(id) != 1 {
+		http.Error(w, "id is missing", http.StatusBadRequest)
+		return
+	}
+
+	exchangeID, err := strconv.ParseInt(id[0], 10, 64)
+	if err != nil {
+		http.Error(w, "id is not a number", http.StatusBadRequest)
+		return
+	}
+
+	exchange := inspect.Default.Exchange(exchangeID)
+	if exchange == nil {
+		http.Error(w, "exchange "+id[0]+" not found", http.StatusNotFound)
+		return
+	}
+
+	response, err := inspect.Replay(exchange)
+	if err != nil {
+		h.log.Errorf("Error in %s: %v", r.URL.String(), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b, err := json.Marshal(&ReplayResult{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/services/inspect"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// inspectedListener captures the exchanges of the accepted connections with the default inspector
+type inspectedListener struct {
+	net.Listener
+}
+
+func (l *inspectedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return inspect.Default.Inspect(conn, 80), nil
+}
+
+func TestInspectHandlers(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	backend.Listener = &inspectedListener{Listener: backend.Listener}
+	backend.Start()
+	defer backend.Close()
+
+	request, err := http.NewRequest(http.MethodGet, backend.URL+"/test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Authorization", "Bearer secret")
+	request.Header.Set("Cookie", "session=secret")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	h := &handler{log: log.Discard}
+	localPort := strconv.Itoa(backend.Listener.Addr().(*net.TCPAddr).Port)
+
+	// wait until the exchange was captured
+	var exchanges []*inspect.Exchange
+	for start := time.Now(); time.Since(start) < time.Second*10 && len(exchanges) == 0; time.Sleep(time.Millisecond * 10) {
+		recorder := httptest.NewRecorder()
+		h.inspect(recorder, httptest.NewRequest(http.MethodGet, "/api/inspect?port="+localPort, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Unexpected status %d: %s", recorder.Code, recorder.Body.String())
+		}
+
+		err = json.Unmarshal(recorder.Body.Bytes(), &exchanges)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(exchanges) != 1 {
+		t.Fatalf("Expected 1 exchange, got %d", len(exchanges))
+	} else if exchanges[0].Request.URI != "/test" || exchanges[0].Response == nil || exchanges[0].Response.StatusCode != http.StatusAccepted {
+		t.Fatalf("Unexpected exchange %#v", exchanges[0])
+	} else if exchanges[0].Request.Header.Get("Authorization") != "[redacted]" || exchanges[0].Request.Header.Get("Cookie") != "[redacted]" {
+		t.Fatalf("Expected redacted headers, got %v", exchanges[0].Request.Header)
+	}
+
+	// exchanges of other ports are filtered
+	recorder := httptest.NewRecorder()
+	h.inspect(recorder, httptest.NewRequest(http.MethodGet, "/api/inspect?port=1", nil))
+	if recorder.Body.String() != "[]" {
+		t.Fatalf("Unexpected exchanges %s", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	h.inspect(recorder, httptest.NewRequest(http.MethodGet, "/api/inspect?port=abc", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+
+	// replay the captured request, which keeps the redacted headers
+	id := strconv.FormatInt(exchanges[0].ID, 10)
+	if inspect.Default.Exchange(exchanges[0].ID).Request.Header.Get("Authorization") != "Bearer secret" {
+		t.Fatal("Expected the captured exchange to keep the authorization header")
+	}
+
+	recorder = httptest.NewRecorder()
+	h.replay(recorder, httptest.NewRequest(http.MethodGet, "/api/inspect/replay?id="+id, nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status %d, got %d", http.StatusMethodNotAllowed, recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.replay(recorder, httptest.NewRequest(http.MethodPost, "/api/inspect/replay?id="+id, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	result := &ReplayResult{}
+	err = json.Unmarshal(recorder.Body.Bytes(), result)
+	if err != nil {
+		t.Fatal(err)
+	} else if result.StatusCode != http.StatusAccepted {
+		t.Fatalf("Unexpected replay status %d", result.StatusCode)
+	}
+
+	for query, status := range map[string]int{
+		"":            http.StatusBadRequest,
+		"?id=abc":     http.StatusBadRequest,
+		"?id=1000000": http.StatusNotFound,
+	} {
+		recorder = httptest.NewRecorder()
+		h.replay(recorder, httptest.NewRequest(http.MethodPost, "/api/inspect/replay"+query, nil))
+		if recorder.Code != status {
+			t.Fatalf("Expected status %d for %s, got %d", status, query, recorder.Code)
+		}
+	}
+}
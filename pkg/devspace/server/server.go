@@ -180,6 +180,8 @@ func newHandler(config config.Config, dependencies []types.Dependency, defaultCo
 	handler.mux.HandleFunc("/api/resize", handler.resize)
 	handler.mux.HandleFunc("/api/logs", handler.logs)
 	handler.mux.HandleFunc("/api/logs-multiple", handler.logsMultiple)
+	handler.mux.HandleFunc("/api/inspect", handler.inspect)
+	handler.mux.HandleFunc("/api/inspect/replay", handler.replay)
 	return handler, nil
 }
 
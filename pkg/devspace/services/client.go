@@ -9,6 +9,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
 	"github.com/loft-sh/devspace/pkg/util/log"
 )
 
@@ -21,9 +22,12 @@ type Client interface {
 
 	StartPortForwarding(interrupt chan error) error
 	StartReversePortForwarding(interrupt chan error) error
+	StartProxy(interrupt chan error) error
+	StartProxyFromCmd(options targetselector.Options, proxyConfig *latest.ProxyConfig, interrupt chan error) error
 	StartSync(interrupt chan error, printSyncLog bool, verboseSync bool) error
 
 	StartSyncFromCmd(options targetselector.Options, syncConfig *latest.SyncConfig, interrupt chan error, verbose bool) error
+	PlanSyncFromCmd(options targetselector.Options, syncConfig *latest.SyncConfig) (*sync.Plan, error)
 	StartTerminal(options targetselector.Options, args []string, workDir string, interrupt chan error, wait bool) (int, error)
 
 	ReplacePods() error
@@ -36,6 +40,8 @@ type client struct {
 	podReplacer podreplace.PodReplacer
 	client      kubectl.Client
 	log         log.Logger
+
+	portForwardingStatus *portForwardingStatusStore
 }
 
 // NewClient creates a new client object
@@ -46,5 +52,7 @@ func NewClient(config config.Config, dependencies []dependencytypes.Dependency,
 		client:       kubeClient,
 		podReplacer:  podreplace.NewPodReplacer(),
 		log:          log,
+
+		portForwardingStatus: newPortForwardingStatusStore(PortForwardingStatusFile),
 	}
 }
@@ -0,0 +1,453 @@
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultBufferSize is the number of exchanges the default inspector keeps
+	DefaultBufferSize = 200
+	// MaxBodySize is the maximum number of bytes that are captured of a request or response body
+	MaxBodySize = 64 * 1024
+
+	// maxPendingRequests is the number of pipelined requests that may wait for their response
+	maxPendingRequests = 64
+	// maxCaptureBufferSize is the number of bytes that may wait for a parser before the capture of the connection is dropped
+	maxCaptureBufferSize = 1024 * 1024
+	// replayTimeout is the time to wait for the response of a replayed request
+	replayTimeout = time.Second * 30
+)
+
+// Default is the inspector of all forwarded ports with inspect: true
+var Default = NewInspector(DefaultBufferSize)
+
+// Request is a captured http request
+type Request struct {
+	Method string      `json:"method"`
+	URI    string      `json:"uri"`
+	Proto  string      `json:"proto"`
+	Host   string      `json:"host"`
+	Header http.Header `json:"header"`
+
+	// Body holds at most MaxBodySize bytes of the body
+	Body          []byte `json:"body,omitempty"`
+	BodyTruncated bool   `json:"bodyTruncated,omitempty"`
+}
+
+// Response is a captured http response
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Proto      string      `json:"proto"`
+	Header     http.Header `json:"header"`
+
+	// Body holds at most MaxBodySize bytes of the body
+	Body          []byte `json:"body,omitempty"`
+	BodyTruncated bool   `json:"bodyTruncated,omitempty"`
+}
+
+// Exchange is a request and its response captured on a forwarded port
+type Exchange struct {
+	ID int64 `json:"id"`
+
+	// LocalAddress is the address the request was sent to, e.g. 127.0.0.1:8080
+	LocalAddress  string `json:"localAddress"`
+	RemotePort    int    `json:"remotePort"`
+	ClientAddress string `json:"clientAddress"`
+
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response,omitempty"`
+
+	// Error is set if no response was received
+	Error string `json:"error,omitempty"`
+}
+
+// LocalPort returns the port the request was sent to
+func (e *Exchange) LocalPort() int {
+	_, port, err := net.SplitHostPort(e.LocalAddress)
+	if err != nil {
+		return 0
+	}
+
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Inspector keeps the last exchanges of the inspected connections in a ring buffer
+type Inspector struct {
+	size int
+
+	exchangesMutex sync.Mutex
+	exchanges      []*Exchange
+	nextID         int64
+}
+
+// NewInspector creates a new inspector that keeps the given number of exchanges
+func NewInspector(size int) *Inspector {
+	return &Inspector{
+		size:      size,
+		exchanges: []*Exchange{},
+		nextID:    1,
+	}
+}
+
+// Exchanges returns the captured exchanges, the oldest first
+func (i *Inspector) Exchanges() []*Exchange {
+	i.exchangesMutex.Lock()
+	defer i.exchangesMutex.Unlock()
+
+	exchanges := make([]*Exchange, len(i.exchanges))
+	copy(exchanges, i.exchanges)
+	return exchanges
+}
+
+// Exchange returns the captured exchange with the id or nil if it is not in the buffer anymore
+func (i *Inspector) Exchange(id int64) *Exchange {
+	i.exchangesMutex.Lock()
+	defer i.exchangesMutex.Unlock()
+
+	for _, exchange := range i.exchanges {
+		if exchange.ID == id {
+			return exchange
+		}
+	}
+
+	return nil
+}
+
+// add adds a complete exchange to the buffer and removes the oldest exchange if the buffer is full.
+// Exchanges are not changed after they were added
+func (i *Inspector) add(exchange *Exchange) {
+	i.exchangesMutex.Lock()
+	defer i.exchangesMutex.Unlock()
+
+	exchange.ID = i.nextID
+	i.nextID++
+
+	i.exchanges = append(i.exchanges, exchange)
+	if len(i.exchanges) > i.size {
+		i.exchanges = i.exchanges[len(i.exchanges)-i.size:]
+	}
+}
+
+// Inspect returns a connection that captures the http requests read from and the responses written to
+// the local connection. Traffic that is not HTTP/1.x is forwarded without being captured. The parsers never
+// block the connection, if they cannot keep up the capture of the connection is dropped
+func (i *Inspector) Inspect(conn net.Conn, remotePort int) net.Conn {
+	requests := newCaptureBuffer(maxCaptureBufferSize)
+	responses := newCaptureBuffer(maxCaptureBufferSize)
+
+	pending := make(chan *pendingExchange, maxPendingRequests)
+	go i.parseRequests(conn, remotePort, requests, pending)
+	go i.parseResponses(responses, pending)
+
+	return &inspectedConn{
+		Conn:      conn,
+		requests:  requests,
+		responses: responses,
+	}
+}
+
+// pendingExchange is an exchange whose request was parsed and that waits for its response
+type pendingExchange struct {
+	exchange *Exchange
+	request  *http.Request
+}
+
+// parseRequests parses the requests of the connection until it is closed or does not speak http
+func (i *Inspector) parseRequests(conn net.Conn, remotePort int, reader *captureBuffer, pending chan *pendingExchange) {
+	defer close(pending)
+	defer reader.drop()
+
+	bufferedReader := bufio.NewReader(reader)
+	for {
+		request, err := http.ReadRequest(bufferedReader)
+		if err != nil {
+			return
+		}
+
+		started := time.Now()
+		body, truncated := readBody(request.Body)
+		if reader.dropped() {
+			return
+		}
+
+		pending <- &pendingExchange{
+			exchange: &Exchange{
+				LocalAddress:  conn.LocalAddr().String(),
+				RemotePort:    remotePort,
+				ClientAddress: conn.RemoteAddr().String(),
+				Started:       started,
+				Request: &Request{
+					Method:        request.Method,
+					URI:           request.RequestURI,
+					Proto:         request.Proto,
+					Host:          request.Host,
+					Header:        request.Header,
+					Body:          body,
+					BodyTruncated: truncated,
+				},
+			},
+			request: request,
+		}
+	}
+}
+
+// parseResponses parses the response of every pending request. If a response cannot be parsed, the
+// remaining data is forwarded without being captured and the pending exchanges are added with an error
+func (i *Inspector) parseResponses(reader *captureBuffer, pending chan *pendingExchange) {
+	bufferedReader := bufio.NewReader(reader)
+	for p := range pending {
+		response, err := readResponse(bufferedReader, p.request)
+		if err != nil {
+			p.exchange.Duration = time.Since(p.exchange.Started)
+			p.exchange.Error = err.Error()
+			i.add(p.exchange)
+			break
+		}
+
+		body, truncated := readBody(response.Body)
+		if reader.dropped() {
+			p.exchange.Duration = time.Since(p.exchange.Started)
+			p.exchange.Error = errCaptureDropped.Error()
+			i.add(p.exchange)
+			break
+		}
+
+		p.exchange.Duration = time.Since(p.exchange.Started)
+		p.exchange.Response = &Response{
+			StatusCode:    response.StatusCode,
+			Status:        response.Status,
+			Proto:         response.Proto,
+			Header:        response.Header,
+			Body:          body,
+			BodyTruncated: truncated,
+		}
+		i.add(p.exchange)
+
+		// the connection does not speak http anymore, e.g. for websockets
+		if response.StatusCode == http.StatusSwitchingProtocols {
+			break
+		}
+	}
+
+	reader.drop()
+	for p := range pending {
+		p.exchange.Error = "connection closed without response"
+		i.add(p.exchange)
+	}
+}
+
+// readResponse reads the final response of the request and skips informational responses such as 100 Continue
+func readResponse(reader *bufio.Reader, request *http.Request) (*http.Response, error) {
+	for {
+		response, err := http.ReadResponse(reader, request)
+		if err != nil {
+			return nil, errors.Wrap(err, "read response")
+		}
+		if response.StatusCode >= 200 || response.StatusCode == http.StatusSwitchingProtocols {
+			return response, nil
+		}
+	}
+}
+
+// readBody reads at most MaxBodySize bytes of the body and discards the rest
+func readBody(body io.ReadCloser) ([]byte, bool) {
+	defer body.Close()
+
+	data, _ := ioutil.ReadAll(io.LimitReader(body, MaxBodySize+1))
+	if len(data) > MaxBodySize {
+		_, _ = io.Copy(ioutil.Discard, body)
+		return data[:MaxBodySize], true
+	}
+
+	return data, false
+}
+
+var errCaptureDropped = errors.New("capture dropped, the parser could not keep up with the connection")
+
+// captureBuffer passes the data of a connection to a parser. Writes never block, if the parser does not keep up
+// and the buffer would exceed its size, the buffered data is dropped and the parser stops with an error
+type captureBuffer struct {
+	mutex sync.Mutex
+	cond  *sync.Cond
+
+	data     []byte
+	size     int
+	closed   bool
+	overflow bool
+}
+
+func newCaptureBuffer(size int) *captureBuffer {
+	buffer := &captureBuffer{
+		size: size,
+	}
+	buffer.cond = sync.NewCond(&buffer.mutex)
+	return buffer
+}
+
+// Write appends the data to the buffer or drops the capture if the buffer is full
+func (b *captureBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed || b.overflow {
+		return len(p), nil
+	}
+	if len(b.data)+len(p) > b.size {
+		b.overflow = true
+		b.data = nil
+		b.cond.Broadcast()
+		return len(p), nil
+	}
+
+	b.data = append(b.data, p...)
+	b.cond.Broadcast()
+	return len(p), nil
+}
+
+// Read waits until data is available, the buffer is closed or the capture was dropped
+func (b *captureBuffer) Read(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for len(b.data) == 0 && !b.closed && !b.overflow {
+		b.cond.Wait()
+	}
+	if b.overflow {
+		return 0, errCaptureDropped
+	} else if len(b.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, b.data)
+	b.data = b.data[n:]
+	if len(b.data) == 0 {
+		b.data = nil
+	}
+
+	return n, nil
+}
+
+// Close lets the parser read the remaining data and then returns io.EOF
+func (b *captureBuffer) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+	b.cond.Broadcast()
+	return nil
+}
+
+// drop discards the buffered and all future data, e.g. if the parser stopped
+func (b *captureBuffer) drop() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.overflow = true
+	b.data = nil
+	b.cond.Broadcast()
+}
+
+// dropped returns true if the capture was dropped
+func (b *captureBuffer) dropped() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.overflow
+}
+
+// inspectedConn copies the read and written data to the request and response parsers
+type inspectedConn struct {
+	net.Conn
+
+	requests  *captureBuffer
+	responses *captureBuffer
+}
+
+func (c *inspectedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		_, _ = c.requests.Write(b[:n])
+	}
+	if err != nil {
+		_ = c.requests.Close()
+	}
+
+	return n, err
+}
+
+func (c *inspectedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		_, _ = c.responses.Write(b[:n])
+	}
+	if err != nil {
+		_ = c.responses.Close()
+	}
+
+	return n, err
+}
+
+func (c *inspectedConn) Close() error {
+	_ = c.requests.Close()
+	_ = c.responses.Close()
+	return c.Conn.Close()
+}
+
+// Replay sends the captured request again to the local address it was captured on. The replayed request
+// passes the port forwarding and is captured as a new exchange
+func Replay(exchange *Exchange) (*http.Response, error) {
+	if exchange.Request.BodyTruncated {
+		return nil, errors.Errorf("the body of request %d was truncated and cannot be replayed", exchange.ID)
+	}
+
+	// the uri of a proxy request contains the scheme and host
+	uri, err := url.ParseRequestURI(exchange.Request.URI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse uri of request %d", exchange.ID)
+	}
+
+	request, err := http.NewRequest(exchange.Request.Method, "http://"+exchange.LocalAddress+uri.RequestURI(), bytes.NewReader(exchange.Request.Body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range exchange.Request.Header {
+		if name == "Content-Length" || name == "Transfer-Encoding" || name == "Connection" {
+			continue
+		}
+
+		request.Header[name] = values
+	}
+	request.Host = exchange.Request.Host
+
+	client := &http.Client{
+		Timeout: replayTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "replay request %d", exchange.ID)
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	return response, nil
+}
@@ -0,0 +1,176 @@
+package inspect
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Path", r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(strings.ToUpper(string(body))))
+	}))
+	defer backend.Close()
+
+	// forward the inspected local connections to the backend
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	inspector := NewInspector(2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				conn = inspector.Inspect(conn, 80)
+				defer conn.Close()
+
+				target, err := net.Dial("tcp", backend.Listener.Addr().String())
+				if err != nil {
+					return
+				}
+				defer target.Close()
+
+				go func() {
+					_, _ = io.Copy(target, conn)
+				}()
+				_, _ = io.Copy(conn, target)
+			}(conn)
+		}
+	}()
+
+	url := "http://" + listener.Addr().String()
+	for _, path := range []string{"/first", "/second", "/third"} {
+		response, err := http.Post(url+path, "text/plain", strings.NewReader("hello"+path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if string(body) != strings.ToUpper("hello"+path) {
+			t.Fatalf("Unexpected response body %s", string(body))
+		}
+	}
+
+	// the buffer only keeps the last two exchanges
+	exchanges := waitForExchanges(t, inspector, 3)
+	if len(exchanges) != 2 {
+		t.Fatalf("Expected 2 exchanges, got %d", len(exchanges))
+	}
+	for index, path := range []string{"/second", "/third"} {
+		exchange := exchanges[index]
+		if exchange.ID != int64(index+2) {
+			t.Fatalf("Expected exchange id %d, got %d", index+2, exchange.ID)
+		} else if exchange.Request.Method != http.MethodPost || exchange.Request.URI != path || string(exchange.Request.Body) != "hello"+path {
+			t.Fatalf("Unexpected request %#v", exchange.Request)
+		} else if exchange.Response == nil || exchange.Response.StatusCode != http.StatusCreated || exchange.Response.Header.Get("X-Path") != path {
+			t.Fatalf("Unexpected response %#v (error %s)", exchange.Response, exchange.Error)
+		} else if string(exchange.Response.Body) != strings.ToUpper("hello"+path) {
+			t.Fatalf("Unexpected response body %s", string(exchange.Response.Body))
+		} else if exchange.LocalPort() != listener.Addr().(*net.TCPAddr).Port || exchange.RemotePort != 80 {
+			t.Fatalf("Unexpected ports %s and %d", exchange.LocalAddress, exchange.RemotePort)
+		}
+	}
+
+	// replay the last request
+	response, err := Replay(exchanges[1])
+	if err != nil {
+		t.Fatal(err)
+	} else if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Unexpected replay status %d", response.StatusCode)
+	}
+
+	exchanges = waitForExchanges(t, inspector, 4)
+	if exchanges[1].Request.URI != "/third" || string(exchanges[1].Response.Body) != "HELLO/THIRD" {
+		t.Fatalf("Unexpected replayed exchange %#v", exchanges[1])
+	}
+}
+
+// waitForExchanges waits until the exchange with the id was captured
+func waitForExchanges(t *testing.T, inspector *Inspector, id int64) []*Exchange {
+	for start := time.Now(); time.Since(start) < time.Second*10; time.Sleep(time.Millisecond * 10) {
+		exchanges := inspector.Exchanges()
+		if len(exchanges) > 0 && exchanges[len(exchanges)-1].ID == id {
+			return exchanges
+		}
+	}
+
+	t.Fatalf("Exchange %d was not captured", id)
+	return nil
+}
+
+func TestInspectServerFirst(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	inspector := NewInspector(2)
+	conn := inspector.Inspect(local, 80)
+	defer conn.Close()
+
+	// the server speaks first and more than the capture buffer can hold
+	greeting := []byte(strings.Repeat("x", maxCaptureBufferSize+1))
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(greeting)
+		done <- err
+	}()
+
+	received, err := ioutil.ReadAll(io.LimitReader(remote, int64(len(greeting))))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(received) != len(greeting) {
+		t.Fatalf("Expected %d bytes, got %d", len(greeting), len(received))
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second * 10):
+		t.Fatal("Write was blocked by the parser")
+	}
+
+	if len(inspector.Exchanges()) != 0 {
+		t.Fatalf("Expected no exchanges, got %d", len(inspector.Exchanges()))
+	}
+}
+
+func TestCaptureBuffer(t *testing.T) {
+	buffer := newCaptureBuffer(4)
+	_, _ = buffer.Write([]byte("abc"))
+
+	data := make([]byte, 2)
+	n, err := buffer.Read(data)
+	if err != nil || string(data[:n]) != "ab" {
+		t.Fatalf("Unexpected read %s: %v", string(data[:n]), err)
+	}
+
+	// the buffer overflows and drops the capture
+	n, err = buffer.Write([]byte("defg"))
+	if err != nil || n != 4 {
+		t.Fatalf("Unexpected write %d: %v", n, err)
+	} else if !buffer.dropped() {
+		t.Fatal("Expected the capture to be dropped")
+	}
+
+	_, err = buffer.Read(data)
+	if err != errCaptureDropped {
+		t.Fatalf("Expected %v, got %v", errCaptureDropped, err)
+	}
+}
@@ -0,0 +1,132 @@
+package podreplace
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	dependencytypes "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
+	"github.com/loft-sh/devspace/pkg/util/imageselector"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// replaceContainersInPodSpec applies the configured containers of the replace pod config on the pod spec.
+// All containers are selected before any of them is changed and every container is only selected once, so
+// neither a replaced image nor the container of the replace pod's replaceImage (replacedIndex) is selected again
+func replaceContainersInPodSpec(podSpec *corev1.PodSpec, config config.Config, dependencies []dependencytypes.Dependency, replacePod *latest.ReplacePod, replacedIndex int) error {
+	selected := map[int]bool{}
+	if replacedIndex >= 0 {
+		selected[replacedIndex] = true
+	}
+
+	indexes := make([]int, len(replacePod.Containers))
+	for i, replaceContainer := range replacePod.Containers {
+		index, err := findContainer(podSpec, config, dependencies, replaceContainer, selected)
+		if err != nil {
+			return errors.Wrapf(err, "containers[%d]", i)
+		}
+
+		indexes[i] = index
+		selected[index] = true
+	}
+
+	for i, replaceContainer := range replacePod.Containers {
+		container, err := applyReplaceContainer(&podSpec.Containers[indexes[i]], config, dependencies, replaceContainer)
+		if err != nil {
+			return errors.Wrapf(err, "containers[%d]", i)
+		}
+
+		podSpec.Containers[indexes[i]] = *container
+	}
+
+	return nil
+}
+
+// findContainer returns the index of the container in the pod spec that is selected by the container config and
+// was not selected before
+func findContainer(podSpec *corev1.PodSpec, config config.Config, dependencies []dependencytypes.Dependency, replaceContainer *latest.ReplaceContainer, selected map[int]bool) (int, error) {
+	if replaceContainer.ContainerName != "" {
+		for i := range podSpec.Containers {
+			if podSpec.Containers[i].Name == replaceContainer.ContainerName {
+				if selected[i] {
+					return 0, fmt.Errorf("container %s is already replaced", replaceContainer.ContainerName)
+				}
+
+				return i, nil
+			}
+		}
+
+		return 0, fmt.Errorf("couldn't find container %s in pod spec", replaceContainer.ContainerName)
+	}
+
+	var (
+		imageSelector *imageselector.ImageSelector
+		err           error
+	)
+	if replaceContainer.ImageName != "" {
+		imageSelector, err = imageselector.Resolve(replaceContainer.ImageName, config, dependencies)
+		if err != nil {
+			return 0, err
+		} else if imageSelector == nil {
+			return 0, fmt.Errorf("cannot find image name: %#+v", replaceContainer.ImageName)
+		}
+	} else {
+		imageSelector, err = util.ResolveImageAsImageSelector(replaceContainer.ImageSelector, config, dependencies)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for i := range podSpec.Containers {
+		if selected[i] == false && imageselector.CompareImageNames(*imageSelector, podSpec.Containers[i].Image) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("couldn't find a container with image %s in pod spec that is not replaced already", imageSelector.Image)
+}
+
+// applyReplaceContainer returns a copy of the container with the replaced image, the entrypoint override
+// and the patches of the container config applied
+func applyReplaceContainer(container *corev1.Container, config config.Config, dependencies []dependencytypes.Dependency, replaceContainer *latest.ReplaceContainer) (*corev1.Container, error) {
+	retContainer := container.DeepCopy()
+	if replaceContainer.ReplaceImage != "" {
+		imageStr, err := util.ResolveImage(replaceContainer.ReplaceImage, config, dependencies)
+		if err != nil {
+			return nil, err
+		}
+
+		retContainer.Image = imageStr
+	}
+
+	// an entrypoint resets the args of the image like in docker
+	if len(replaceContainer.Entrypoint) > 0 {
+		retContainer.Command = replaceContainer.Entrypoint
+		retContainer.Args = replaceContainer.Cmd
+	} else if len(replaceContainer.Cmd) > 0 {
+		retContainer.Args = replaceContainer.Cmd
+	}
+
+	if len(replaceContainer.Patches) == 0 {
+		return retContainer, nil
+	}
+
+	raw, err := loader.ApplyPatchesOnObject(convertToInterface(retContainer), replaceContainer.Patches)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply container patches")
+	}
+
+	// convert back
+	rawJson := convertFromInterface(raw)
+	retContainer = &corev1.Container{}
+	err = json.Unmarshal(rawJson, retContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	return retContainer, nil
+}
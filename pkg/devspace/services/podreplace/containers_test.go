@@ -0,0 +1,120 @@
+package podreplace
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestReplaceContainersInPodSpec(t *testing.T) {
+	conf := config.NewConfig(nil, latest.NewRaw(), generated.New(), nil)
+	podSpec := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "app", Image: "backend:1", Args: []string{"serve"}},
+			{Name: "worker", Image: "backend:1", Command: []string{"worker"}, Args: []string{"--queue", "jobs"}},
+			{Name: "sidecar", Image: "proxy:1"},
+		},
+	}
+	replacePod := &latest.ReplacePod{
+		ImageSelector: "backend:1",
+		ContainerName: "app",
+		Containers: []*latest.ReplaceContainer{
+			{
+				ContainerName: "worker",
+				ReplaceImage:  "backend:dev",
+				Entrypoint:    []string{"sleep"},
+				Cmd:           []string{"infinity"},
+			},
+			{
+				ImageSelector: "proxy:1",
+				ReplaceImage:  "proxy:debug",
+				Patches: []*latest.PatchConfig{
+					{
+						Operation: "add",
+						Path:      "env",
+						Value:     []interface{}{map[interface{}]interface{}{"name": "DEBUG", "value": "true"}},
+					},
+				},
+			},
+		},
+	}
+
+	err := replaceContainersInPodSpec(podSpec, conf, nil, replacePod, -1)
+	assert.NilError(t, err)
+
+	// the selected container of the pod is not changed
+	assert.DeepEqual(t, podSpec.Containers[0], corev1.Container{Name: "app", Image: "backend:1", Args: []string{"serve"}})
+
+	// the entrypoint replaces command and args
+	assert.Equal(t, podSpec.Containers[1].Image, "backend:dev")
+	assert.DeepEqual(t, podSpec.Containers[1].Command, []string{"sleep"})
+	assert.DeepEqual(t, podSpec.Containers[1].Args, []string{"infinity"})
+
+	// the patches are applied on the container
+	assert.Equal(t, podSpec.Containers[2].Image, "proxy:debug")
+	assert.DeepEqual(t, podSpec.Containers[2].Env, []corev1.EnvVar{{Name: "DEBUG", Value: "true"}})
+
+	// a changed container config changes the config hash
+	oldHash, err := hashConfig(replacePod)
+	assert.NilError(t, err)
+	replacePod.Containers[0].Cmd = []string{"3600"}
+	newHash, err := hashConfig(replacePod)
+	assert.NilError(t, err)
+	assert.Assert(t, oldHash != newHash)
+
+	// a container that does not exist is an error
+	replacePod.Containers[0].ContainerName = "unknown"
+	err = replaceContainersInPodSpec(podSpec, conf, nil, replacePod, -1)
+	assert.ErrorContains(t, err, "couldn't find container unknown")
+}
+
+func TestReplaceContainersSelectEveryContainerOnce(t *testing.T) {
+	conf := config.NewConfig(nil, latest.NewRaw(), generated.New(), nil)
+	podSpec := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "app", Image: "backend:1"},
+			{Name: "worker", Image: "backend:1"},
+			{Name: "cron", Image: "backend:1"},
+		},
+	}
+	replacePod := &latest.ReplacePod{
+		ImageSelector: "backend:1",
+		ReplaceImage:  "backend:dev",
+		Containers: []*latest.ReplaceContainer{
+			{
+				ImageSelector: "backend",
+				Entrypoint:    []string{"sleep"},
+			},
+			{
+				ContainerName: "cron",
+				Cmd:           []string{"infinity"},
+			},
+		},
+	}
+
+	// the image of the first container is replaced, so the container config selects the next one
+	replacedIndex, err := replaceImageInPodSpec(podSpec, conf, nil, replacePod)
+	assert.NilError(t, err)
+	assert.Equal(t, replacedIndex, 0)
+	err = replaceContainersInPodSpec(podSpec, conf, nil, replacePod, replacedIndex)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, podSpec.Containers, []corev1.Container{
+		{Name: "app", Image: "backend:dev"},
+		{Name: "worker", Image: "backend:1", Command: []string{"sleep"}},
+		{Name: "cron", Image: "backend:1", Args: []string{"infinity"}},
+	})
+
+	// no container is left for another container config
+	replacePod.Containers = append(replacePod.Containers, &latest.ReplaceContainer{ImageSelector: "backend:1"})
+	err = replaceContainersInPodSpec(podSpec, conf, nil, replacePod, replacedIndex)
+	assert.ErrorContains(t, err, "couldn't find a container with image backend:1 in pod spec that is not replaced already")
+
+	// a container that is already replaced cannot be selected by its name
+	replacePod.Containers = []*latest.ReplaceContainer{{ContainerName: "app"}}
+	err = replaceContainersInPodSpec(podSpec, conf, nil, replacePod, replacedIndex)
+	assert.ErrorContains(t, err, "container app is already replaced")
+}
@@ -0,0 +1,326 @@
+package podreplace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/util/imageselector"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// getJobParent returns the job with the given name or the cron job that created it
+func getJobParent(ctx context.Context, client kubectl.Client, namespace, name string) (runtime.Object, error) {
+	job, err := client.KubeClient().BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("unrecognized owning Job %s", name)
+		}
+
+		return nil, err
+	}
+
+	jobOwner := metav1.GetControllerOf(job)
+	if jobOwner == nil {
+		return job, nil
+	}
+
+	// is cron job?
+	if jobOwner.Kind == "CronJob" {
+		cronJob, err := client.KubeClient().BatchV1beta1().CronJobs(namespace).Get(ctx, jobOwner.Name, metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				return nil, fmt.Errorf("unrecognized owning CronJob %s group version: %s", jobOwner.Name, jobOwner.APIVersion)
+			}
+
+			return nil, err
+		}
+
+		return cronJob, nil
+	}
+
+	return nil, fmt.Errorf("unrecognized owner of Job %s: %s %s %s", job.Name, jobOwner.Kind, jobOwner.APIVersion, jobOwner.Name)
+}
+
+// findReplaceableCronJob searches a cron job whose job template matches the replace pod config. Cron jobs
+// often have no running pod, so the pod to replace is built from the job template instead
+func findReplaceableCronJob(ctx context.Context, client kubectl.Client, replacePod *latest.ReplacePod, imageSelectors []imageselector.ImageSelector) (*kubectl.SelectedPodContainer, runtime.Object, error) {
+	namespace := replacePod.Namespace
+	if namespace == "" {
+		namespace = client.Namespace()
+	}
+
+	cronJobs, err := client.KubeClient().BatchV1beta1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// we are not allowed to list cron jobs or the cluster does not serve them
+		if kerrors.IsForbidden(err) || kerrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+
+		return nil, nil, errors.Wrap(err, "list cron jobs")
+	}
+
+	var (
+		selected *kubectl.SelectedPodContainer
+		parent   *batchv1beta1.CronJob
+	)
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		template := &cronJob.Spec.JobTemplate.Spec.Template
+		container, err := matchPodTemplate(template, replacePod, imageSelectors)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "cron job %s/%s", cronJob.Namespace, cronJob.Name)
+		} else if container == nil {
+			continue
+		} else if parent != nil {
+			return nil, nil, fmt.Errorf("found multiple cron jobs to replace: %s and %s", parent.Name, cronJob.Name)
+		}
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        cronJob.Name,
+				Namespace:   cronJob.Namespace,
+				Labels:      template.Labels,
+				Annotations: template.Annotations,
+			},
+			Spec: *template.Spec.DeepCopy(),
+		}
+		for i := range pod.Spec.Containers {
+			if pod.Spec.Containers[i].Name == container.Name {
+				selected = &kubectl.SelectedPodContainer{
+					Pod:       pod,
+					Container: &pod.Spec.Containers[i],
+				}
+			}
+		}
+		parent = cronJob
+	}
+	if parent == nil {
+		return nil, nil, nil
+	}
+
+	return selected, parent, nil
+}
+
+// findReplaceableWorkloads returns the deployments, stateful sets, replica sets and jobs that are scaled up and whose pod
+// template matches the replace pod config. Their pods might not have been created yet, so they are matched by their template
+func findReplaceableWorkloads(ctx context.Context, client kubectl.Client, namespace string, replacePod *latest.ReplacePod, imageSelectors []imageselector.ImageSelector) ([]string, error) {
+	type workload struct {
+		kind     string
+		name     string
+		template *corev1.PodTemplateSpec
+	}
+
+	workloads := []workload{}
+	deployments, err := client.KubeClient().AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployments")
+	}
+	for i := range deployments.Items {
+		if deployments.Items[i].Spec.Replicas == nil || *deployments.Items[i].Spec.Replicas > 0 {
+			workloads = append(workloads, workload{kind: "Deployment", name: deployments.Items[i].Name, template: &deployments.Items[i].Spec.Template})
+		}
+	}
+
+	statefulSets, err := client.KubeClient().AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list stateful sets")
+	}
+	for i := range statefulSets.Items {
+		if statefulSets.Items[i].Spec.Replicas == nil || *statefulSets.Items[i].Spec.Replicas > 0 {
+			workloads = append(workloads, workload{kind: "StatefulSet", name: statefulSets.Items[i].Name, template: &statefulSets.Items[i].Spec.Template})
+		}
+	}
+
+	// replica sets of deployments are already matched by their deployment
+	replicaSets, err := client.KubeClient().AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list replica sets")
+	}
+	for i := range replicaSets.Items {
+		if metav1.GetControllerOf(&replicaSets.Items[i]) == nil && (replicaSets.Items[i].Spec.Replicas == nil || *replicaSets.Items[i].Spec.Replicas > 0) {
+			workloads = append(workloads, workload{kind: "ReplicaSet", name: replicaSets.Items[i].Name, template: &replicaSets.Items[i].Spec.Template})
+		}
+	}
+
+	// jobs of cron jobs are matched by the cron job template
+	jobs, err := client.KubeClient().BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list jobs")
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if metav1.GetControllerOf(job) == nil && job.Status.CompletionTime == nil && (job.Spec.Parallelism == nil || *job.Spec.Parallelism > 0) {
+			workloads = append(workloads, workload{kind: "Job", name: job.Name, template: &job.Spec.Template})
+		}
+	}
+
+	matched := []string{}
+	for _, workload := range workloads {
+		container, err := matchPodTemplate(workload.template, replacePod, imageSelectors)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s %s/%s", workload.kind, namespace, workload.name)
+		} else if container != nil {
+			matched = append(matched, workload.kind+" "+workload.name)
+		}
+	}
+
+	return matched, nil
+}
+
+// matchPodTemplate returns the container of the pod template that is selected by the replace pod config or nil
+// if the template does not match
+func matchPodTemplate(template *corev1.PodTemplateSpec, replacePod *latest.ReplacePod, imageSelectors []imageselector.ImageSelector) (*corev1.Container, error) {
+	if len(replacePod.LabelSelector) > 0 && labels.SelectorFromSet(replacePod.LabelSelector).Matches(labels.Set(template.Labels)) == false {
+		return nil, nil
+	}
+
+	matched := []*corev1.Container{}
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if replacePod.ContainerName != "" && container.Name != replacePod.ContainerName {
+			continue
+		}
+
+		if len(imageSelectors) > 0 {
+			found := false
+			for _, imageSelector := range imageSelectors {
+				if imageselector.CompareImageNames(imageSelector, container.Image) {
+					found = true
+					break
+				}
+			}
+			if found == false {
+				continue
+			}
+		} else if len(replacePod.LabelSelector) == 0 {
+			continue
+		}
+
+		matched = append(matched, container)
+	}
+
+	if len(matched) == 0 {
+		return nil, nil
+	} else if len(matched) > 1 && len(imageSelectors) == 0 {
+		return nil, fmt.Errorf("job template has more than 1 containers and containerName is an empty string")
+	}
+
+	return matched[0], nil
+}
+
+// makeLongLived changes the pod spec of a job pod, so that the pod keeps running and is restarted like a
+// pod of a deployment
+func makeLongLived(podSpec *corev1.PodSpec) {
+	podSpec.RestartPolicy = corev1.RestartPolicyAlways
+	podSpec.ActiveDeadlineSeconds = nil
+}
+
+// suspendCronJob suspends the cron job and pauses its running jobs. The previous suspend value is saved
+// in an annotation
+func suspendCronJob(ctx context.Context, client kubectl.Client, cronJob *batchv1beta1.CronJob) error {
+	if cronJob.Annotations == nil || cronJob.Annotations[SuspendAnnotation] == "" {
+		cloned := cronJob.DeepCopy()
+		if cronJob.Annotations == nil {
+			cronJob.Annotations = map[string]string{}
+		}
+
+		cronJob.Annotations[SuspendAnnotation] = strconv.FormatBool(cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend)
+		cronJob.Spec.Suspend = ptr.Bool(true)
+		patch := MergeFrom(cloned)
+		bytes, err := patch.Data(cronJob)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.KubeClient().BatchV1beta1().CronJobs(cronJob.Namespace).Patch(ctx, cronJob.Name, patch.Type(), bytes, metav1.PatchOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	// pause the jobs that are still running
+	jobs, err := listCronJobJobs(ctx, client, cronJob)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.Status.Active == 0 {
+			continue
+		}
+
+		err = scaleDownParent(ctx, client, job)
+		if err != nil {
+			return errors.Wrapf(err, "pause job %s", job.Name)
+		}
+	}
+
+	return nil
+}
+
+// resumeCronJob restores the suspend value of the cron job and resumes its paused jobs
+func resumeCronJob(ctx context.Context, client kubectl.Client, cronJob *batchv1beta1.CronJob) error {
+	jobs, err := listCronJobJobs(ctx, client, cronJob)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		err = scaleUpParent(ctx, client, job)
+		if err != nil {
+			return errors.Wrapf(err, "resume job %s", job.Name)
+		}
+	}
+
+	if cronJob.Annotations == nil || cronJob.Annotations[SuspendAnnotation] == "" {
+		return nil
+	}
+
+	suspend, err := strconv.ParseBool(cronJob.Annotations[SuspendAnnotation])
+	if err != nil {
+		return errors.Wrap(err, "parse old suspend")
+	}
+
+	cloned := cronJob.DeepCopy()
+	delete(cronJob.Annotations, SuspendAnnotation)
+	cronJob.Spec.Suspend = &suspend
+	patch := MergeFrom(cloned)
+	bytes, err := patch.Data(cronJob)
+	if err != nil {
+		return errors.Wrap(err, "create parent patch")
+	}
+
+	_, err = client.KubeClient().BatchV1beta1().CronJobs(cronJob.Namespace).Patch(ctx, cronJob.Name, patch.Type(), bytes, metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrap(err, "patch parent")
+	}
+
+	return nil
+}
+
+// listCronJobJobs returns the jobs that were created by the cron job
+func listCronJobJobs(ctx context.Context, client kubectl.Client, cronJob *batchv1beta1.CronJob) ([]*batchv1.Job, error) {
+	jobList, err := client.KubeClient().BatchV1().Jobs(cronJob.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list jobs")
+	}
+
+	jobs := []*batchv1.Job{}
+	for i := range jobList.Items {
+		owner := metav1.GetControllerOf(&jobList.Items[i])
+		if owner != nil && owner.Kind == "CronJob" && owner.Name == cronJob.Name {
+			jobs = append(jobs, &jobList.Items[i])
+		}
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,188 @@
+package podreplace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSuspendCronJob(t *testing.T) {
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker",
+			Namespace: "test",
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": "worker"},
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyOnFailure,
+							Containers: []corev1.Container{
+								{Name: "worker", Image: "worker"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-1",
+			Namespace: "test",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "worker", Controller: ptr.Bool(true)},
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: ptr.Int32(2),
+		},
+		Status: batchv1.JobStatus{
+			Active: 2,
+		},
+	}
+	kube := fake.NewSimpleClientset(cronJob, job)
+	client := &fakekube.Client{Client: kube}
+
+	// the cron job is found by the labels of its job template
+	selected, parent, err := findReplaceableCronJob(context.TODO(), client, &latest.ReplacePod{
+		LabelSelector: map[string]string{"app": "worker"},
+		Namespace:     "test",
+	}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, parent.(*batchv1beta1.CronJob).Name, "worker")
+	assert.Equal(t, selected.Pod.Name, "worker")
+	assert.Equal(t, selected.Container.Name, "worker")
+
+	// the job is resolved to its cron job
+	parent, err = getJobParent(context.TODO(), client, "test", "worker-1")
+	assert.NilError(t, err)
+	assert.Equal(t, parent.(*batchv1beta1.CronJob).Name, "worker")
+
+	// suspend the cron job and pause the running job
+	err = scaleDownParent(context.TODO(), client, parent)
+	assert.NilError(t, err)
+
+	cronJob, err = kube.BatchV1beta1().CronJobs("test").Get(context.TODO(), "worker", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *cronJob.Spec.Suspend, true)
+	assert.Equal(t, cronJob.Annotations[SuspendAnnotation], "false")
+	job, err = kube.BatchV1().Jobs("test").Get(context.TODO(), "worker-1", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *job.Spec.Parallelism, int32(0))
+	assert.Equal(t, job.Annotations[ReplicasAnnotation], "2")
+
+	// resume the original schedule
+	err = scaleUpParent(context.TODO(), client, cronJob)
+	assert.NilError(t, err)
+
+	cronJob, err = kube.BatchV1beta1().CronJobs("test").Get(context.TODO(), "worker", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *cronJob.Spec.Suspend, false)
+	assert.Equal(t, cronJob.Annotations[SuspendAnnotation], "")
+	job, err = kube.BatchV1().Jobs("test").Get(context.TODO(), "worker-1", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *job.Spec.Parallelism, int32(2))
+	assert.Equal(t, job.Annotations[ReplicasAnnotation], "")
+}
+
+func TestFindCronJobParent(t *testing.T) {
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"app": "worker"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "worker", Image: "worker"},
+			},
+		},
+	}
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker",
+			Namespace: "test",
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: template,
+				},
+			},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker",
+			Namespace: "test",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.Int32(1),
+			Template: template,
+		},
+	}
+	replacePod := &latest.ReplacePod{
+		LabelSelector: map[string]string{"app": "worker"},
+		Namespace:     "test",
+	}
+
+	// a deployment that is starting and the cron job match
+	kube := fake.NewSimpleClientset(cronJob, deployment)
+	client := &fakekube.Client{Client: kube}
+	_, _, err := findSingleReplaceablePodParent(context.TODO(), client, nil, nil, replacePod, log.Discard)
+	assert.Error(t, err, "found multiple workloads to replace: CronJob worker and Deployment worker, please use a more specific selector")
+
+	// the cron job is replaced if the deployment is scaled down
+	deployment.Spec.Replicas = ptr.Int32(0)
+	kube = fake.NewSimpleClientset(cronJob, deployment)
+	client = &fakekube.Client{Client: kube}
+	selected, parent, err := findSingleReplaceablePodParent(context.TODO(), client, nil, nil, replacePod, log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, parent.(*batchv1beta1.CronJob).Name, "worker")
+	assert.Equal(t, selected.Container.Name, "worker")
+
+	// a matching pod that does not belong to the cron job is ambiguous
+	_, err = kube.CoreV1().Pods("test").Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other",
+			Namespace: "test",
+			Labels:    template.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "batch/v1", Kind: "Job", Name: "other", Controller: ptr.Bool(true)},
+			},
+		},
+		Spec: template.Spec,
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	_, err = kube.BatchV1().Jobs("test").Create(context.TODO(), &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other",
+			Namespace: "test",
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{Spec: template.Spec},
+		},
+		Status: batchv1.JobStatus{
+			CompletionTime: &metav1.Time{},
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	_, _, err = findSingleReplaceablePodParent(context.TODO(), client, nil, nil, replacePod, log.Discard)
+	assert.Error(t, err, "found multiple workloads to replace: CronJob worker and pod other, please use a more specific selector")
+}
@@ -0,0 +1,253 @@
+package podreplace
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/upgrade"
+	"github.com/loft-sh/devspace/pkg/util/encoding"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PersistenceVolumeName is the name of the volume in the replaced pod that holds the persisted paths
+	PersistenceVolumeName = "devspace-persistence"
+	// DefaultPersistenceSize is the size of the persistent volume claim if no size is configured
+	DefaultPersistenceSize = "10Gi"
+	// DefaultPersistenceHelperImage is the image that provides the devspace helper, which populates the persistent
+	// volume claim. It is tagged with the version of DevSpace
+	DefaultPersistenceHelperImage = "devspacesh/devspacehelper"
+
+	// PersistenceStagingPath is the sub path in the persistent volume claim where the persisted paths are copied
+	// to before they are moved to their volume path
+	PersistenceStagingPath = ".devspace-staging"
+
+	// persistenceHelperContainerName is the name of the init container that copies the devspace helper from the
+	// helper image into the helper volume
+	persistenceHelperContainerName = "devspace-persistence-helper"
+	// persistenceHelperVolumeName is the name of the volume that holds the devspace helper for the init container
+	persistenceHelperVolumeName = "devspace-persistence-helper"
+	// persistenceHelperMountPath is the path the init containers mount the helper volume at
+	persistenceHelperMountPath = "/devspace-helper"
+	// persistenceHelperImagePath is the path of the devspace helper in the helper image
+	persistenceHelperImagePath = "/devspacehelper"
+
+	// persistenceInitContainerName is the name of the init container that populates the persistent volume claim
+	persistenceInitContainerName = "devspace-persistence-init"
+	// persistenceInitMountPath is the path the init container mounts the persistent volume claim at
+	persistenceInitMountPath = "/devspace-persistence"
+)
+
+// PersistentVolumePath returns the sub path in the persistent volume claim where the path is persisted
+func PersistentVolumePath(persistPath latest.PersistentPath) string {
+	if persistPath.VolumePath != "" {
+		return path.Clean(persistPath.VolumePath)
+	}
+
+	return strings.TrimPrefix(path.Clean(persistPath.Path), "/")
+}
+
+// persistPaths mounts the persisted paths from a persistent volume claim into the replaced container. The claim
+// is created if it does not exist yet and an init container populates paths that are not in the claim yet with
+// their content from the image. Only the replaced container mounts the persisted paths
+func persistPaths(ctx context.Context, client kubectl.Client, pod *corev1.Pod, containerName string, replacePod *latest.ReplacePod, log log.Logger) error {
+	var container *corev1.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == containerName {
+			container = &pod.Spec.Containers[i]
+			break
+		}
+	}
+	if container == nil {
+		return fmt.Errorf("couldn't find container %s in replaced pod", containerName)
+	}
+
+	populateArgs := []string{path.Join(persistenceHelperMountPath, "devspacehelper"), "persist", "populate", "--volume", persistenceInitMountPath, "--staging", PersistenceStagingPath}
+	for _, persistPath := range replacePod.PersistPaths {
+		volumePath := PersistentVolumePath(persistPath)
+		if volumePath == PersistenceStagingPath || strings.HasPrefix(volumePath, PersistenceStagingPath+"/") {
+			return fmt.Errorf("volume path %s of persisted path %s is reserved for DevSpace", volumePath, persistPath.Path)
+		}
+
+		populateArgs = append(populateArgs, path.Clean(persistPath.Path), volumePath)
+	}
+
+	claimName := encoding.SafeConcatName(pod.Annotations[ParentNameAnnotation], "devspace")
+	if replacePod.PersistenceOptions != nil && replacePod.PersistenceOptions.Name != "" {
+		claimName = replacePod.PersistenceOptions.Name
+	}
+
+	err := ensurePersistentVolumeClaim(ctx, client, pod, claimName, replacePod.PersistenceOptions, log)
+	if err != nil {
+		return err
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: PersistenceVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+		},
+	}, corev1.Volume{
+		Name: persistenceHelperVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	for _, persistPath := range replacePod.PersistPaths {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      PersistenceVolumeName,
+			MountPath: path.Clean(persistPath.Path),
+			SubPath:   PersistentVolumePath(persistPath),
+		})
+	}
+
+	// the devspace helper is copied from the helper image and then populates the claim from the image of the
+	// container, which neither needs a shell nor other tools in the image. Both init containers run with the
+	// security context of the container, so the copied paths belong to the user of the container
+	helperMount := corev1.VolumeMount{
+		Name:      persistenceHelperVolumeName,
+		MountPath: persistenceHelperMountPath,
+	}
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:            persistenceHelperContainerName,
+		Image:           persistenceHelperImage(replacePod.PersistenceOptions),
+		Command:         []string{persistenceHelperImagePath, "persist", "install", path.Join(persistenceHelperMountPath, "devspacehelper")},
+		SecurityContext: container.SecurityContext.DeepCopy(),
+		VolumeMounts:    []corev1.VolumeMount{helperMount},
+	}, corev1.Container{
+		Name:            persistenceInitContainerName,
+		Image:           container.Image,
+		ImagePullPolicy: container.ImagePullPolicy,
+		Command:         populateArgs,
+		SecurityContext: container.SecurityContext.DeepCopy(),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      PersistenceVolumeName,
+				MountPath: persistenceInitMountPath,
+			},
+			helperMount,
+		},
+	})
+
+	return nil
+}
+
+// persistenceHelperImage returns the configured helper image or the default helper image of this DevSpace version
+func persistenceHelperImage(options *latest.PersistenceOptions) string {
+	if options != nil && options.HelperImage != "" {
+		return options.HelperImage
+	}
+
+	version := upgrade.GetRawVersion()
+	if version == "" {
+		version = "latest"
+	}
+
+	return DefaultPersistenceHelperImage + ":" + version
+}
+
+// ensurePersistentVolumeClaim creates the persistent volume claim if it does not exist yet. Claims that are
+// created by DevSpace are labeled, so that they are deleted when the replaced pod is reset
+func ensurePersistentVolumeClaim(ctx context.Context, client kubectl.Client, pod *corev1.Pod, claimName string, options *latest.PersistenceOptions, log log.Logger) error {
+	_, err := client.KubeClient().CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claimName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	} else if kerrors.IsNotFound(err) == false {
+		return errors.Wrap(err, "get persistent volume claim")
+	}
+
+	if options == nil {
+		options = &latest.PersistenceOptions{}
+	}
+
+	size := DefaultPersistenceSize
+	if options.Size != "" {
+		size = options.Size
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return errors.Wrapf(err, "parse persistent volume claim size %s", size)
+	}
+
+	accessModes := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	if len(options.AccessModes) > 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{}
+		for _, accessMode := range options.AccessModes {
+			accessModes = append(accessModes, corev1.PersistentVolumeAccessMode(accessMode))
+		}
+	}
+
+	var storageClassName *string
+	if options.StorageClassName != "" {
+		storageClassName = &options.StorageClassName
+	}
+
+	_, err = client.KubeClient().CoreV1().PersistentVolumeClaims(pod.Namespace).Create(ctx, &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      claimName,
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				kubectl.ReplacedLabel: "true",
+			},
+			Annotations: map[string]string{
+				ParentKindAnnotation: pod.Annotations[ParentKindAnnotation],
+				ParentNameAnnotation: pod.Annotations[ParentNameAnnotation],
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: accessModes,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: quantity,
+				},
+			},
+			StorageClassName: storageClassName,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "create persistent volume claim")
+	}
+
+	log.Donef("Created persistent volume claim %s/%s", pod.Namespace, claimName)
+	return nil
+}
+
+// deletePersistentVolumeClaims deletes the persistent volume claims of the replaced pod that were created by DevSpace
+func deletePersistentVolumeClaims(ctx context.Context, client kubectl.Client, pod *corev1.Pod, log log.Logger) error {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name != PersistenceVolumeName || volume.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		claim, err := client.KubeClient().CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+
+			return errors.Wrap(err, "get persistent volume claim")
+		} else if claim.Labels == nil || claim.Labels[kubectl.ReplacedLabel] != "true" {
+			continue
+		}
+
+		err = client.KubeClient().CoreV1().PersistentVolumeClaims(pod.Namespace).Delete(ctx, claim.Name, metav1.DeleteOptions{})
+		if err != nil && kerrors.IsNotFound(err) == false {
+			return errors.Wrap(err, "delete persistent volume claim")
+		}
+
+		log.Donef("Deleted persistent volume claim %s/%s", claim.Namespace, claim.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package podreplace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPersistPaths(t *testing.T) {
+	kube := fake.NewSimpleClientset(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "existing",
+			Namespace: "test",
+		},
+	})
+	client := &fakekube.Client{Client: kube}
+	user := int64(1000)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-devspace",
+			Namespace: "test",
+			Annotations: map[string]string{
+				ParentKindAnnotation: "Deployment",
+				ParentNameAnnotation: "app",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "sidecar", Image: "sidecar"},
+				{Name: "app", Image: "node", SecurityContext: &corev1.SecurityContext{RunAsUser: &user}},
+			},
+		},
+	}
+	replacePod := &latest.ReplacePod{
+		PersistPaths: []latest.PersistentPath{
+			{Path: "/app/node_modules/"},
+			{Path: "/root", VolumePath: "home"},
+		},
+		PersistenceOptions: &latest.PersistenceOptions{
+			Size: "1Gi",
+		},
+	}
+
+	err := persistPaths(context.TODO(), client, pod, "app", replacePod, log.Discard)
+	assert.NilError(t, err)
+
+	// the claim is created with the configured size
+	claim, err := kube.CoreV1().PersistentVolumeClaims("test").Get(context.TODO(), "app-devspace", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, claim.Labels[kubectl.ReplacedLabel], "true")
+	size := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, size.String(), "1Gi")
+	assert.DeepEqual(t, claim.Spec.AccessModes, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce})
+
+	// only the selected container mounts the paths
+	assert.Equal(t, len(pod.Spec.Volumes), 2)
+	assert.Equal(t, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName, "app-devspace")
+	assert.Assert(t, pod.Spec.Volumes[1].EmptyDir != nil)
+	assert.Equal(t, len(pod.Spec.Containers[0].VolumeMounts), 0)
+	assert.DeepEqual(t, pod.Spec.Containers[1].VolumeMounts, []corev1.VolumeMount{
+		{Name: PersistenceVolumeName, MountPath: "/app/node_modules", SubPath: "app/node_modules"},
+		{Name: PersistenceVolumeName, MountPath: "/root", SubPath: "home"},
+	})
+
+	// the helper is copied from the helper image and populates the claim from the image of the container with
+	// the security context of the container
+	assert.Equal(t, len(pod.Spec.InitContainers), 2)
+	assert.Equal(t, pod.Spec.InitContainers[0].Image, DefaultPersistenceHelperImage+":latest")
+	assert.DeepEqual(t, pod.Spec.InitContainers[0].Command, []string{"/devspacehelper", "persist", "install", "/devspace-helper/devspacehelper"})
+	assert.Equal(t, pod.Spec.InitContainers[1].Image, "node")
+	assert.DeepEqual(t, pod.Spec.InitContainers[1].Command, []string{
+		"/devspace-helper/devspacehelper", "persist", "populate", "--volume", "/devspace-persistence", "--staging", ".devspace-staging",
+		"/app/node_modules", "app/node_modules",
+		"/root", "home",
+	})
+	for _, initContainer := range pod.Spec.InitContainers {
+		assert.DeepEqual(t, initContainer.SecurityContext, pod.Spec.Containers[1].SecurityContext)
+	}
+
+	// an existing claim is used as is and not deleted
+	replacePod.PersistenceOptions.Name = "existing"
+	existingPod := &corev1.Pod{
+		ObjectMeta: pod.ObjectMeta,
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "node"}},
+		},
+	}
+	err = persistPaths(context.TODO(), client, existingPod, "app", replacePod, log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, existingPod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName, "existing")
+	assert.Assert(t, existingPod.Spec.InitContainers[1].SecurityContext == nil)
+
+	err = deletePersistentVolumeClaims(context.TODO(), client, existingPod, log.Discard)
+	assert.NilError(t, err)
+	_, err = kube.CoreV1().PersistentVolumeClaims("test").Get(context.TODO(), "existing", metav1.GetOptions{})
+	assert.NilError(t, err)
+
+	// the created claim is deleted
+	err = deletePersistentVolumeClaims(context.TODO(), client, pod, log.Discard)
+	assert.NilError(t, err)
+	_, err = kube.CoreV1().PersistentVolumeClaims("test").Get(context.TODO(), "app-devspace", metav1.GetOptions{})
+	assert.Assert(t, kerrors.IsNotFound(err))
+
+	// a configured helper image is used and the staging path is reserved
+	replacePod.PersistenceOptions.HelperImage = "registry.local/devspacehelper:v1"
+	helperPod := &corev1.Pod{
+		ObjectMeta: pod.ObjectMeta,
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "node"}},
+		},
+	}
+	err = persistPaths(context.TODO(), client, helperPod, "app", replacePod, log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, helperPod.Spec.InitContainers[0].Image, "registry.local/devspacehelper:v1")
+
+	replacePod.PersistPaths = []latest.PersistentPath{{Path: "/app", VolumePath: ".devspace-staging/app"}}
+	err = persistPaths(context.TODO(), client, helperPod, "app", replacePod, log.Discard)
+	assert.ErrorContains(t, err, "reserved")
+}
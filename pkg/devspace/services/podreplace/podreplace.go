@@ -20,6 +20,8 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -27,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -37,6 +40,7 @@ const (
 	ReplaceConfigHashAnnotation = "devspace.sh/config-hash"
 
 	ReplicasAnnotation = "devspace.sh/replicas"
+	SuspendAnnotation  = "devspace.sh/suspend"
 )
 
 type PodReplacer interface {
@@ -67,17 +71,17 @@ func (p *replacer) RevertReplacePod(ctx context.Context, client kubectl.Client,
 	}
 
 	if selectedPod.Pod.Annotations == nil || selectedPod.Pod.Annotations[ParentKindAnnotation] == "" || selectedPod.Pod.Annotations[ParentNameAnnotation] == "" {
-		return selectedPod, deleteAndWait(ctx, client, selectedPod.Pod, log)
+		return selectedPod, deleteWithPersistence(ctx, client, selectedPod.Pod, log)
 	}
 
 	parent, err := getParentFromReplaced(ctx, client, selectedPod.Pod)
 	if err != nil {
 		log.Infof("Error getting Parent of replaced Pod %s/%s: %v", selectedPod.Pod.Namespace, selectedPod.Pod.Name, err)
-		return selectedPod, deleteAndWait(ctx, client, selectedPod.Pod, log)
+		return selectedPod, deleteWithPersistence(ctx, client, selectedPod.Pod, log)
 	}
 
 	// delete replaced pod
-	err = deleteAndWait(ctx, client, selectedPod.Pod, log)
+	err = deleteWithPersistence(ctx, client, selectedPod.Pod, log)
 	if err != nil {
 		return nil, errors.Wrap(err, "delete replaced pod")
 	}
@@ -189,6 +193,10 @@ func getParentFromReplaced(ctx context.Context, client kubectl.Client, pod *core
 		parent, err = client.KubeClient().AppsV1().Deployments(pod.Namespace).Get(ctx, pod.Annotations[ParentNameAnnotation], metav1.GetOptions{})
 	case "StatefulSet":
 		parent, err = client.KubeClient().AppsV1().StatefulSets(pod.Namespace).Get(ctx, pod.Annotations[ParentNameAnnotation], metav1.GetOptions{})
+	case "Job":
+		parent, err = client.KubeClient().BatchV1().Jobs(pod.Namespace).Get(ctx, pod.Annotations[ParentNameAnnotation], metav1.GetOptions{})
+	case "CronJob":
+		parent, err = client.KubeClient().BatchV1beta1().CronJobs(pod.Namespace).Get(ctx, pod.Annotations[ParentNameAnnotation], metav1.GetOptions{})
 	default:
 		return nil, fmt.Errorf("unrecognized parent kind")
 	}
@@ -196,6 +204,11 @@ func getParentFromReplaced(ctx context.Context, client kubectl.Client, pod *core
 }
 
 func scaleUpParent(ctx context.Context, client kubectl.Client, parent runtime.Object) error {
+	// cron jobs are suspended instead of scaled down
+	if cronJob, ok := parent.(*batchv1beta1.CronJob); ok {
+		return resumeCronJob(ctx, client, cronJob)
+	}
+
 	clonedParent := parent.DeepCopyObject()
 	metaParent, err := meta.Accessor(parent)
 	if err != nil {
@@ -224,6 +237,8 @@ func scaleUpParent(ctx context.Context, client kubectl.Client, parent runtime.Ob
 		t.Spec.Replicas = &oldReplica32
 	case *appsv1.StatefulSet:
 		t.Spec.Replicas = &oldReplica32
+	case *batchv1.Job:
+		t.Spec.Parallelism = &oldReplica32
 	}
 
 	// delete replicas annotation
@@ -245,6 +260,8 @@ func scaleUpParent(ctx context.Context, client kubectl.Client, parent runtime.Ob
 		_, err = client.KubeClient().AppsV1().Deployments(t.Namespace).Patch(ctx, t.Name, patch.Type(), bytes, metav1.PatchOptions{})
 	case *appsv1.StatefulSet:
 		_, err = client.KubeClient().AppsV1().StatefulSets(t.Namespace).Patch(ctx, t.Name, patch.Type(), bytes, metav1.PatchOptions{})
+	case *batchv1.Job:
+		_, err = client.KubeClient().BatchV1().Jobs(t.Namespace).Patch(ctx, t.Name, patch.Type(), bytes, metav1.PatchOptions{})
 	}
 	if err != nil {
 		return errors.Wrap(err, "patch parent")
@@ -253,6 +270,16 @@ func scaleUpParent(ctx context.Context, client kubectl.Client, parent runtime.Ob
 	return nil
 }
 
+// deleteWithPersistence deletes the replaced pod and afterwards the persistent volume claims of its persisted paths
+func deleteWithPersistence(ctx context.Context, client kubectl.Client, pod *corev1.Pod, log log.Logger) error {
+	err := deleteAndWait(ctx, client, pod, log)
+	if err != nil {
+		return err
+	}
+
+	return deletePersistentVolumeClaims(ctx, client, pod, log)
+}
+
 func deleteAndWait(ctx context.Context, client kubectl.Client, pod *corev1.Pod, log log.Logger) error {
 	log.StartWait(fmt.Sprintf("Waiting for replaced pod " + pod.Namespace + "/" + pod.Name + " to get terminated..."))
 	err := client.KubeClient().CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
@@ -298,14 +325,29 @@ func replace(ctx context.Context, client kubectl.Client, pod *kubectl.SelectedPo
 
 	copiedPod := pod.Pod.DeepCopyObject().(*corev1.Pod)
 
-	// replace the image name
+	// pods of jobs would terminate after their work is done
+	switch parent.(type) {
+	case *batchv1.Job, *batchv1beta1.CronJob:
+		makeLongLived(&copiedPod.Spec)
+	}
+
+	// replace the image name first, so that the configured containers cannot select the same container
+	replacedIndex := -1
 	if replacePod.ReplaceImage != "" {
-		err := replaceImageInPodSpec(&copiedPod.Spec, config, dependencies, replacePod)
+		replacedIndex, err = replaceImageInPodSpec(&copiedPod.Spec, config, dependencies, replacePod)
 		if err != nil {
 			return err
 		}
 	}
 
+	// replace the configured containers
+	if len(replacePod.Containers) > 0 {
+		err := replaceContainersInPodSpec(&copiedPod.Spec, config, dependencies, replacePod, replacedIndex)
+		if err != nil {
+			return errors.Wrap(err, "replace containers")
+		}
+	}
+
 	// apply the patches
 	copiedPod, err = applyPodPatches(copiedPod, replacePod)
 	if err != nil {
@@ -330,6 +372,8 @@ func replace(ctx context.Context, client kubectl.Client, pod *kubectl.SelectedPo
 	delete(copiedPod.Labels, "pod-template-hash")
 	delete(copiedPod.Labels, "controller-revision-hash")
 	delete(copiedPod.Labels, "statefulset.kubernetes.io/pod-name")
+	delete(copiedPod.Labels, "controller-uid")
+	delete(copiedPod.Labels, "job-name")
 
 	copiedPod.Labels[kubectl.ReplacedLabel] = "true"
 	if replacePod.ImageName != "" {
@@ -353,10 +397,24 @@ func replace(ctx context.Context, client kubectl.Client, pod *kubectl.SelectedPo
 	case *appsv1.StatefulSet:
 		copiedPod.Annotations[ParentNameAnnotation] = t.Name
 		copiedPod.Annotations[ParentKindAnnotation] = "StatefulSet"
+	case *batchv1.Job:
+		copiedPod.Annotations[ParentNameAnnotation] = t.Name
+		copiedPod.Annotations[ParentKindAnnotation] = "Job"
+	case *batchv1beta1.CronJob:
+		copiedPod.Annotations[ParentNameAnnotation] = t.Name
+		copiedPod.Annotations[ParentKindAnnotation] = "CronJob"
 	default:
 		return fmt.Errorf("unrecognized object")
 	}
 
+	// mount the persisted paths from a persistent volume claim
+	if len(replacePod.PersistPaths) > 0 {
+		err = persistPaths(ctx, client, copiedPod, pod.Container.Name, replacePod, log)
+		if err != nil {
+			return errors.Wrap(err, "persist paths")
+		}
+	}
+
 	// scale down parent
 	err = scaleDownParent(ctx, client, parent)
 	if err != nil {
@@ -438,13 +496,27 @@ func hashParentPodSpec(obj runtime.Object, config config.Config, dependencies []
 		podSpec = &t.Spec.Template
 	case *appsv1.StatefulSet:
 		podSpec = &t.Spec.Template
+	case *batchv1.Job:
+		podSpec = &t.Spec.Template
+	case *batchv1beta1.CronJob:
+		podSpec = &t.Spec.JobTemplate.Spec.Template
 	default:
 		return "", fmt.Errorf("unrecognized object")
 	}
 
 	// replace the image name
+	replacedIndex := -1
 	if replacePod.ReplaceImage != "" {
-		err := replaceImageInPodSpec(&podSpec.Spec, config, dependencies, replacePod)
+		var err error
+		replacedIndex, err = replaceImageInPodSpec(&podSpec.Spec, config, dependencies, replacePod)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// replace the configured containers, so that changed images of them are detected as well
+	if len(replacePod.Containers) > 0 {
+		err := replaceContainersInPodSpec(&podSpec.Spec, config, dependencies, replacePod, replacedIndex)
 		if err != nil {
 			return "", err
 		}
@@ -458,29 +530,31 @@ func hashParentPodSpec(obj runtime.Object, config config.Config, dependencies []
 	return hash.String(string(out)), nil
 }
 
-func replaceImageInPodSpec(podSpec *corev1.PodSpec, config config.Config, dependencies []dependencytypes.Dependency, replacePod *latest.ReplacePod) error {
+// replaceImageInPodSpec replaces the image of the container that is selected by the replace pod config and returns
+// its index or -1 if no container was selected
+func replaceImageInPodSpec(podSpec *corev1.PodSpec, config config.Config, dependencies []dependencytypes.Dependency, replacePod *latest.ReplacePod) (int, error) {
 	imageStr, err := util.ResolveImage(replacePod.ReplaceImage, config, dependencies)
 	if err != nil {
-		return err
+		return -1, err
 	}
 
 	// either replace by labelSelector & containerName
 	// or by resolved image name
 	if replacePod.LabelSelector != nil {
 		if len(podSpec.Containers) > 1 && replacePod.ContainerName == "" {
-			return fmt.Errorf("pod spec has more than 1 containers and containerName is an empty string")
+			return -1, fmt.Errorf("pod spec has more than 1 containers and containerName is an empty string")
 		} else if len(podSpec.Containers) == 0 {
-			return fmt.Errorf("no containers in pod spec")
+			return -1, fmt.Errorf("no containers in pod spec")
 		}
 
 		// exchange image name
 		for i := range podSpec.Containers {
 			if len(podSpec.Containers) == 1 {
 				podSpec.Containers[i].Image = imageStr
-				break
+				return i, nil
 			} else if podSpec.Containers[i].Name == replacePod.ContainerName {
 				podSpec.Containers[i].Image = imageStr
-				break
+				return i, nil
 			}
 		}
 	} else if replacePod.ImageName != "" || replacePod.ImageSelector != "" {
@@ -488,14 +562,14 @@ func replaceImageInPodSpec(podSpec *corev1.PodSpec, config config.Config, depend
 		if replacePod.ImageName != "" {
 			imageSelector, err = imageselector.Resolve(replacePod.ImageName, config, dependencies)
 			if err != nil {
-				return err
+				return -1, err
 			} else if imageSelector == nil {
-				return fmt.Errorf("cannot find image name: %#+v", replacePod.ImageName)
+				return -1, fmt.Errorf("cannot find image name: %#+v", replacePod.ImageName)
 			}
 		} else if replacePod.ImageSelector != "" {
 			imageSelector, err = util.ResolveImageAsImageSelector(replacePod.ImageSelector, config, dependencies)
 			if err != nil {
-				return err
+				return -1, err
 			}
 		}
 
@@ -503,15 +577,15 @@ func replaceImageInPodSpec(podSpec *corev1.PodSpec, config config.Config, depend
 		for i := range podSpec.Containers {
 			if len(podSpec.Containers) == 1 {
 				podSpec.Containers[i].Image = imageStr
-				break
+				return i, nil
 			} else if imageselector.CompareImageNames(*imageSelector, podSpec.Containers[i].Image) {
 				podSpec.Containers[i].Image = imageStr
-				break
+				return i, nil
 			}
 		}
 	}
 
-	return nil
+	return -1, nil
 }
 
 func scaleDownParent(ctx context.Context, client kubectl.Client, obj runtime.Object) error {
@@ -603,6 +677,37 @@ func scaleDownParent(ctx context.Context, client kubectl.Client, obj runtime.Obj
 		}
 
 		return nil
+	case *batchv1.Job:
+		if t.Annotations == nil {
+			t.Annotations = map[string]string{}
+		}
+
+		// a job with a parallelism of 0 is paused
+		parallelism := 1
+		if t.Spec.Parallelism != nil {
+			parallelism = int(*t.Spec.Parallelism)
+		}
+
+		if parallelism == 0 {
+			return nil
+		}
+
+		t.Annotations[ReplicasAnnotation] = strconv.Itoa(parallelism)
+		t.Spec.Parallelism = ptr.Int32(0)
+		patch := MergeFrom(cloned)
+		bytes, err := patch.Data(t)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.KubeClient().BatchV1().Jobs(t.Namespace).Patch(ctx, t.Name, patch.Type(), bytes, metav1.PatchOptions{})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	case *batchv1beta1.CronJob:
+		return suspendCronJob(ctx, client, t)
 	}
 
 	return fmt.Errorf("unrecognized object")
@@ -622,7 +727,7 @@ func convertFromInterface(inter map[interface{}]interface{}) []byte {
 	return retOut
 }
 
-func convertToInterface(str runtime.Object) map[interface{}]interface{} {
+func convertToInterface(str interface{}) map[interface{}]interface{} {
 	out, err := json.Marshal(str)
 	if err != nil {
 		panic(err)
@@ -696,23 +801,59 @@ func findSingleReplaceablePodParent(ctx context.Context, client kubectl.Client,
 		targetOptions.ImageSelector = append(targetOptions.ImageSelector, *imageSelector)
 	}
 
+	// cron jobs are replaced from their job template, because they usually have no running pod
+	cronJobContainer, cronJob, err := findReplaceableCronJob(ctx, client, replacePod, targetOptions.ImageSelector)
+	if err != nil {
+		return nil, nil, err
+	} else if cronJob == nil {
+		container, err := targetselector.NewTargetSelector(client).SelectSingleContainer(ctx, targetOptions, log)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		parent, err := getParent(ctx, client, container.Pod)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "get pod parent")
+		}
+
+		return container, parent, nil
+	}
+
+	// the cron job is only replaced if no other workload matches, even if its pods are not created yet
+	cronJobName := cronJob.(*batchv1beta1.CronJob).Name
+	workloads, err := findReplaceableWorkloads(ctx, client, cronJob.(*batchv1beta1.CronJob).Namespace, replacePod, targetOptions.ImageSelector)
+	if err != nil {
+		return nil, nil, err
+	} else if len(workloads) > 0 {
+		return nil, nil, fmt.Errorf("found multiple workloads to replace: CronJob %s and %s, please use a more specific selector", cronJobName, strings.Join(workloads, ", "))
+	}
+
+	// other matching pods must belong to the cron job, e.g. if one of its jobs is running
+	targetOptions.Wait = ptr.Bool(false)
+	targetOptions.WaitingStrategy = targetselector.NewUntilNotTerminatingStrategy(0)
 	container, err := targetselector.NewTargetSelector(client).SelectSingleContainer(ctx, targetOptions, log)
 	if err != nil {
+		if _, ok := err.(*targetselector.NotFoundErr); ok {
+			return cronJobContainer, cronJob, nil
+		}
+
 		return nil, nil, err
 	}
 
 	parent, err := getParent(ctx, client, container.Pod)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "get pod parent")
+	} else if parentCronJob, ok := parent.(*batchv1beta1.CronJob); !ok || parentCronJob.Name != cronJobName {
+		return nil, nil, fmt.Errorf("found multiple workloads to replace: CronJob %s and pod %s, please use a more specific selector", cronJobName, container.Pod.Name)
 	}
 
-	return container, parent, nil
+	return cronJobContainer, cronJob, nil
 }
 
 func getParent(ctx context.Context, client kubectl.Client, pod *corev1.Pod) (runtime.Object, error) {
 	controller := metav1.GetControllerOf(pod)
 	if controller == nil {
-		return nil, fmt.Errorf("pod was not created by a ReplicaSet, Deployment or StatefulSet, patching only works if pod was created by one of those resources")
+		return nil, fmt.Errorf("pod was not created by a ReplicaSet, Deployment, StatefulSet, Job or CronJob, patching only works if pod was created by one of those resources")
 	}
 
 	// replica set / deployment ?
@@ -764,5 +905,10 @@ func getParent(ctx context.Context, client kubectl.Client, pod *corev1.Pod) (run
 		return statefulSet, nil
 	}
 
+	// job / cron job?
+	if controller.Kind == "Job" {
+		return getJobParent(ctx, client, pod.Namespace, controller.Name)
+	}
+
 	return nil, fmt.Errorf("unrecognized owner of Pod %s: %s %s %s", pod.Name, controller.Kind, controller.APIVersion, controller.Name)
 }
@@ -1,21 +1,20 @@
 package services
 
 import (
-	"context"
 	"fmt"
 	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
 	"github.com/loft-sh/devspace/pkg/util/imageselector"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl/portforward"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
-	"github.com/loft-sh/devspace/pkg/util/port"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // StartPortForwarding starts the port forwarding functionality
@@ -24,24 +23,96 @@ func (serviceClient *client) StartPortForwarding(interrupt chan error) error {
 		return fmt.Errorf("DevSpace config is not set")
 	}
 
+	// choose the local ports of port mappings with a fallback
+	err := serviceClient.resolveLocalPorts()
+	if err != nil {
+		return err
+	}
+
 	cache := serviceClient.config.Generated().GetActive()
-	for _, portForwarding := range serviceClient.config.Config().Dev.Ports {
+	for index, portForwarding := range serviceClient.config.Config().Dev.Ports {
 		if len(portForwarding.PortMappings) == 0 {
 			continue
 		}
 
 		// start port forwarding
-		err := serviceClient.startForwarding(cache, portForwarding, interrupt, serviceClient.log)
-		if err != nil {
-			return err
+		if len(filterPortMappings(portForwarding.PortMappings, latest.PortProtocolTCP)) > 0 {
+			err := serviceClient.startForwarding(cache, index, portForwarding, interrupt, serviceClient.log)
+			if err != nil {
+				return err
+			}
+		}
+
+		// udp ports are forwarded via the devspace helper
+		if len(filterPortMappings(portForwarding.PortMappings, latest.PortProtocolUDP)) > 0 {
+			err := serviceClient.startUDPForwarding(cache, index, portForwarding, interrupt, serviceClient.log)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-func (serviceClient *client) startForwarding(cache *generated.CacheConfig, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
-	var err error
+// filterPortMappings returns the port mappings with the given protocol, where tcp is the default
+func filterPortMappings(portMappings []*latest.PortMapping, protocol latest.PortProtocol) []*latest.PortMapping {
+	filtered := []*latest.PortMapping{}
+	for _, portMapping := range portMappings {
+		if portMapping.Protocol == protocol || (portMapping.Protocol == "" && protocol == latest.PortProtocolTCP) {
+			filtered = append(filtered, portMapping)
+		}
+	}
+
+	return filtered
+}
+
+// forwardedPorts returns the tcp port mappings as local and remote port pairs and their bind addresses
+func forwardedPorts(portMappings []*latest.PortMapping) ([]portforward.ForwardedPort, []string, error) {
+	portMappings = filterPortMappings(portMappings, latest.PortProtocolTCP)
+	ports := make([]portforward.ForwardedPort, len(portMappings))
+	addresses := make([]string, len(portMappings))
+	for index, value := range portMappings {
+		if value.LocalPort == nil {
+			return nil, nil, errors.Errorf("port is not defined in portmapping %d", index)
+		}
+
+		localPort := *value.LocalPort
+		remotePort := localPort
+		if value.RemotePort != nil {
+			remotePort = *value.RemotePort
+		}
+
+		ports[index] = portforward.ForwardedPort{
+			Local:  uint16(localPort),
+			Remote: uint16(remotePort),
+		}
+		if value.BindAddress == "" {
+			addresses[index] = "localhost"
+		} else {
+			addresses[index] = value.BindAddress
+		}
+	}
+
+	return ports, addresses, nil
+}
+
+// inspectedPorts returns the local ports whose http traffic is captured by the inspector
+func inspectedPorts(portMappings []*latest.PortMapping) map[uint16]bool {
+	inspected := map[uint16]bool{}
+	for _, portMapping := range portMappings {
+		if portMapping.Inspect && portMapping.LocalPort != nil {
+			inspected[uint16(*portMapping.LocalPort)] = true
+		}
+	}
+
+	return inspected
+}
+
+func (serviceClient *client) startForwarding(cache *generated.CacheConfig, index int, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
+	if portForwarding.ServiceName != "" {
+		return serviceClient.startServiceForwarding(index, portForwarding, interrupt, log)
+	}
 
 	// apply config & set image selector
 	options := targetselector.NewEmptyOptions().ApplyConfigParameter(portForwarding.LabelSelector, portForwarding.Namespace, "", "")
@@ -64,87 +135,112 @@ func (serviceClient *client) startForwarding(cache *generated.CacheConfig, portF
 	options.WaitingStrategy = targetselector.NewUntilNewestRunningWaitingStrategy(time.Second * 2)
 	options.SkipInitContainers = true
 
+	ports, addresses, err := forwardedPorts(portForwarding.PortMappings)
+	if err != nil {
+		return err
+	}
+
+	fileLog := logpkg.GetFileLogger("portforwarding")
+	supervisor := &portForwardingSupervisor{
+		client:         serviceClient.client,
+		options:        options,
+		ports:          ports,
+		addresses:      addresses,
+		inspected:      inspectedPorts(portForwarding.PortMappings),
+		status:         newPortForwardingStatusReporter(serviceClient.portForwardingStatus, index, latest.PortProtocolTCP, strings.Join(portStrings(ports), ", "), fileLog),
+		log:            serviceClient.log,
+		fileLog:        fileLog,
+		errorChan:      make(chan error, 10),
+		forwarderReady: make(chan struct{}),
+	}
+
+	// the local ports stay bound until the port forwarding is interrupted, even if the pod changes
+	err = supervisor.listen()
+	if err != nil {
+		supervisor.updateStatus(PortForwardingStateFailed, err)
+		return err
+	}
+
 	// start port forwarding
+	supervisor.updateStatus(PortForwardingStateConnecting, nil)
 	log.StartWait("Port-Forwarding: Waiting for containers to start...")
-	pod, err := targetselector.NewTargetSelector(serviceClient.client).SelectSinglePod(context.TODO(), options, log)
+	doneChan, err := supervisor.connect(log)
 	log.StopWait()
 	if err != nil {
+		// the status is reported before it is removed by close
+		supervisor.updateStatus(PortForwardingStateFailed, err)
+		supervisor.close()
 		return errors.Errorf("%s: %s", message.SelectorErrorPod, err.Error())
-	} else if pod == nil {
-		return nil
 	}
 
-	ports := make([]string, len(portForwarding.PortMappings))
-	addresses := make([]string, len(portForwarding.PortMappings))
-	for index, value := range portForwarding.PortMappings {
-		if value.LocalPort == nil {
-			return errors.Errorf("port is not defined in portmapping %d", index)
-		}
-
-		localPort := strconv.Itoa(*value.LocalPort)
-		remotePort := localPort
-		if value.RemotePort != nil {
-			remotePort = strconv.Itoa(*value.RemotePort)
-		}
+	log.Donef("Port forwarding started on %s (%s)", strings.Join(supervisor.portStrings(), ", "), supervisor.podName())
+	go supervisor.collectErrors(interrupt)
+	go supervisor.supervise(doneChan, interrupt)
+	return nil
+}
 
-		open, _ := port.Check(*value.LocalPort)
-		if open == false {
-			serviceClient.log.Warnf("Seems like port %d is already in use. Is another application using that port?", *value.LocalPort)
-		}
+// startServiceForwarding forwards the ports to the ready endpoints of a service
+func (serviceClient *client) startServiceForwarding(index int, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
+	ports, addresses, err := forwardedPorts(portForwarding.PortMappings)
+	if err != nil {
+		return err
+	}
 
-		ports[index] = localPort + ":" + remotePort
-		if value.BindAddress == "" {
-			addresses[index] = "localhost"
-		} else {
-			addresses[index] = value.BindAddress
-		}
+	namespace := portForwarding.Namespace
+	if namespace == "" {
+		namespace = serviceClient.client.Namespace()
 	}
 
-	readyChan := make(chan struct{})
-	errorChan := make(chan error)
+	fileLog := logpkg.GetFileLogger("portforwarding")
+	supervisor := &serviceForwardingSupervisor{
+		client:          serviceClient.client,
+		namespace:       namespace,
+		serviceName:     portForwarding.ServiceName,
+		ports:           ports,
+		addresses:       addresses,
+		status:          newPortForwardingStatusReporter(serviceClient.portForwardingStatus, index, latest.PortProtocolTCP, strings.Join(portStrings(ports), ", "), fileLog),
+		log:             serviceClient.log,
+		fileLog:         fileLog,
+		errorChan:       make(chan error, 10),
+		lostChan:        make(chan struct{}, 1),
+		forwarders:      map[string]*endpointForwarder{},
+		forwardersReady: make(chan struct{}),
+	}
 
-	pf, err := serviceClient.client.NewPortForwarder(pod, ports, addresses, make(chan struct{}), readyChan, errorChan)
+	// the local ports stay bound until the port forwarding is interrupted, even if the endpoints change
+	supervisor.listeners, err = listenOnPorts(ports, addresses, inspectedPorts(portForwarding.PortMappings), supervisor.handleConnection, serviceClient.log)
 	if err != nil {
-		return errors.Errorf("Error starting port forwarding: %v", err)
+		supervisor.updateStatus(PortForwardingStateFailed, 0, err)
+		return err
 	}
 
-	go func() {
-		err := pf.ForwardPorts()
-		if err != nil {
-			serviceClient.log.Fatalf("Error forwarding ports: %v", err)
-		}
-	}()
-
-	// Wait till forwarding is ready
-	select {
-	case <-readyChan:
-		log.Donef("Port forwarding started on %s (%s/%s)", strings.Join(ports, ", "), pod.Namespace, pod.Name)
-	case <-time.After(20 * time.Second):
-		return errors.Errorf("Timeout waiting for port forwarding to start")
+	// a service that does not exist or lacks the ports is a config error, while endpoints that are
+	// not ready yet are connected as soon as they are
+	supervisor.updateStatus(PortForwardingStateConnecting, 0, nil)
+	var watcher watch.Interface
+	_, err = supervisor.resolveEndpoints()
+	if err == nil {
+		watcher, err = supervisor.watchEndpoints()
+	}
+	if err != nil {
+		// the status is reported before it is removed by close
+		supervisor.updateStatus(PortForwardingStateFailed, 0, err)
+		supervisor.close()
+		return err
 	}
 
-	go func(portForwarding *latest.PortForwardingConfig, interrupt chan error) {
-		select {
-		case err := <-errorChan:
-			if err != nil {
-				pf.Close()
-				for {
-					err = serviceClient.startForwarding(cache, portForwarding, interrupt, logpkg.Discard)
-					if err != nil {
-						serviceClient.log.Errorf("Error restarting port-forwarding: %v", err)
-						serviceClient.log.Errorf("Will try again in 15 seconds")
-						time.Sleep(time.Second * 15)
-						continue
-					}
-
-					time.Sleep(time.Second * 3)
-					break
-				}
-			}
-		case <-interrupt:
-			pf.Close()
-		}
-	}(portForwarding, interrupt)
+	log.StartWait("Port-Forwarding: Waiting for endpoints of " + supervisor.String() + "...")
+	connected, err := supervisor.waitForEndpoints(watcher, portForwardingReadyTimeout)
+	log.StopWait()
+	if connected == 0 {
+		log.Warnf("Port-Forwarding: %v, will connect to %s as soon as it has ready endpoints", err, supervisor)
+		supervisor.updateStatus(PortForwardingStateReconnecting, 0, err)
+	} else {
+		log.Donef("Port forwarding started on %s (%s, %d endpoints)", strings.Join(portStrings(ports), ", "), supervisor, connected)
+		supervisor.updateStatus(PortForwardingStateConnected, connected, err)
+	}
 
+	go supervisor.collectErrors(interrupt)
+	go supervisor.supervise(watcher, connected > 0, err, interrupt)
 	return nil
 }
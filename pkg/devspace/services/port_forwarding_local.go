@@ -0,0 +1,200 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	portutil "github.com/loft-sh/devspace/pkg/util/port"
+	"github.com/pkg/errors"
+)
+
+// resolveLocalPorts chooses free local ports for auto ports and replaces the local ports of port mappings
+// with a fallback by a free port of the fallback if they are already in use. The local ports of named port
+// mappings are stored in the generated config, so that they can be used as DEVSPACE_PORT_<NAME> variables.
+// The port the config was loaded with is preferred, so that the variables match the forwarded ports
+func (serviceClient *client) resolveLocalPorts() error {
+	generatedConfig := serviceClient.config.Generated()
+	if generatedConfig.Vars == nil {
+		generatedConfig.Vars = map[string]string{}
+	}
+
+	changed := false
+	names := map[string]bool{}
+	for _, portForwarding := range serviceClient.config.Config().Dev.Ports {
+		for _, portMapping := range portForwarding.PortMappings {
+			lastPort := ""
+			if portMapping.Name != "" {
+				lastPort = serviceClient.lastLocalPort(variable.PortVariableName(portMapping.Name))
+			}
+
+			if portMapping.AutoLocalPort && portMapping.LocalPort == nil {
+				localPort, err := chooseAutoLocalPort(lastPort, portMapping.BindAddress)
+				if err != nil {
+					return errors.Wrapf(err, "port %s", portMapping.Name)
+				}
+
+				portMapping.LocalPort = &localPort
+			}
+			if portMapping.LocalPort == nil {
+				continue
+			}
+
+			if portMapping.PortFallback != "" {
+				localPort, err := chooseLocalPort(*portMapping.LocalPort, lastPort, portMapping.BindAddress, portMapping.PortFallback)
+				if err != nil {
+					return errors.Wrapf(err, "port %s", portMapping.Name)
+				}
+
+				if localPort != *portMapping.LocalPort {
+					serviceClient.log.Warnf("Local port %d is already in use, port %s is forwarded to local port %d instead", *portMapping.LocalPort, portMapping.Name, localPort)
+
+					// the remote port defaults to the configured local port
+					if portMapping.RemotePort == nil {
+						remotePort := *portMapping.LocalPort
+						portMapping.RemotePort = &remotePort
+					}
+
+					portMapping.LocalPort = &localPort
+				}
+			}
+
+			if portMapping.Name != "" {
+				name, value := variable.PortVariableName(portMapping.Name), strconv.Itoa(*portMapping.LocalPort)
+				names[name] = true
+				if generatedConfig.Vars[name] != value {
+					generatedConfig.Vars[name] = value
+					changed = true
+				}
+			}
+		}
+	}
+
+	// the ports of port mappings that were removed or renamed are outdated
+	for name := range generatedConfig.Vars {
+		if strings.HasPrefix(name, variable.PortVariablePrefix) && names[name] == false {
+			delete(generatedConfig.Vars, name)
+			changed = true
+		}
+	}
+
+	if changed {
+		err := generated.NewConfigLoader("").Save(generatedConfig)
+		if err != nil {
+			return errors.Wrap(err, "save generated config")
+		}
+	}
+
+	return nil
+}
+
+// lastLocalPort returns the port the variable of a named port mapping was resolved to when the config was
+// loaded or the port that was forwarded before
+func (serviceClient *client) lastLocalPort(name string) string {
+	if value, ok := serviceClient.config.Variables()[name]; ok {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return serviceClient.config.Generated().Vars[name]
+}
+
+// chooseAutoLocalPort returns the port that was chosen for an auto port before if it is still free and
+// otherwise a free port chosen by the operating system
+func chooseAutoLocalPort(lastPort string, bindAddress string) (int, error) {
+	if bindAddress == "" || bindAddress == "localhost" {
+		bindAddress = "127.0.0.1"
+	}
+
+	last, err := strconv.Atoi(lastPort)
+	if err == nil && localPortFree(bindAddress, last) {
+		return last, nil
+	}
+
+	localPort, err := portutil.FreeHostPort(bindAddress)
+	if err != nil {
+		return 0, errors.Errorf("error finding a free local port: %v", err)
+	}
+
+	return localPort, nil
+}
+
+// chooseLocalPort returns the local port if it is free and otherwise a free port of the fallback, which is
+// either auto or a port range. The port that was chosen before is preferred if it belongs to the fallback
+func chooseLocalPort(port int, lastPort string, bindAddress string, fallback string) (int, error) {
+	if bindAddress == "" || bindAddress == "localhost" {
+		bindAddress = "127.0.0.1"
+	}
+	if localPortFree(bindAddress, port) {
+		return port, nil
+	}
+
+	start, end, err := ParsePortFallback(fallback)
+	if err != nil {
+		return 0, err
+	}
+
+	last, err := strconv.Atoi(lastPort)
+	if err == nil && last != port && (start == 0 || (last >= start && last <= end)) && localPortFree(bindAddress, last) {
+		return last, nil
+	}
+
+	// let the operating system choose a free port
+	if start == 0 {
+		localPort, err := portutil.FreeHostPort(bindAddress)
+		if err != nil {
+			return 0, errors.Errorf("error finding a free local port: %v", err)
+		}
+
+		return localPort, nil
+	}
+
+	for candidate := start; candidate <= end; candidate++ {
+		if candidate != port && localPortFree(bindAddress, candidate) {
+			return candidate, nil
+		}
+	}
+
+	return 0, errors.Errorf("local port %d and all ports of the fallback %s are already in use", port, fallback)
+}
+
+// ParsePortFallback parses the port fallback of a port mapping, which is either auto or a port range
+// such as 8081-8090. For auto an empty range is returned
+func ParsePortFallback(fallback string) (int, int, error) {
+	if fallback == latest.PortFallbackAuto {
+		return 0, 0, nil
+	}
+
+	splitted := strings.Split(fallback, "-")
+	if len(splitted) != 2 {
+		return 0, 0, errors.Errorf("port fallback '%s' is neither %s nor a port range such as 8081-8090", fallback, latest.PortFallbackAuto)
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(splitted[0]))
+	if err != nil {
+		return 0, 0, errors.Errorf("port fallback '%s' has an invalid start port", fallback)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(splitted[1]))
+	if err != nil {
+		return 0, 0, errors.Errorf("port fallback '%s' has an invalid end port", fallback)
+	}
+	if start <= 0 || end > 65535 || start > end {
+		return 0, 0, errors.Errorf("port fallback '%s' is not a valid port range", fallback)
+	}
+
+	return start, end, nil
+}
+
+// localPortFree checks if the port can be bound on the address
+func localPortFree(address string, port int) bool {
+	listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+
+	_ = listener.Close()
+	return true
+}
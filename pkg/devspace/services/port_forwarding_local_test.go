@@ -0,0 +1,101 @@
+package services
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestChooseLocalPort(t *testing.T) {
+	used, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer used.Close()
+	usedPort := used.Addr().(*net.TCPAddr).Port
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	freePort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	// a free local port is kept
+	port, err := chooseLocalPort(freePort, "", "", "auto")
+	if err != nil {
+		t.Fatal(err)
+	} else if port != freePort {
+		t.Fatalf("Expected free port %d to be kept, got %d", freePort, port)
+	}
+
+	// auto lets the operating system choose
+	port, err = chooseLocalPort(usedPort, "", "localhost", "auto")
+	if err != nil {
+		t.Fatal(err)
+	} else if port == usedPort || port == 0 {
+		t.Fatalf("Expected another port than %d, got %d", usedPort, port)
+	}
+
+	// a range without free ports fails
+	port, err = chooseLocalPort(usedPort, "", "127.0.0.1", strconv.Itoa(usedPort)+"-"+strconv.Itoa(usedPort))
+	if err == nil {
+		t.Fatalf("Expected error for a range without free ports, got port %d", port)
+	}
+
+	// a free port of the range is chosen
+	port, err = chooseLocalPort(usedPort, "", "127.0.0.1", strconv.Itoa(freePort)+"-"+strconv.Itoa(freePort))
+	if err != nil {
+		t.Fatal(err)
+	} else if port != freePort {
+		t.Fatalf("Expected port %d of the range, got %d", freePort, port)
+	}
+
+	// the port chosen before is kept if it is free and belongs to the fallback
+	port, err = chooseLocalPort(usedPort, strconv.Itoa(freePort), "127.0.0.1", "auto")
+	if err != nil {
+		t.Fatal(err)
+	} else if port != freePort {
+		t.Fatalf("Expected last port %d to be kept, got %d", freePort, port)
+	}
+
+	// the port chosen before is not kept if it does not belong to the fallback
+	port, err = chooseLocalPort(usedPort, strconv.Itoa(freePort), "127.0.0.1", strconv.Itoa(usedPort)+"-"+strconv.Itoa(usedPort))
+	if err == nil {
+		t.Fatalf("Expected error for a range without free ports, got port %d", port)
+	}
+}
+
+func TestChooseAutoLocalPort(t *testing.T) {
+	used, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer used.Close()
+	usedPort := used.Addr().(*net.TCPAddr).Port
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	freePort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	// the port that was chosen before is kept if it is still free
+	port, err := chooseAutoLocalPort(strconv.Itoa(freePort), "")
+	if err != nil {
+		t.Fatal(err)
+	} else if port != freePort {
+		t.Fatalf("Expected last port %d to be kept, got %d", freePort, port)
+	}
+
+	// another port is chosen if the last port is in use or there is none
+	for _, lastPort := range []string{strconv.Itoa(usedPort), ""} {
+		port, err = chooseAutoLocalPort(lastPort, "localhost")
+		if err != nil {
+			t.Fatal(err)
+		} else if port == usedPort || port == 0 {
+			t.Fatalf("Expected a free port instead of %d, got %d", usedPort, port)
+		}
+	}
+}
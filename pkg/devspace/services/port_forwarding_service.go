@@ -0,0 +1,487 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl/portforward"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// serviceEndpoint is a ready pod behind a service
+type serviceEndpoint struct {
+	namespace string
+	pod       string
+
+	// ports maps the service ports to the container ports of the pod
+	ports map[uint16]uint16
+}
+
+func (e *serviceEndpoint) String() string {
+	return e.namespace + "/" + e.pod
+}
+
+// serviceEndpoints returns the ready pods of the service that serve all of the given service ports
+func serviceEndpoints(service *v1.Service, endpoints *v1.Endpoints, servicePorts []uint16) ([]*serviceEndpoint, error) {
+	portNames := map[uint16]string{}
+	for _, servicePort := range servicePorts {
+		found := false
+		for _, port := range service.Spec.Ports {
+			if port.Protocol != "" && port.Protocol != v1.ProtocolTCP {
+				continue
+			}
+			if uint16(port.Port) == servicePort {
+				portNames[servicePort] = port.Name
+				found = true
+				break
+			}
+		}
+		if found == false {
+			return nil, errors.Errorf("service %s/%s has no tcp port %d", service.Namespace, service.Name, servicePort)
+		}
+	}
+
+	endpointsByPod := map[string]*serviceEndpoint{}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+
+			namespace := address.TargetRef.Namespace
+			if namespace == "" {
+				namespace = endpoints.Namespace
+			}
+
+			key := namespace + "/" + address.TargetRef.Name
+			if endpointsByPod[key] == nil {
+				endpointsByPod[key] = &serviceEndpoint{
+					namespace: namespace,
+					pod:       address.TargetRef.Name,
+					ports:     map[uint16]uint16{},
+				}
+			}
+
+			for servicePort, name := range portNames {
+				for _, port := range subset.Ports {
+					if port.Name == name && (port.Protocol == "" || port.Protocol == v1.ProtocolTCP) {
+						endpointsByPod[key].ports[servicePort] = uint16(port.Port)
+					}
+				}
+			}
+		}
+	}
+
+	ready := []*serviceEndpoint{}
+	for _, endpoint := range endpointsByPod {
+		if len(endpoint.ports) == len(servicePorts) {
+			ready = append(ready, endpoint)
+		}
+	}
+
+	// a stable order spreads the connections evenly across the endpoints
+	sort.Slice(ready, func(i, j int) bool { return ready[i].String() < ready[j].String() })
+	return ready, nil
+}
+
+// endpointForwarder is the connection of a service port forwarding to a single endpoint
+type endpointForwarder struct {
+	endpoint  *serviceEndpoint
+	forwarder *portforward.PortForwarder
+	stopChan  chan struct{}
+	doneChan  chan error
+}
+
+// serviceForwardingSupervisor keeps the local listeners of a port forwarding to a service bound and
+// spreads the accepted connections across the ready endpoints of the service. The endpoints are
+// watched, so that new endpoints are connected and removed endpoints are disconnected
+type serviceForwardingSupervisor struct {
+	client      kubectl.Client
+	namespace   string
+	serviceName string
+	ports       []portforward.ForwardedPort
+	addresses   []string
+	status      *portForwardingStatusReporter
+	log         logpkg.Logger
+	fileLog     logpkg.Logger
+
+	listeners []net.Listener
+
+	// errorChan receives the errors of single forwarded connections
+	errorChan chan error
+
+	// lostChan is notified when the connection to an endpoint is lost
+	lostChan chan struct{}
+
+	forwardersMutex sync.Mutex
+	forwarders      map[string]*endpointForwarder
+	forwardersReady chan struct{}
+	nextForwarder   int
+}
+
+func (s *serviceForwardingSupervisor) String() string {
+	return "service " + s.namespace + "/" + s.serviceName
+}
+
+// servicePorts returns the forwarded ports of the service
+func (s *serviceForwardingSupervisor) servicePorts() []uint16 {
+	ports := make([]uint16, len(s.ports))
+	for index, port := range s.ports {
+		ports[index] = port.Remote
+	}
+
+	return ports
+}
+
+// resolveEndpoints returns the ready endpoints of the service
+func (s *serviceForwardingSupervisor) resolveEndpoints() ([]*serviceEndpoint, error) {
+	service, err := s.client.KubeClient().CoreV1().Services(s.namespace).Get(context.TODO(), s.serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "get service %s/%s", s.namespace, s.serviceName)
+	}
+
+	endpoints, err := s.client.KubeClient().CoreV1().Endpoints(s.namespace).Get(context.TODO(), s.serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "get endpoints of service %s/%s", s.namespace, s.serviceName)
+	}
+
+	return serviceEndpoints(service, endpoints, s.servicePorts())
+}
+
+// watchEndpoints watches the endpoints of the service for changes
+func (s *serviceForwardingSupervisor) watchEndpoints() (watch.Interface, error) {
+	watcher, err := s.client.KubeClient().CoreV1().Endpoints(s.namespace).Watch(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", s.serviceName).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "watch endpoints of service %s/%s", s.namespace, s.serviceName)
+	}
+
+	return watcher, nil
+}
+
+// handleConnection forwards the local connection to the next endpoint as soon as one is connected
+func (s *serviceForwardingSupervisor) handleConnection(conn net.Conn, port portforward.ForwardedPort) {
+	forwarder := s.waitForForwarder(connectionWaitTimeout)
+	if forwarder == nil {
+		s.fileLog.Infof("Close connection on port %d, because %s has no connected endpoint", port.Local, s)
+		_ = conn.Close()
+		return
+	}
+
+	forwarder.forwarder.HandleConnection(conn, portforward.ForwardedPort{
+		Local:  port.Local,
+		Remote: forwarder.endpoint.ports[port.Remote],
+	})
+}
+
+// waitForForwarder returns the next connected endpoint or waits for one until the timeout is reached
+func (s *serviceForwardingSupervisor) waitForForwarder(timeout time.Duration) *endpointForwarder {
+	deadline := time.After(timeout)
+	for {
+		s.forwardersMutex.Lock()
+		ready := s.forwardersReady
+		if len(s.forwarders) > 0 {
+			keys := make([]string, 0, len(s.forwarders))
+			for key := range s.forwarders {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			forwarder := s.forwarders[keys[s.nextForwarder%len(keys)]]
+			s.nextForwarder++
+			s.forwardersMutex.Unlock()
+			return forwarder
+		}
+		s.forwardersMutex.Unlock()
+
+		select {
+		case <-ready:
+		case <-deadline:
+			return nil
+		}
+	}
+}
+
+// connect connects a new port forwarder to the endpoint
+func (s *serviceForwardingSupervisor) connect(endpoint *serviceEndpoint) (*endpointForwarder, error) {
+	ports := make([]string, len(s.ports))
+	for index, port := range s.ports {
+		ports[index] = strconv.Itoa(int(port.Local)) + ":" + strconv.Itoa(int(endpoint.ports[port.Remote]))
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      endpoint.pod,
+			Namespace: endpoint.namespace,
+		},
+	}
+
+	forwarder, stopChan, connectionDone, err := startPortForwarder(s.client, pod, ports, s.addresses, s.errorChan)
+	if err != nil {
+		return nil, err
+	}
+
+	// a lost connection is handled right away instead of with the next change of the endpoints
+	doneChan := make(chan error, 1)
+	go func() {
+		doneChan <- <-connectionDone
+		select {
+		case s.lostChan <- struct{}{}:
+		default:
+		}
+	}()
+
+	return &endpointForwarder{
+		endpoint:  endpoint,
+		forwarder: forwarder,
+		stopChan:  stopChan,
+		doneChan:  doneChan,
+	}, nil
+}
+
+// update connects the new endpoints of the service and disconnects the endpoints that were removed or
+// lost their connection. It returns the number of connected endpoints
+func (s *serviceForwardingSupervisor) update() (int, error) {
+	endpoints, resolveErr := s.resolveEndpoints()
+	endpointsByKey := map[string]*serviceEndpoint{}
+	for _, endpoint := range endpoints {
+		endpointsByKey[endpoint.String()] = endpoint
+	}
+
+	// disconnect endpoints whose connection was lost or that are not ready anymore. If the endpoints
+	// could not be resolved, the connected endpoints are kept
+	var lastErr error
+	s.forwardersMutex.Lock()
+	for key, forwarder := range s.forwarders {
+		select {
+		case err := <-forwarder.doneChan:
+			lastErr = errors.Errorf("lost connection to %s: %v", key, err)
+			s.fileLog.Infof("Port forwarding %s: %v", s, lastErr)
+			delete(s.forwarders, key)
+			continue
+		default:
+		}
+
+		if resolveErr == nil && (endpointsByKey[key] == nil || reflect.DeepEqual(endpointsByKey[key].ports, forwarder.endpoint.ports) == false) {
+			s.fileLog.Infof("Port forwarding %s: disconnect endpoint %s", s, key)
+			close(forwarder.stopChan)
+			delete(s.forwarders, key)
+		}
+	}
+	s.resetReady()
+	connected := len(s.forwarders)
+	s.forwardersMutex.Unlock()
+	if resolveErr != nil {
+		return connected, resolveErr
+	}
+
+	// connect the new endpoints
+	connectErrors := make(chan error, len(endpoints))
+	waitGroup := sync.WaitGroup{}
+	for _, endpoint := range endpoints {
+		s.forwardersMutex.Lock()
+		connected := s.forwarders[endpoint.String()] != nil
+		s.forwardersMutex.Unlock()
+		if connected {
+			continue
+		}
+
+		waitGroup.Add(1)
+		go func(endpoint *serviceEndpoint) {
+			defer waitGroup.Done()
+
+			forwarder, err := s.connect(endpoint)
+			if err != nil {
+				connectErrors <- errors.Errorf("connect to %s: %v", endpoint, err)
+				return
+			}
+
+			s.fileLog.Infof("Port forwarding %s: connected endpoint %s", s, endpoint)
+			s.forwardersMutex.Lock()
+			defer s.forwardersMutex.Unlock()
+
+			if len(s.forwarders) == 0 {
+				close(s.forwardersReady)
+			}
+			s.forwarders[endpoint.String()] = forwarder
+		}(endpoint)
+	}
+	waitGroup.Wait()
+	close(connectErrors)
+	for err := range connectErrors {
+		lastErr = err
+	}
+
+	s.forwardersMutex.Lock()
+	defer s.forwardersMutex.Unlock()
+
+	if len(s.forwarders) == 0 {
+		s.resetReady()
+		if lastErr == nil {
+			lastErr = errors.Errorf("%s has no ready endpoints", s)
+		}
+	}
+
+	return len(s.forwarders), lastErr
+}
+
+// disconnect disconnects all endpoints
+func (s *serviceForwardingSupervisor) disconnect() {
+	s.forwardersMutex.Lock()
+	defer s.forwardersMutex.Unlock()
+
+	for key, forwarder := range s.forwarders {
+		close(forwarder.stopChan)
+		delete(s.forwarders, key)
+	}
+
+	s.resetReady()
+}
+
+// resetReady lets new connections wait for the next connected endpoint if there is none left,
+// s.forwardersMutex needs to be locked before this function is called
+func (s *serviceForwardingSupervisor) resetReady() {
+	if len(s.forwarders) > 0 {
+		return
+	}
+
+	select {
+	case <-s.forwardersReady:
+		s.forwardersReady = make(chan struct{})
+	default:
+	}
+}
+
+// updateStatus reports the state of the port forwarding with the number of connected endpoints
+func (s *serviceForwardingSupervisor) updateStatus(state PortForwardingState, connected int, err error) {
+	s.status.update(state, fmt.Sprintf("%s (%d endpoints)", s, connected), err)
+}
+
+// waitForEndpoints connects the endpoints of the service whenever they change until one is connected or
+// the timeout is reached. It returns the number of connected endpoints
+func (s *serviceForwardingSupervisor) waitForEndpoints(watcher watch.Interface, timeout time.Duration) (int, error) {
+	deadline := time.After(timeout)
+	connected, err := s.update()
+	for connected == 0 {
+		select {
+		case _, ok := <-watcher.ResultChan():
+			if ok == false {
+				return connected, err
+			}
+		case <-s.lostChan:
+		case <-deadline:
+			return connected, err
+		}
+
+		connected, err = s.update()
+	}
+
+	return connected, err
+}
+
+// supervise follows the endpoint changes of the service until interrupt is closed. Endpoints that could
+// not be connected are retried with backoff
+func (s *serviceForwardingSupervisor) supervise(watcher watch.Interface, wasConnected bool, lastErr error, interrupt chan error) {
+	defer s.close()
+	defer func() {
+		if watcher != nil {
+			watcher.Stop()
+		}
+	}()
+
+	backoff := minReconnectBackoff
+	for {
+		var retry <-chan time.Time
+		if lastErr != nil {
+			retry = time.After(backoff)
+			backoff = nextReconnectBackoff(backoff)
+		} else {
+			backoff = minReconnectBackoff
+		}
+
+		// a watch that was closed by the server is started again
+		var events <-chan watch.Event
+		if watcher != nil {
+			events = watcher.ResultChan()
+		} else if retry == nil {
+			retry = time.After(minReconnectBackoff)
+		}
+
+		select {
+		case _, ok := <-events:
+			if ok == false {
+				watcher.Stop()
+				watcher = nil
+			}
+		case <-s.lostChan:
+		case <-retry:
+		case <-interrupt:
+			return
+		}
+
+		if watcher == nil {
+			var err error
+			watcher, err = s.watchEndpoints()
+			if err != nil {
+				s.fileLog.Infof("Port forwarding %s: %v", s, err)
+			}
+		}
+
+		connected, err := s.update()
+		lastErr = err
+		if connected > 0 {
+			if wasConnected == false {
+				s.log.Donef("Port forwarding connected to %s on %s", s, strings.Join(portStrings(s.ports), ", "))
+			}
+
+			s.updateStatus(PortForwardingStateConnected, connected, err)
+		} else {
+			if wasConnected {
+				s.log.Warnf("Port-Forwarding: lost connection to all endpoints of %s: %v, reconnecting...", s, err)
+			}
+
+			s.updateStatus(PortForwardingStateReconnecting, connected, err)
+		}
+
+		wasConnected = connected > 0
+	}
+}
+
+// collectErrors records the errors of single forwarded connections, which do not break the port forwarding
+func (s *serviceForwardingSupervisor) collectErrors(interrupt chan error) {
+	collectForwardingErrors(s.errorChan, interrupt, func(err error) {
+		s.fileLog.Infof("Port forwarding %s: %v", s, err)
+		s.updateStatus("", s.connectedEndpoints(), err)
+	})
+}
+
+func (s *serviceForwardingSupervisor) connectedEndpoints() int {
+	s.forwardersMutex.Lock()
+	defer s.forwardersMutex.Unlock()
+
+	return len(s.forwarders)
+}
+
+// close disconnects all endpoints and closes the local listeners
+func (s *serviceForwardingSupervisor) close() {
+	s.disconnect()
+	for _, listener := range s.listeners {
+		_ = listener.Close()
+	}
+
+	s.status.remove()
+}
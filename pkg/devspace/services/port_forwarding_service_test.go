@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type serviceEndpointsTestCase struct {
+	name string
+
+	servicePorts []v1.ServicePort
+	subsets      []v1.EndpointSubset
+	ports        []uint16
+
+	expectedErr       string
+	expectedEndpoints map[string]map[uint16]uint16
+}
+
+func podAddress(name string) v1.EndpointAddress {
+	return v1.EndpointAddress{
+		IP: "10.0.0.1",
+		TargetRef: &v1.ObjectReference{
+			Kind:      "Pod",
+			Name:      name,
+			Namespace: "test",
+		},
+	}
+}
+
+func TestServiceEndpoints(t *testing.T) {
+	testCases := []serviceEndpointsTestCase{
+		{
+			name:         "Unnamed port",
+			servicePorts: []v1.ServicePort{{Port: 80}},
+			subsets: []v1.EndpointSubset{
+				{
+					Addresses:         []v1.EndpointAddress{podAddress("pod-b"), podAddress("pod-a")},
+					NotReadyAddresses: []v1.EndpointAddress{podAddress("pod-c")},
+					Ports:             []v1.EndpointPort{{Port: 8080}},
+				},
+			},
+			ports: []uint16{80},
+			expectedEndpoints: map[string]map[uint16]uint16{
+				"test/pod-a": {80: 8080},
+				"test/pod-b": {80: 8080},
+			},
+		},
+		{
+			name:         "Named ports in different subsets",
+			servicePorts: []v1.ServicePort{{Name: "http", Port: 80}, {Name: "metrics", Port: 9090}},
+			subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{podAddress("pod-a"), podAddress("pod-b")},
+					Ports:     []v1.EndpointPort{{Name: "http", Port: 3000}},
+				},
+				{
+					Addresses: []v1.EndpointAddress{podAddress("pod-a")},
+					Ports:     []v1.EndpointPort{{Name: "metrics", Port: 9100}},
+				},
+			},
+			ports: []uint16{80, 9090},
+			expectedEndpoints: map[string]map[uint16]uint16{
+				"test/pod-a": {80: 3000, 9090: 9100},
+			},
+		},
+		{
+			name:         "Addresses without pod",
+			servicePorts: []v1.ServicePort{{Port: 80}},
+			subsets: []v1.EndpointSubset{
+				{
+					Addresses: []v1.EndpointAddress{{IP: "10.0.0.2"}},
+					Ports:     []v1.EndpointPort{{Port: 80}},
+				},
+			},
+			ports:             []uint16{80},
+			expectedEndpoints: map[string]map[uint16]uint16{},
+		},
+		{
+			name:         "Missing service port",
+			servicePorts: []v1.ServicePort{{Port: 80}, {Port: 53, Protocol: v1.ProtocolUDP}},
+			ports:        []uint16{53},
+			expectedErr:  "service test/backend has no tcp port 53",
+		},
+	}
+
+	for _, testCase := range testCases {
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "test"},
+			Spec:       v1.ServiceSpec{Ports: testCase.servicePorts},
+		}
+		endpoints := &v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "test"},
+			Subsets:    testCase.subsets,
+		}
+
+		result, err := serviceEndpoints(service, endpoints, testCase.ports)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, "Wrong error in test case %s", testCase.name)
+			continue
+		}
+		assert.NilError(t, err, "Error in test case %s", testCase.name)
+
+		assert.Equal(t, len(testCase.expectedEndpoints), len(result), "Wrong number of endpoints in test case %s", testCase.name)
+		for index, endpoint := range result {
+			if index > 0 {
+				assert.Assert(t, result[index-1].String() < endpoint.String(), "Endpoints are not sorted in test case %s", testCase.name)
+			}
+
+			assert.DeepEqual(t, testCase.expectedEndpoints[endpoint.String()], endpoint.ports)
+		}
+	}
+}
+
+func TestWatchServiceEndpoints(t *testing.T) {
+	endpoints := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "test"}}
+	other := &v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"}}
+	kubeClient := fake.NewSimpleClientset(endpoints, other)
+	supervisor := &serviceForwardingSupervisor{
+		client:      &fakekube.Client{Client: kubeClient},
+		namespace:   "test",
+		serviceName: "backend",
+	}
+
+	watcher, err := supervisor.watchEndpoints()
+	assert.NilError(t, err)
+	defer watcher.Stop()
+
+	endpoints.Subsets = []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{podAddress("pod-a")}, Ports: []v1.EndpointPort{{Port: 8080}}}}
+	_, err = kubeClient.CoreV1().Endpoints("test").Update(context.TODO(), endpoints, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	select {
+	case event := <-watcher.ResultChan():
+		assert.Equal(t, watch.Modified, event.Type, "Wrong event type")
+		assert.Equal(t, "backend", event.Object.(*v1.Endpoints).Name, "Wrong endpoints")
+	case <-time.After(time.Second * 5):
+		t.Fatal("Change of the endpoints was not watched")
+	}
+}
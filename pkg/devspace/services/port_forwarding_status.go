@@ -0,0 +1,253 @@
+package services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/system"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// PortForwardingStatusFile is the file where a running devspace dev reports the state of its port forwardings
+var PortForwardingStatusFile = ".devspace/portforwarding/status.json"
+
+// PortForwardingState is the state of a single port forwarding
+type PortForwardingState string
+
+// List of port forwarding states
+const (
+	PortForwardingStateConnecting   PortForwardingState = "connecting"
+	PortForwardingStateConnected    PortForwardingState = "connected"
+	PortForwardingStateReconnecting PortForwardingState = "reconnecting"
+	PortForwardingStateFailed       PortForwardingState = "failed"
+)
+
+// PortForwardingStatus is the reported state of a port forwarding config of dev.ports
+type PortForwardingStatus struct {
+	Ports     string              `json:"ports"`
+	State     PortForwardingState `json:"state"`
+	Pod       string              `json:"pod,omitempty"`
+	LastError string              `json:"lastError,omitempty"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+
+	// PID is the process that reported the state, the state is outdated if this process is not running anymore
+	PID int `json:"pid,omitempty"`
+}
+
+// portForwardingStatusKey returns the key of the state of a port forwarding config, where the udp ports
+// of a config are forwarded and reported separately
+func portForwardingStatusKey(index int, protocol latest.PortProtocol) string {
+	if protocol == latest.PortProtocolUDP {
+		return strconv.Itoa(index) + "/udp"
+	}
+
+	return strconv.Itoa(index)
+}
+
+// LoadPortForwardingStatus loads the reported port forwarding states keyed by the index of the config in
+// dev.ports. The states of the tcp and udp ports of a config are merged. If there is no status file or the
+// process that wrote it is not running anymore, nil is returned
+func LoadPortForwardingStatus(path string) (map[int]*PortForwardingStatus, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	status := map[string]*PortForwardingStatus{}
+	err = json.Unmarshal(out, &status)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", path)
+	}
+
+	// the keys are sorted, so that the tcp state comes before the udp state of a config
+	keys := make([]string, 0, len(status))
+	for key := range status {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	statusByIndex := map[int]*PortForwardingStatus{}
+	for _, key := range keys {
+		index, err := strconv.Atoi(strings.TrimSuffix(key, "/udp"))
+		if err != nil {
+			return nil, errors.Errorf("parse %s: invalid port forwarding index %s", path, key)
+		}
+
+		// a devspace dev that was stopped without removing its status left it behind
+		value := status[key]
+		if value.PID != 0 && system.IsProcessAlive(value.PID) == false {
+			continue
+		}
+
+		statusByIndex[index] = mergePortForwardingStatus(statusByIndex[index], value)
+	}
+	if len(statusByIndex) == 0 {
+		return nil, nil
+	}
+
+	return statusByIndex, nil
+}
+
+// mergePortForwardingStatus merges the states of the tcp and udp ports of a config, where the config is
+// only reported as connected if both are connected
+func mergePortForwardingStatus(status *PortForwardingStatus, other *PortForwardingStatus) *PortForwardingStatus {
+	if status == nil {
+		return other
+	}
+
+	merged := *status
+	merged.Ports = status.Ports + ", " + other.Ports
+	if merged.State == PortForwardingStateConnected {
+		merged.State = other.State
+	}
+	if other.Pod != "" && other.Pod != merged.Pod {
+		if merged.Pod != "" {
+			merged.Pod += ", "
+		}
+		merged.Pod += other.Pod
+	}
+	if other.LastError != "" && (merged.LastError == "" || other.UpdatedAt.After(merged.UpdatedAt)) {
+		merged.LastError = other.LastError
+	}
+	if other.UpdatedAt.After(merged.UpdatedAt) {
+		merged.UpdatedAt = other.UpdatedAt
+	}
+
+	return &merged
+}
+
+// portForwardingStatusStore keeps the states of the port forwardings of this process and writes them
+// to the status file on every change
+type portForwardingStatusStore struct {
+	path string
+
+	statusMutex sync.Mutex
+	status      map[string]*PortForwardingStatus
+}
+
+func newPortForwardingStatusStore(path string) *portForwardingStatusStore {
+	return &portForwardingStatusStore{
+		path:   path,
+		status: map[string]*PortForwardingStatus{},
+	}
+}
+
+// update sets the state of the port forwarding with the given key. The state and last error are kept if
+// they are empty
+func (s *portForwardingStatusStore) update(key string, ports string, state PortForwardingState, pod string, err error) error {
+	s.statusMutex.Lock()
+	defer s.statusMutex.Unlock()
+
+	status := s.status[key]
+	if status == nil {
+		status = &PortForwardingStatus{PID: os.Getpid()}
+		s.status[key] = status
+	}
+
+	status.Ports = ports
+	if state != "" {
+		status.State = state
+	}
+	status.UpdatedAt = time.Now()
+	if pod != "" {
+		status.Pod = pod
+	}
+	if err != nil {
+		status.LastError = err.Error()
+	}
+
+	return s.write()
+}
+
+// remove removes the state of the port forwarding with the given key
+func (s *portForwardingStatusStore) remove(key string) error {
+	s.statusMutex.Lock()
+	defer s.statusMutex.Unlock()
+
+	delete(s.status, key)
+	if len(s.status) == 0 {
+		err := os.Remove(s.path)
+		if err != nil && os.IsNotExist(err) == false {
+			return err
+		}
+
+		return nil
+	}
+
+	return s.write()
+}
+
+// write writes the status file, s.statusMutex needs to be locked before this function is called
+func (s *portForwardingStatusStore) write() error {
+	out, err := json.MarshalIndent(s.status, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.path, out, 0666)
+}
+
+// portForwardingStatusReporter reports the state of the tcp or udp ports of a port forwarding config
+type portForwardingStatusReporter struct {
+	key     string
+	ports   string
+	store   *portForwardingStatusStore
+	fileLog logpkg.Logger
+
+	// removed is true after the port forwarding was closed, the state is not reported anymore then
+	removedMutex sync.Mutex
+	removed      bool
+}
+
+func newPortForwardingStatusReporter(store *portForwardingStatusStore, index int, protocol latest.PortProtocol, ports string, fileLog logpkg.Logger) *portForwardingStatusReporter {
+	return &portForwardingStatusReporter{
+		key:     portForwardingStatusKey(index, protocol),
+		ports:   ports,
+		store:   store,
+		fileLog: fileLog,
+	}
+}
+
+// update reports the state, an empty state only updates the last error
+func (r *portForwardingStatusReporter) update(state PortForwardingState, pod string, err error) {
+	r.removedMutex.Lock()
+	defer r.removedMutex.Unlock()
+	if r.removed {
+		return
+	}
+
+	statusErr := r.store.update(r.key, r.ports, state, pod, err)
+	if statusErr != nil {
+		r.fileLog.Infof("Error writing port forwarding status: %v", statusErr)
+	}
+}
+
+// remove removes the reported state
+func (r *portForwardingStatusReporter) remove() {
+	r.removedMutex.Lock()
+	defer r.removedMutex.Unlock()
+	r.removed = true
+
+	err := r.store.remove(r.key)
+	if err != nil {
+		r.fileLog.Infof("Error removing port forwarding status: %v", err)
+	}
+}
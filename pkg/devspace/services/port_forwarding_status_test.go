@@ -0,0 +1,181 @@
+package services
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func TestPortForwardingStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "portforwarding", "status.json")
+	status, err := LoadPortForwardingStatus(path)
+	assert.NilError(t, err)
+	assert.Assert(t, status == nil, "Status returned although there is no status file")
+
+	store := newPortForwardingStatusStore(path)
+	assert.NilError(t, store.update("0", "8080:80", PortForwardingStateConnected, "default/pod-a", nil))
+	assert.NilError(t, store.update("1", "3000:3000", PortForwardingStateConnecting, "", nil))
+	assert.NilError(t, store.update("0", "8080:80", PortForwardingStateReconnecting, "", errors.New("lost connection to pod")))
+	assert.NilError(t, store.update("0", "8080:80", "", "", errors.New("connection refused")))
+
+	status, err = LoadPortForwardingStatus(path)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(status), "Wrong number of port forwardings")
+	assert.Equal(t, PortForwardingStateReconnecting, status[0].State, "Empty state overwrote the state")
+	assert.Equal(t, "default/pod-a", status[0].Pod, "Empty pod overwrote the pod")
+	assert.Equal(t, "connection refused", status[0].LastError, "Wrong last error")
+	assert.Equal(t, PortForwardingStateConnecting, status[1].State, "Wrong state")
+	assert.Equal(t, "", status[1].LastError, "Wrong last error")
+
+	assert.NilError(t, store.remove("0"))
+	status, err = LoadPortForwardingStatus(path)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(status), "Removed port forwarding is still reported")
+
+	assert.NilError(t, store.remove("1"))
+	_, err = os.Stat(path)
+	assert.Assert(t, os.IsNotExist(err), "Status file was not removed after the last port forwarding was removed")
+
+	// the udp ports of a config are reported separately and merged when loaded
+	assert.NilError(t, store.update(portForwardingStatusKey(0, latest.PortProtocolTCP), "8080:80", PortForwardingStateConnected, "default/pod-a", nil))
+	assert.NilError(t, store.update(portForwardingStatusKey(0, latest.PortProtocolUDP), "53:53/udp", PortForwardingStateReconnecting, "default/pod-b", errors.New("connection lost")))
+	status, err = LoadPortForwardingStatus(path)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(status), "Wrong number of port forwardings")
+	assert.Equal(t, "8080:80, 53:53/udp", status[0].Ports, "Wrong merged ports")
+	assert.Equal(t, PortForwardingStateReconnecting, status[0].State, "Wrong merged state")
+	assert.Equal(t, "default/pod-a, default/pod-b", status[0].Pod, "Wrong merged pod")
+	assert.Equal(t, "connection lost", status[0].LastError, "Wrong merged last error")
+}
+
+func TestPortForwardingStatusReporterRemoved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "status.json")
+	reporter := newPortForwardingStatusReporter(newPortForwardingStatusStore(path), 0, latest.PortProtocolTCP, "8080:80", log.Discard)
+	reporter.update(PortForwardingStateConnecting, "", nil)
+	reporter.remove()
+
+	// a closed port forwarding does not report its state again
+	reporter.update(PortForwardingStateFailed, "", errors.New("no pod found"))
+	status, err := LoadPortForwardingStatus(path)
+	assert.NilError(t, err)
+	assert.Assert(t, status == nil, "Status of a closed port forwarding returned")
+}
+
+func TestStalePortForwardingStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a status that was written by a process that is not running anymore is ignored
+	path := filepath.Join(dir, "status.json")
+	err = ioutil.WriteFile(path, []byte(`{"0": {"ports": "8080:80", "state": "connected", "pid": 999999999}}`), 0666)
+	assert.NilError(t, err)
+
+	status, err := LoadPortForwardingStatus(path)
+	assert.NilError(t, err)
+	assert.Assert(t, status == nil, "Status of a stopped process returned")
+}
+
+func TestNextReconnectBackoff(t *testing.T) {
+	backoff := minReconnectBackoff
+	for i := 0; i < 3; i++ {
+		backoff = nextReconnectBackoff(backoff)
+	}
+	assert.Equal(t, time.Second*8, backoff, "Wrong backoff")
+
+	for i := 0; i < 10; i++ {
+		backoff = nextReconnectBackoff(backoff)
+	}
+	assert.Equal(t, maxReconnectBackoff, backoff, "Backoff exceeds the maximum")
+}
+
+func TestSuperviseReconnects(t *testing.T) {
+	doneChan := make(chan error, 1)
+	interrupt := make(chan error)
+	events := make(chan string, 10)
+	attempts := 0
+	go func() {
+		superviseReconnects(doneChan, interrupt, reconnectHandler{
+			connect: func() (<-chan error, error) {
+				attempts++
+				if attempts == 1 {
+					return nil, errors.New("pod not ready")
+				}
+
+				return make(chan error), nil
+			},
+			lost: func(err error) {
+				events <- "lost: " + err.Error()
+			},
+			retry: func(err error, backoff time.Duration) {
+				events <- "retry in " + backoff.String() + ": " + err.Error()
+			},
+			reconnected: func() {
+				events <- "reconnected"
+			},
+		})
+		close(events)
+	}()
+
+	doneChan <- errors.New("connection lost")
+	for _, expected := range []string{"lost: connection lost", "retry in 1s: pod not ready", "reconnected"} {
+		select {
+		case event := <-events:
+			assert.Equal(t, expected, event, "Unexpected reconnect event")
+		case <-time.After(time.Second * 5):
+			t.Fatalf("Timeout waiting for %s", expected)
+		}
+	}
+
+	close(interrupt)
+	_, ok := <-events
+	assert.Equal(t, false, ok, "Expected supervising to stop after the interrupt")
+}
+
+func TestCollectForwardingErrors(t *testing.T) {
+	errorChan := make(chan error, 10)
+	interrupt := make(chan error)
+	recorded := make(chan error, 10)
+	done := make(chan struct{})
+	go func() {
+		collectForwardingErrors(errorChan, interrupt, func(err error) { recorded <- err })
+		close(done)
+	}()
+
+	errorChan <- errors.New("connection refused")
+	select {
+	case err := <-recorded:
+		assert.Error(t, err, "connection refused")
+	case <-time.After(time.Second * 5):
+		t.Fatal("Error was not recorded")
+	}
+
+	// the collector stops after the interrupt although the error channel stays open
+	close(interrupt)
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Collector did not stop after the interrupt")
+	}
+}
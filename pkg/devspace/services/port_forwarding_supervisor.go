@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl/portforward"
+	"github.com/loft-sh/devspace/pkg/devspace/services/inspect"
+	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// minReconnectBackoff is the time to wait before the first attempt to reconnect a lost port forwarding
+	minReconnectBackoff = time.Second
+	// maxReconnectBackoff is the maximum time between two attempts to reconnect a port forwarding
+	maxReconnectBackoff = time.Second * 30
+	// portForwardingReadyTimeout is the time to wait for a port forwarding to become ready
+	portForwardingReadyTimeout = time.Second * 20
+	// connectionWaitTimeout is the time a local connection waits for a reconnecting port forwarding
+	connectionWaitTimeout = time.Second * 30
+)
+
+// nextReconnectBackoff doubles the backoff until the maximum backoff is reached
+func nextReconnectBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxReconnectBackoff {
+		return maxReconnectBackoff
+	}
+
+	return backoff
+}
+
+// reconnectHandler defines how superviseReconnects reconnects a lost connection and reports its state
+type reconnectHandler struct {
+	// connect connects again and returns a channel that receives the error once the connection is lost
+	connect func() (<-chan error, error)
+
+	lost        func(err error)
+	retry       func(err error, backoff time.Duration)
+	reconnected func()
+}
+
+// superviseReconnects waits until the connection is lost and reconnects it with backoff until interrupt is closed
+func superviseReconnects(doneChan <-chan error, interrupt chan error, handler reconnectHandler) {
+	for {
+		select {
+		case err := <-doneChan:
+			handler.lost(err)
+		case <-interrupt:
+			return
+		}
+
+		backoff := minReconnectBackoff
+		for {
+			var err error
+			doneChan, err = handler.connect()
+			if err == nil {
+				break
+			}
+
+			handler.retry(err, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-interrupt:
+				return
+			}
+
+			backoff = nextReconnectBackoff(backoff)
+		}
+
+		handler.reconnected()
+	}
+}
+
+// portForwardingSupervisor keeps the local listeners of a port forwarding config bound and forwards
+// the accepted connections to the currently selected pod. If the connection to the pod is lost, the
+// pod is selected again and the port forwarding reconnects with backoff
+type portForwardingSupervisor struct {
+	client    kubectl.Client
+	options   targetselector.Options
+	ports     []portforward.ForwardedPort
+	addresses []string
+	inspected map[uint16]bool
+	status    *portForwardingStatusReporter
+	log       logpkg.Logger
+	fileLog   logpkg.Logger
+
+	listeners []net.Listener
+
+	// errorChan receives the errors of single forwarded connections
+	errorChan chan error
+
+	forwarderMutex sync.Mutex
+	forwarder      *portforward.PortForwarder
+	forwarderPod   *v1.Pod
+	forwarderStop  chan struct{}
+	forwarderReady chan struct{}
+}
+
+// portStrings returns the ports in the local:remote format of the port forwarder
+func (s *portForwardingSupervisor) portStrings() []string {
+	return portStrings(s.ports)
+}
+
+func portStrings(ports []portforward.ForwardedPort) []string {
+	out := make([]string, len(ports))
+	for index, port := range ports {
+		out[index] = strconv.Itoa(int(port.Local)) + ":" + strconv.Itoa(int(port.Remote))
+	}
+
+	return out
+}
+
+// listen binds the local ports, which stay bound until the supervisor is closed
+func (s *portForwardingSupervisor) listen() error {
+	var err error
+	s.listeners, err = listenOnPorts(s.ports, s.addresses, s.inspected, s.handleConnection, s.log)
+	return err
+}
+
+// listenOnPorts binds the local ports and passes every accepted connection to handle. A port that cannot
+// be bound is skipped with a warning, but at least one port has to be bound. The http traffic of inspected
+// local ports is captured by the inspector
+func listenOnPorts(ports []portforward.ForwardedPort, addresses []string, inspected map[uint16]bool, handle func(conn net.Conn, port portforward.ForwardedPort), log logpkg.Logger) ([]net.Listener, error) {
+	allListeners := []net.Listener{}
+	for index, port := range ports {
+		listeners, err := listenOnAddress(addresses[index], port.Local)
+		if err != nil {
+			log.Warnf("Unable to listen on port %d: %v. Is another application using that port?", port.Local, err)
+			continue
+		}
+
+		for _, listener := range listeners {
+			allListeners = append(allListeners, listener)
+			go accept(listener, port, inspected[port.Local], handle)
+		}
+	}
+
+	if len(allListeners) == 0 {
+		return nil, errors.Errorf("unable to listen on any of the requested ports: %s", strings.Join(portStrings(ports), ", "))
+	}
+
+	return allListeners, nil
+}
+
+// listenOnAddress binds the local port on the address, where localhost is bound on the ipv4 and ipv6 loopback address
+func listenOnAddress(address string, port uint16) ([]net.Listener, error) {
+	if address != "localhost" {
+		listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(int(port))))
+		if err != nil {
+			return nil, err
+		}
+
+		return []net.Listener{listener}, nil
+	}
+
+	listeners := []net.Listener{}
+	errs := []string{}
+	for _, loopback := range []struct {
+		protocol string
+		address  string
+	}{{"tcp4", "127.0.0.1"}, {"tcp6", "::1"}} {
+		listener, err := net.Listen(loopback.protocol, net.JoinHostPort(loopback.address, strconv.Itoa(int(port))))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		listeners = append(listeners, listener)
+	}
+	if len(listeners) == 0 {
+		return nil, errors.New(strings.Join(errs, ", "))
+	}
+
+	return listeners, nil
+}
+
+// accept accepts the local connections until the listener is closed
+func accept(listener net.Listener, port portforward.ForwardedPort, inspected bool, handle func(conn net.Conn, port portforward.ForwardedPort)) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		if inspected {
+			conn = inspect.Default.Inspect(conn, int(port.Remote))
+		}
+
+		go handle(conn, port)
+	}
+}
+
+// handleConnection forwards the local connection as soon as the port forwarding is connected
+func (s *portForwardingSupervisor) handleConnection(conn net.Conn, port portforward.ForwardedPort) {
+	forwarder := s.waitForForwarder(connectionWaitTimeout)
+	if forwarder == nil {
+		s.fileLog.Infof("Close connection on port %d, because port forwarding is not connected", port.Local)
+		_ = conn.Close()
+		return
+	}
+
+	forwarder.HandleConnection(conn, port)
+}
+
+// waitForForwarder returns the connected port forwarder or waits for a reconnect until the timeout is reached
+func (s *portForwardingSupervisor) waitForForwarder(timeout time.Duration) *portforward.PortForwarder {
+	deadline := time.After(timeout)
+	for {
+		s.forwarderMutex.Lock()
+		forwarder := s.forwarder
+		ready := s.forwarderReady
+		s.forwarderMutex.Unlock()
+		if forwarder != nil {
+			return forwarder
+		}
+
+		select {
+		case <-ready:
+		case <-deadline:
+			return nil
+		}
+	}
+}
+
+// setForwarder sets the port forwarder local connections are forwarded to. If forwarder is nil,
+// local connections wait until a new one is set
+func (s *portForwardingSupervisor) setForwarder(forwarder *portforward.PortForwarder, pod *v1.Pod, stopChan chan struct{}) {
+	s.forwarderMutex.Lock()
+	defer s.forwarderMutex.Unlock()
+
+	if forwarder == nil {
+		if s.forwarder != nil {
+			s.forwarderReady = make(chan struct{})
+		}
+	} else if s.forwarder == nil {
+		close(s.forwarderReady)
+	}
+
+	s.forwarder = forwarder
+	s.forwarderPod = pod
+	s.forwarderStop = stopChan
+}
+
+// connect selects the pod and connects a new port forwarder. The returned channel receives the error
+// once the connection to the pod is lost
+func (s *portForwardingSupervisor) connect(log logpkg.Logger) (<-chan error, error) {
+	pod, err := targetselector.NewTargetSelector(s.client).SelectSinglePod(context.TODO(), s.options, log)
+	if err != nil {
+		return nil, err
+	} else if pod == nil {
+		return nil, errors.New("no pod found")
+	}
+
+	forwarder, stopChan, doneChan, err := startPortForwarder(s.client, pod, s.portStrings(), s.addresses, s.errorChan)
+	if err != nil {
+		return nil, err
+	}
+
+	s.setForwarder(forwarder, pod, stopChan)
+	s.updateStatus(PortForwardingStateConnected, nil)
+	return doneChan, nil
+}
+
+// startPortForwarder connects a new port forwarder to the pod and waits until it is ready. The returned
+// stop channel disconnects it and the returned done channel receives the error once the connection is lost
+func startPortForwarder(client kubectl.Client, pod *v1.Pod, ports []string, addresses []string, errorChan chan error) (*portforward.PortForwarder, chan struct{}, <-chan error, error) {
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	forwarder, err := client.NewPortForwarder(pod, ports, addresses, stopChan, readyChan, errorChan)
+	if err != nil {
+		return nil, nil, nil, errors.Errorf("error starting port forwarding: %v", err)
+	}
+
+	doneChan := make(chan error, 1)
+	go func() {
+		err := forwarder.Connect()
+		if err == nil {
+			err = errors.New("port forwarding stopped")
+		}
+
+		doneChan <- err
+	}()
+
+	select {
+	case <-readyChan:
+	case err := <-doneChan:
+		return nil, nil, nil, err
+	case <-time.After(portForwardingReadyTimeout):
+		close(stopChan)
+		return nil, nil, nil, errors.Errorf("timeout waiting for port forwarding to %s/%s to start", pod.Namespace, pod.Name)
+	}
+
+	return forwarder, stopChan, doneChan, nil
+}
+
+// supervise reconnects the port forwarding whenever the connection to the pod is lost until interrupt is closed
+func (s *portForwardingSupervisor) supervise(doneChan <-chan error, interrupt chan error) {
+	defer s.close()
+
+	superviseReconnects(doneChan, interrupt, reconnectHandler{
+		connect: func() (<-chan error, error) {
+			return s.connect(logpkg.Discard)
+		},
+		lost: func(err error) {
+			pod := s.podName()
+			s.setForwarder(nil, nil, nil)
+			s.updateStatus(PortForwardingStateReconnecting, err)
+			s.log.Warnf("Port-Forwarding: lost connection to %s: %v, reconnecting...", pod, err)
+		},
+		retry: func(err error, backoff time.Duration) {
+			s.updateStatus(PortForwardingStateReconnecting, err)
+			s.fileLog.Infof("Error reconnecting port forwarding %s: %v, retry in %s", strings.Join(s.portStrings(), ", "), err, backoff)
+		},
+		reconnected: func() {
+			s.log.Donef("Port forwarding reconnected on %s (%s)", strings.Join(s.portStrings(), ", "), s.podName())
+		},
+	})
+}
+
+// collectErrors records the errors of single forwarded connections, which do not break the port forwarding
+func (s *portForwardingSupervisor) collectErrors(interrupt chan error) {
+	collectForwardingErrors(s.errorChan, interrupt, func(err error) {
+		s.fileLog.Infof("Port forwarding %s: %v", strings.Join(s.portStrings(), ", "), err)
+		s.updateStatus("", err)
+	})
+}
+
+// collectForwardingErrors passes the errors of single forwarded connections to record until interrupt is closed
+func collectForwardingErrors(errorChan chan error, interrupt chan error, record func(err error)) {
+	for {
+		select {
+		case err := <-errorChan:
+			record(err)
+		case <-interrupt:
+			// the errors of connections that were closed with the port forwarding are not recorded anymore
+			for {
+				select {
+				case <-errorChan:
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// close stops the port forwarder and closes the local listeners
+func (s *portForwardingSupervisor) close() {
+	s.forwarderMutex.Lock()
+	if s.forwarderStop != nil {
+		close(s.forwarderStop)
+		s.forwarderStop = nil
+	}
+	s.forwarderMutex.Unlock()
+
+	for _, listener := range s.listeners {
+		_ = listener.Close()
+	}
+
+	s.status.remove()
+}
+
+func (s *portForwardingSupervisor) podName() string {
+	s.forwarderMutex.Lock()
+	defer s.forwarderMutex.Unlock()
+
+	if s.forwarderPod == nil {
+		return ""
+	}
+
+	return s.forwarderPod.Namespace + "/" + s.forwarderPod.Name
+}
+
+// updateStatus reports the state of the port forwarding. An empty state only updates the last error
+func (s *portForwardingSupervisor) updateStatus(state PortForwardingState, err error) {
+	s.status.update(state, s.podName(), err)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
+	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	"github.com/loft-sh/devspace/pkg/devspace/tunnel"
+	"github.com/loft-sh/devspace/pkg/util/imageselector"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/pkg/errors"
+)
+
+// DefaultProxyPort is the local port of the proxy if no port is configured
+const DefaultProxyPort = 1080
+
+// StartProxy starts the proxy configured in dev.proxy
+func (serviceClient *client) StartProxy(interrupt chan error) error {
+	if serviceClient.config == nil || serviceClient.config.Config() == nil {
+		return errors.New("DevSpace config is not set")
+	}
+
+	proxyConfig := serviceClient.config.Config().Dev.Proxy
+	if proxyConfig == nil {
+		return nil
+	}
+
+	// apply config & set image selector
+	options := targetselector.NewEmptyOptions().ApplyConfigParameter(proxyConfig.LabelSelector, proxyConfig.Namespace, proxyConfig.ContainerName, "")
+	options.AllowPick = false
+	options.ImageSelector = []imageselector.ImageSelector{}
+	imageSelector, err := imageselector.Resolve(proxyConfig.ImageName, serviceClient.config, serviceClient.dependencies)
+	if err != nil {
+		return err
+	} else if imageSelector != nil {
+		options.ImageSelector = append(options.ImageSelector, *imageSelector)
+	}
+	if proxyConfig.ImageSelector != "" {
+		imageSelector, err := util.ResolveImageAsImageSelector(proxyConfig.ImageSelector, serviceClient.config, serviceClient.dependencies)
+		if err != nil {
+			return err
+		}
+
+		options.ImageSelector = append(options.ImageSelector, *imageSelector)
+	}
+	options.WaitingStrategy = targetselector.NewUntilNewestRunningWaitingStrategy(time.Second * 2)
+	options.SkipInitContainers = true
+
+	return serviceClient.startProxy(options, proxyConfig, interrupt, serviceClient.log)
+}
+
+// StartProxyFromCmd starts the proxy in the selected container and blocks until interrupt is closed
+func (serviceClient *client) StartProxyFromCmd(options targetselector.Options, proxyConfig *latest.ProxyConfig, interrupt chan error) error {
+	err := serviceClient.startProxy(options, proxyConfig, interrupt, serviceClient.log)
+	if err != nil {
+		return err
+	}
+
+	<-interrupt
+	return nil
+}
+
+// startProxy injects the devspace helper into the selected container and starts the local proxy, which
+// opens the proxied connections from that container
+func (serviceClient *client) startProxy(options targetselector.Options, proxyConfig *latest.ProxyConfig, interrupt chan error, log logpkg.Logger) error {
+	log.StartWait("Proxy: Waiting for containers to start...")
+	container, err := targetselector.NewTargetSelector(serviceClient.client).SelectSingleContainer(context.TODO(), options, log)
+	log.StopWait()
+	if err != nil {
+		return errors.Errorf("%s: %s", message.SelectorErrorPod, err.Error())
+	}
+
+	// make sure the devspace helper binary is injected
+	log.StartWait("Proxy: Upload devspace helper...")
+	err = InjectDevSpaceHelper(serviceClient.client, container.Pod, container.Container.Name, string(proxyConfig.Arch), serviceClient.log)
+	log.StopWait()
+	if err != nil {
+		return err
+	}
+
+	port := DefaultProxyPort
+	if proxyConfig.Port != nil {
+		port = *proxyConfig.Port
+	}
+
+	errorChan := make(chan error, 2)
+	closeChan := make(chan error)
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	go func() {
+		err := serviceClient.startStream(container.Pod, container.Container.Name, []string{DevSpaceHelperContainerPath, "tunnel"}, stdinReader, stdoutWriter)
+		if err != nil {
+			errorChan <- errors.Errorf("Proxy - connection lost to pod %s/%s: %v", container.Pod.Namespace, container.Pod.Name, err)
+		}
+	}()
+
+	go func() {
+		err := tunnel.StartProxy(stdoutReader, stdinWriter, proxyConfig.BindAddress, port, closeChan, container.Pod.Namespace, container.Pod.Name, log)
+		if err != nil {
+			errorChan <- err
+		}
+	}()
+
+	logFile := logpkg.GetFileLogger("proxy")
+	go func() {
+		select {
+		case err := <-errorChan:
+			if err != nil {
+				close(closeChan)
+				stdinWriter.Close()
+				stdoutWriter.Close()
+				logFile.Error(err)
+
+				// a restart must not ask which container to select
+				options.AllowPick = false
+				for {
+					err = serviceClient.startProxy(options, proxyConfig, interrupt, logpkg.Discard)
+					if err != nil {
+						serviceClient.log.Errorf("Error restarting proxy: %v", err)
+						serviceClient.log.Errorf("Will try again in 15 seconds")
+						time.Sleep(time.Second * 15)
+						continue
+					}
+
+					time.Sleep(time.Second * 5)
+					break
+				}
+			}
+		case <-interrupt:
+			close(closeChan)
+			stdinWriter.Close()
+			stdoutWriter.Close()
+		}
+	}()
+
+	return nil
+}
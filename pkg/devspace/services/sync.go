@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/loft-sh/devspace/assets"
+	helperutil "github.com/loft-sh/devspace/helper/util"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/util/hash"
@@ -31,6 +33,7 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // DevSpaceHelperBaseURL is the base url where to look for the sync helper
@@ -39,6 +42,13 @@ const DevSpaceHelperBaseURL = "https://github.com/loft-sh/devspace/releases"
 // DevSpaceHelperTempFolder is the local folder where we store the sync helper
 const DevSpaceHelperTempFolder = "devspacehelper"
 
+// defaultUploadConnections, defaultUploadBatchFiles and defaultUploadBatchSize are used if parallelUpload is enabled
+const (
+	defaultUploadConnections       = 4
+	defaultUploadBatchFiles        = 1000
+	defaultUploadBatchSize   int64 = 32 * 1024 * 1024
+)
+
 // helperBinaryRegEx is the regexp that finds the correct download link for the sync helper binary
 var helperBinaryRegEx = `href="(\/loft-sh\/devspace\/releases\/download\/[^\/]*\/%s)"`
 
@@ -143,51 +153,16 @@ func (serviceClient *client) startSyncClient(options *startClientOptions, log lo
 		syncConfig = options.SyncConfig
 	)
 
-	localPath := "."
-	if syncConfig.LocalSubPath != "" {
-		localPath = syncConfig.LocalSubPath
-	}
-
-	// check if local path exists
-	_, err := os.Stat(localPath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return err
-		}
-
-		err = os.MkdirAll(localPath, os.ModePerm)
-		if err != nil {
-			return err
-		}
-	}
-
-	options.TargetOptions.ImageSelector = []imageselector.ImageSelector{}
-	imageSelector, err := imageselector.Resolve(syncConfig.ImageName, serviceClient.config, serviceClient.dependencies)
+	container, err := serviceClient.selectSyncContainer(&options.TargetOptions, syncConfig, log)
 	if err != nil {
 		return err
-	} else if imageSelector != nil {
-		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
-	}
-	if syncConfig.ImageSelector != "" {
-		imageSelector, err := util.ResolveImageAsImageSelector(syncConfig.ImageSelector, serviceClient.config, serviceClient.dependencies)
-		if err != nil {
-			return err
-		}
-
-		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
-	}
-
-	log.StartWait("Sync: Waiting for pods...")
-	container, err := targetselector.NewTargetSelector(serviceClient.client).SelectSingleContainer(context.TODO(), options.TargetOptions, serviceClient.log)
-	log.StopWait()
-	if err != nil {
-		return errors.Errorf("Error selecting pod: %v", err)
 	}
 
 	syncDone := make(chan bool)
 
 	log.StartWait("Starting sync...")
-	syncClient, err := serviceClient.startSync(container.Pod, container.Container.Name, syncConfig, options.Verbose, syncDone, options.SyncLog)
+	pod, containerName := syncTarget(container)
+	syncClient, err := serviceClient.startSync(pod, containerName, syncConfig, options.Verbose, syncDone, options.SyncLog, false)
 	log.StopWait()
 	if err != nil {
 		return errors.Wrap(err, "start sync")
@@ -198,12 +173,12 @@ func (serviceClient *client) startSyncClient(options *startClientOptions, log lo
 		return errors.Errorf("Sync error: %v", err)
 	}
 
-	containerPath := "."
-	if syncConfig.ContainerPath != "" {
-		containerPath = syncConfig.ContainerPath
+	target := "Local"
+	if pod != nil {
+		target = fmt.Sprintf("Pod: %s/%s", pod.Namespace, pod.Name)
 	}
 
-	log.Donef("Sync started on %s <-> %s (Pod: %s/%s)", syncClient.LocalPath, containerPath, container.Pod.Namespace, container.Pod.Name)
+	log.Donef("Sync started on %s <-> %s (%s)", syncClient.LocalPath, getContainerPath(syncConfig), target)
 
 	if syncConfig.WaitInitialSync == nil || *syncConfig.WaitInitialSync == true {
 		log.StartWait("Sync: waiting for initial sync to complete")
@@ -212,6 +187,11 @@ func (serviceClient *client) startSyncClient(options *startClientOptions, log lo
 		log.StopWait()
 	}
 
+	// Upload to all other matching containers as well
+	if syncConfig.SyncAllReplicas && container != nil && (syncConfig.DisableUpload == nil || *syncConfig.DisableUpload == false) {
+		go serviceClient.syncReplicas(container, options, syncDone, log)
+	}
+
 	// Should we restart the client on error?
 	if options.RestartOnError {
 		go func(syncClient *sync.Sync, options *startClientOptions) {
@@ -247,6 +227,78 @@ func (serviceClient *client) startSyncClient(options *startClientOptions, log lo
 	return nil
 }
 
+// selectSyncContainer creates the local sync path if necessary and selects the container to sync with. If the sync
+// uses the local transport, no container is selected and nil is returned
+func (serviceClient *client) selectSyncContainer(targetOptions *targetselector.Options, syncConfig *latest.SyncConfig, log logpkg.Logger) (*kubectl.SelectedPodContainer, error) {
+	localPath := "."
+	if syncConfig.LocalSubPath != "" {
+		localPath = syncConfig.LocalSubPath
+	}
+
+	// check if local path exists
+	_, err := os.Stat(localPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		err = os.MkdirAll(localPath, os.ModePerm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isLocalTransport(syncConfig) {
+		return nil, nil
+	}
+
+	targetOptions.ImageSelector = []imageselector.ImageSelector{}
+	imageSelector, err := imageselector.Resolve(syncConfig.ImageName, serviceClient.config, serviceClient.dependencies)
+	if err != nil {
+		return nil, err
+	} else if imageSelector != nil {
+		targetOptions.ImageSelector = append(targetOptions.ImageSelector, *imageSelector)
+	}
+	if syncConfig.ImageSelector != "" {
+		imageSelector, err := util.ResolveImageAsImageSelector(syncConfig.ImageSelector, serviceClient.config, serviceClient.dependencies)
+		if err != nil {
+			return nil, err
+		}
+
+		targetOptions.ImageSelector = append(targetOptions.ImageSelector, *imageSelector)
+	}
+
+	log.StartWait("Sync: Waiting for pods...")
+	container, err := targetselector.NewTargetSelector(serviceClient.client).SelectSingleContainer(context.TODO(), *targetOptions, serviceClient.log)
+	log.StopWait()
+	if err != nil {
+		return nil, errors.Errorf("Error selecting pod: %v", err)
+	}
+
+	return container, nil
+}
+
+// PlanSyncFromCmd calculates the changes the initial sync would apply without transferring any files. The devspace
+// helper is still injected into the container if it is missing, because it has to list the remote files
+func (serviceClient *client) PlanSyncFromCmd(targetOptions targetselector.Options, syncConfig *latest.SyncConfig) (*sync.Plan, error) {
+	targetOptions.SkipInitContainers = true
+	container, err := serviceClient.selectSyncContainer(&targetOptions, syncConfig, serviceClient.log)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceClient.log.StartWait("Calculating sync plan...")
+	defer serviceClient.log.StopWait()
+
+	pod, containerName := syncTarget(container)
+	syncClient, err := serviceClient.startSync(pod, containerName, syncConfig, false, make(chan bool), logpkg.Discard, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "start sync")
+	}
+	defer syncClient.Stop(nil)
+
+	return syncClient.Plan()
+}
+
 func (serviceClient *client) isFatalSyncError(err error) bool {
 	if strings.Index(err.Error(), "no such file or directory") != -1 {
 		return true
@@ -258,10 +310,13 @@ func (serviceClient *client) isFatalSyncError(err error) bool {
 	return false
 }
 
-func (serviceClient *client) startSync(pod *v1.Pod, container string, syncConfig *latest.SyncConfig, verbose bool, syncDone chan bool, customLog logpkg.Logger) (*sync.Sync, error) {
-	err := InjectDevSpaceHelper(serviceClient.client, pod, container, string(syncConfig.Arch), serviceClient.log)
-	if err != nil {
-		return nil, err
+// startSync starts a new sync client to the given container. The pod is nil if the sync uses the local transport
+func (serviceClient *client) startSync(pod *v1.Pod, container string, syncConfig *latest.SyncConfig, verbose bool, syncDone chan bool, customLog logpkg.Logger, reinject bool) (*sync.Sync, error) {
+	if pod != nil {
+		err := injectDevSpaceHelper(serviceClient.client, pod, container, string(syncConfig.Arch), reinject, serviceClient.log)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	localPath := "."
@@ -269,10 +324,7 @@ func (serviceClient *client) startSync(pod *v1.Pod, container string, syncConfig
 		localPath = syncConfig.LocalSubPath
 	}
 
-	containerPath := "."
-	if syncConfig.ContainerPath != "" {
-		containerPath = syncConfig.ContainerPath
-	}
+	containerPath := getContainerPath(syncConfig)
 
 	upstreamDisabled := false
 	if syncConfig.DisableUpload != nil {
@@ -291,14 +343,33 @@ func (serviceClient *client) startSync(pod *v1.Pod, container string, syncConfig
 
 	options := sync.Options{
 		Verbose:              verbose,
-		SyncError:            make(chan error),
+		SyncError:            make(chan error, 1),
 		SyncDone:             syncDone,
 		InitialSyncCompareBy: compareBy,
 		InitialSync:          syncConfig.InitialSync,
+		ConflictStrategy:     syncConfig.ConflictStrategy,
+		Symlinks:             syncConfig.Symlinks,
 		UpstreamDisabled:     upstreamDisabled,
 		DownstreamDisabled:   downstreamDisabled,
 		Log:                  customLog,
-		Polling:              syncConfig.Polling,
+	}
+	if syncConfig.Permissions != nil && syncConfig.Permissions.PreserveExecutable != nil {
+		options.PreserveExecutable = *syncConfig.Permissions.PreserveExecutable
+	}
+
+	// The synced file index is saved per sync path and only reused for the same container,
+	// because a recreated container has to be synced completely again
+	if pod != nil {
+		stateKey := pod.Namespace + ":" + localPath + ":" + containerPath
+		if syncConfig.SyncAllReplicas {
+			stateKey += ":" + pod.Name
+		}
+		options.StateFile = filepath.Join(".devspace", "sync", hash.String(stateKey)[:32]+".json")
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == container && status.ContainerID != "" {
+				options.StateID = string(pod.UID) + "/" + status.ContainerID
+			}
+		}
 	}
 
 	// Add onDownload hooks
@@ -342,13 +413,50 @@ func (serviceClient *client) startSync(pod *v1.Pod, container string, syncConfig
 		options.RestartContainer = true
 	}
 
+	// exclude files that are too large
+	maxFileSize, sizeRules, err := getSyncSizeLimits(syncConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	options.MaxFileSize = maxFileSize
+	options.SizeRules = sizeRules
+
+	// split large uploads into batches
+	if syncConfig.ParallelUpload != nil {
+		options.UploadConnections = defaultUploadConnections
+		if syncConfig.ParallelUpload.Connections != nil {
+			options.UploadConnections = *syncConfig.ParallelUpload.Connections
+		}
+
+		options.UploadBatchFiles = defaultUploadBatchFiles
+		if syncConfig.ParallelUpload.BatchFiles != nil {
+			options.UploadBatchFiles = *syncConfig.ParallelUpload.BatchFiles
+		}
+
+		options.UploadBatchSize = defaultUploadBatchSize
+		if syncConfig.ParallelUpload.BatchSize != "" {
+			quantity, err := resource.ParseQuantity(syncConfig.ParallelUpload.BatchSize)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse parallelUpload.batchSize %s", syncConfig.ParallelUpload.BatchSize)
+			}
+
+			options.UploadBatchSize = quantity.Value()
+		}
+	}
+
 	syncClient, err := sync.NewSync(localPath, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "create sync")
 	}
 
+	transport, err := serviceClient.newSyncTransport(pod, container, syncConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start upstream
-	upstreamArgs := []string{DevSpaceHelperContainerPath, "sync", "upstream"}
+	upstreamArgs := []string{"upstream"}
 	for _, exclude := range options.ExcludePaths {
 		upstreamArgs = append(upstreamArgs, "--exclude", exclude)
 	}
@@ -377,32 +485,68 @@ func (serviceClient *client) startSync(pod *v1.Pod, container string, syncConfig
 			}
 		}
 	}
+	if syncConfig.OnUpload != nil {
+		for _, rule := range syncConfig.OnUpload.Rules {
+			out, err := json.Marshal(rule)
+			if err != nil {
+				return nil, errors.Wrap(err, "marshal upload rule")
+			}
 
-	upstreamArgs = append(upstreamArgs, containerPath)
-
-	upStdinReader, upStdinWriter := io.Pipe()
-	upStdoutReader, upStdoutWriter := io.Pipe()
+			upstreamArgs = append(upstreamArgs, "--uploadrule", string(out))
+		}
+	}
 
-	go func() {
-		err := serviceClient.startStream(pod, container, upstreamArgs, upStdinReader, upStdoutWriter)
-		if err != nil {
-			syncClient.Stop(errors.Errorf("Sync - connection lost to pod %s/%s: %v", pod.Namespace, pod.Name, err))
+	if syncConfig.Permissions != nil {
+		permissions := syncConfig.Permissions
+		if permissions.Owner != "" {
+			upstreamArgs = append(upstreamArgs, "--owner", permissions.Owner)
 		}
-	}()
+		if permissions.Group != "" {
+			upstreamArgs = append(upstreamArgs, "--group", permissions.Group)
+		}
+		if permissions.FileMode != "" {
+			upstreamArgs = append(upstreamArgs, "--filemode", permissions.FileMode)
+		}
+		if permissions.DirMode != "" {
+			upstreamArgs = append(upstreamArgs, "--dirmode", permissions.DirMode)
+		}
+		if permissions.PreserveExecutable != nil && *permissions.PreserveExecutable {
+			upstreamArgs = append(upstreamArgs, "--preserveexec")
+		}
+	}
+	if syncConfig.Symlinks != "" && syncConfig.Symlinks != latest.SymlinksModeFollow {
+		upstreamArgs = append(upstreamArgs, "--symlinks", string(syncConfig.Symlinks))
+	}
+
+	upstreamArgs = append(upstreamArgs, containerPath)
 
-	err = syncClient.InitUpstream(upStdoutReader, upStdinWriter)
+	err = syncClient.ConnectUpstream(transport, upstreamArgs)
 	if err != nil {
 		return nil, errors.Wrap(err, "init upstream")
 	}
 
 	// Start downstream
-	downstreamArgs := []string{DevSpaceHelperContainerPath, "sync", "downstream"}
+	downstreamArgs := []string{"downstream"}
 	if syncConfig.ThrottleChangeDetection != nil {
 		downstreamArgs = append(downstreamArgs, "--throttle", strconv.FormatInt(*syncConfig.ThrottleChangeDetection, 10))
 	}
 	if syncConfig.Polling {
 		downstreamArgs = append(downstreamArgs, "--polling")
 	}
+	if syncConfig.Symlinks != "" && syncConfig.Symlinks != latest.SymlinksModeFollow {
+		downstreamArgs = append(downstreamArgs, "--symlinks", string(syncConfig.Symlinks))
+	}
+	if options.MaxFileSize > 0 {
+		downstreamArgs = append(downstreamArgs, "--maxfilesize", strconv.FormatInt(options.MaxFileSize, 10))
+	}
+	for _, rule := range options.SizeRules {
+		out, err := json.Marshal(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		downstreamArgs = append(downstreamArgs, "--sizerule", string(out))
+	}
 	for _, exclude := range options.ExcludePaths {
 		downstreamArgs = append(downstreamArgs, "--exclude", exclude)
 	}
@@ -411,19 +555,23 @@ func (serviceClient *client) startSync(pod *v1.Pod, container string, syncConfig
 	}
 	downstreamArgs = append(downstreamArgs, containerPath)
 
-	downStdinReader, downStdinWriter := io.Pipe()
-	downStdoutReader, downStdoutWriter := io.Pipe()
+	err = syncClient.ConnectDownstream(transport, downstreamArgs)
+	if err != nil {
+		return nil, errors.Wrap(err, "init downstream")
+	}
 
-	go func() {
-		err := serviceClient.startStream(pod, container, downstreamArgs, downStdinReader, downStdoutWriter)
-		if err != nil {
-			syncClient.Stop(errors.Errorf("Sync - connection lost to pod %s/%s: %v", pod.Namespace, pod.Name, err))
+	// Replace a helper that is too old once instead of failing later with obscure errors
+	err = syncClient.CheckHelper()
+	if err != nil {
+		// The sync was never started, so nobody should be notified that it stopped
+		syncClient.Options.SyncDone = nil
+		syncClient.Stop(nil)
+		if reinject || pod == nil {
+			return nil, err
 		}
-	}()
 
-	err = syncClient.InitDownstream(downStdoutReader, downStdinWriter)
-	if err != nil {
-		return nil, errors.Wrap(err, "init downstream")
+		serviceClient.log.Infof("Reinject the devspace helper into pod %s/%s, because %v", pod.Namespace, pod.Name, err)
+		return serviceClient.startSync(pod, container, syncConfig, verbose, syncDone, customLog, true)
 	}
 
 	return syncClient, nil
@@ -445,7 +593,14 @@ func (serviceClient *client) startStream(pod *v1.Pod, container string, command
 	return nil
 }
 
+// InjectDevSpaceHelper injects the devspace helper into the container if it is not there yet or has a different version
 func InjectDevSpaceHelper(client kubectl.Client, pod *v1.Pod, container string, arch string, log logpkg.Logger) error {
+	return injectDevSpaceHelper(client, pod, container, arch, false, log)
+}
+
+// injectDevSpaceHelper injects the devspace helper into the container. If force is true, the helper is replaced
+// even if the container already has a helper with the same version
+func injectDevSpaceHelper(client kubectl.Client, pod *v1.Pod, container string, arch string, force bool, log logpkg.Logger) error {
 	// Compare sync versions
 	version := upgrade.GetRawVersion()
 	if version == "" {
@@ -462,7 +617,7 @@ func InjectDevSpaceHelper(client kubectl.Client, pod *v1.Pod, container string,
 	// Check if sync is already in pod
 	localHelperName := "devspacehelper" + arch
 	stdout, _, err := client.ExecBuffered(pod, container, []string{DevSpaceHelperContainerPath, "version"}, nil)
-	if err != nil || version != string(stdout) {
+	if force || err != nil || version != string(stdout) {
 		// check if we can find it in the assets
 		helperBytes, err := assets.Asset("release/" + localHelperName)
 		if err == nil {
@@ -741,6 +896,55 @@ func injectSyncHelper(client kubectl.Client, pod *v1.Pod, container string, file
 	return <-errChan
 }
 
+// syncTarget returns the pod and the name of the selected container, which is nil for the local transport
+func syncTarget(container *kubectl.SelectedPodContainer) (*v1.Pod, string) {
+	if container == nil {
+		return nil, ""
+	}
+
+	return container.Pod, container.Container.Name
+}
+
+// getContainerPath returns the path the sync uploads to, which is a local directory for the local transport
+func getContainerPath(syncConfig *latest.SyncConfig) string {
+	if isLocalTransport(syncConfig) {
+		return syncConfig.Transport.LocalPath
+	}
+	if syncConfig.ContainerPath != "" {
+		return syncConfig.ContainerPath
+	}
+
+	return "."
+}
+
+// getSyncSizeLimits parses the max file size and size rules of the sync config into bytes
+func getSyncSizeLimits(syncConfig *latest.SyncConfig) (int64, []helperutil.SizeRule, error) {
+	maxFileSize := int64(0)
+	if syncConfig.MaxFileSize != "" {
+		quantity, err := resource.ParseQuantity(syncConfig.MaxFileSize)
+		if err != nil {
+			return 0, nil, errors.Wrapf(err, "parse maxFileSize %s", syncConfig.MaxFileSize)
+		}
+
+		maxFileSize = quantity.Value()
+	}
+
+	sizeRules := make([]helperutil.SizeRule, 0, len(syncConfig.SizeRules))
+	for _, rule := range syncConfig.SizeRules {
+		quantity, err := resource.ParseQuantity(rule.MaxSize)
+		if err != nil {
+			return 0, nil, errors.Wrapf(err, "parse maxSize %s of size rule %s", rule.MaxSize, rule.Path)
+		}
+
+		sizeRules = append(sizeRules, helperutil.SizeRule{
+			Path:    rule.Path,
+			MaxSize: quantity.Value(),
+		})
+	}
+
+	return maxFileSize, sizeRules, nil
+}
+
 func getSyncCommands(cmd *latest.SyncExecCommand) (string, []string, string, []string) {
 	if cmd.Command != "" {
 		return cmd.Command, cmd.Args, cmd.Command, cmd.Args
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// replicaSyncInterval is the interval in which DevSpace checks which containers match a sync config with syncAllReplicas
+var replicaSyncInterval = time.Second * 5
+
+// replicaSync is a running sync to a replica container
+type replicaSync interface {
+	Stop(fatalError error)
+}
+
+// startReplicaFunc starts the sync to a replica container. The key of the replica is sent to stopped as
+// soon as the sync stops by itself
+type startReplicaFunc func(container *kubectl.SelectedPodContainer, key string, stopped chan string) (replicaSync, error)
+
+// syncReplicas uploads local changes to all other containers that match the selector of the sync config until the
+// sync to the primary container is done. Files are only downloaded from the primary container, so the replicas
+// are synced in upload only mode
+func (serviceClient *client) syncReplicas(primary *kubectl.SelectedPodContainer, options *startClientOptions, primaryDone chan bool, log logpkg.Logger) {
+	disableDownload := true
+	waitInitialSync := false
+	syncConfig := *options.SyncConfig
+	syncConfig.DisableDownload = &disableDownload
+	syncConfig.WaitInitialSync = &waitInitialSync
+
+	watchReplicas(serviceClient.client, primary, options.TargetOptions, primaryDone, func(container *kubectl.SelectedPodContainer, key string, stopped chan string) (replicaSync, error) {
+		return serviceClient.startReplicaSync(container, &syncConfig, options, key, stopped, primaryDone, log)
+	}, log)
+}
+
+// watchReplicas starts a sync to every running container that matches the target options except the primary
+// container and stops the syncs to containers that don't match anymore until primaryDone is closed
+func watchReplicas(client kubectl.Client, primary *kubectl.SelectedPodContainer, targetOptions targetselector.Options, primaryDone chan bool, startReplica startReplicaFunc, log logpkg.Logger) {
+	replicas := map[string]replicaSync{}
+	stopped := make(chan string)
+	defer func() {
+		for _, replica := range replicas {
+			replica.Stop(nil)
+		}
+	}()
+
+	ticker := time.NewTicker(replicaSyncInterval)
+	defer ticker.Stop()
+	for {
+		containers, err := kubectl.NewFilterWithSort(client, targetOptions.SortPods, targetOptions.SortContainers).SelectContainers(context.TODO(), targetOptions.Selector)
+		if err != nil {
+			log.Warnf("Sync: error selecting replicas: %v", err)
+		} else {
+			found := map[string]bool{}
+			for _, container := range containers {
+				if isSameContainer(container, primary) || targetselector.IsContainerRunning(container) == false {
+					continue
+				}
+
+				key := replicaKey(container)
+				found[key] = true
+				if replicas[key] != nil {
+					continue
+				}
+
+				replica, err := startReplica(container, key, stopped)
+				if err != nil {
+					log.Warnf("Sync: error starting sync to replica %s/%s: %v", container.Pod.Namespace, container.Pod.Name, err)
+					continue
+				}
+
+				replicas[key] = replica
+			}
+
+			// Stop the sync to containers that don't exist anymore
+			for key, replica := range replicas {
+				if found[key] == false {
+					replica.Stop(nil)
+					delete(replicas, key)
+				}
+			}
+		}
+
+		select {
+		case <-primaryDone:
+			return
+		case key := <-stopped:
+			// the replica is restarted with the next check if it still exists
+			delete(replicas, key)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (serviceClient *client) startReplicaSync(container *kubectl.SelectedPodContainer, syncConfig *latest.SyncConfig, options *startClientOptions, key string, stopped chan string, primaryDone chan bool, log logpkg.Logger) (replicaSync, error) {
+	syncDone := make(chan bool)
+	syncClient, err := serviceClient.startSync(container.Pod, container.Container.Name, syncConfig, options.Verbose, syncDone, options.SyncLog, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "start sync")
+	}
+
+	err = syncClient.Start()
+	if err != nil {
+		return nil, errors.Errorf("Sync error: %v", err)
+	}
+
+	go func() {
+		select {
+		case err := <-syncClient.Options.SyncError:
+			log.Warnf("Sync: lost connection to replica %s/%s: %v", container.Pod.Namespace, container.Pod.Name, err)
+		case <-syncDone:
+		}
+
+		select {
+		case stopped <- key:
+		case <-primaryDone:
+		}
+	}()
+
+	log.Donef("Sync started on %s -> %s (Replica Pod: %s/%s)", syncClient.LocalPath, getContainerPath(syncConfig), container.Pod.Namespace, container.Pod.Name)
+	return syncClient, nil
+}
+
+func isSameContainer(a, b *kubectl.SelectedPodContainer) bool {
+	return a.Pod.UID == b.Pod.UID && a.Container.Name == b.Container.Name
+}
+
+func replicaKey(container *kubectl.SelectedPodContainer) string {
+	return string(container.Pod.UID) + "/" + container.Container.Name
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeReplicaSync struct {
+	key     string
+	stopped chan string
+}
+
+func (f *fakeReplicaSync) Stop(fatalError error) {
+	f.stopped <- f.key
+}
+
+func replicaPod(name, app string, running bool) *corev1.Pod {
+	state := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+	if running {
+		state = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+			UID:       types.UID(name),
+			Labels:    map[string]string{"app": app},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: app}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", State: state}},
+		},
+	}
+}
+
+func expectReplica(t *testing.T, replicas chan string, expected string) {
+	select {
+	case key := <-replicas:
+		assert.Equal(t, key, expected)
+	case <-time.After(time.Second * 5):
+		t.Fatalf("Timeout waiting for replica %s", expected)
+	}
+}
+
+func TestWatchReplicas(t *testing.T) {
+	oldInterval := replicaSyncInterval
+	replicaSyncInterval = time.Millisecond * 10
+	defer func() { replicaSyncInterval = oldInterval }()
+
+	primary := replicaPod("primary", "backend", true)
+	kube := fake.NewSimpleClientset(
+		primary,
+		replicaPod("replica-a", "backend", true),
+		replicaPod("replica-b", "backend", false),
+		replicaPod("other", "frontend", true),
+	)
+	client := &fakekube.Client{Client: kube}
+
+	started := make(chan string, 10)
+	stopped := make(chan string, 10)
+	replicaStopped := make(chan chan string, 1)
+	startReplica := func(container *kubectl.SelectedPodContainer, key string, stoppedChan chan string) (replicaSync, error) {
+		select {
+		case replicaStopped <- stoppedChan:
+		default:
+		}
+
+		started <- key
+		return &fakeReplicaSync{key: key, stopped: stopped}, nil
+	}
+
+	primaryDone := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchReplicas(client, &kubectl.SelectedPodContainer{Pod: primary, Container: &primary.Spec.Containers[0]}, targetselector.Options{
+			Selector: kubectl.Selector{
+				LabelSelector: "app=backend",
+				Namespace:     "test",
+			},
+		}, primaryDone, startReplica, log.Discard)
+	}()
+
+	// only the running replica is synced
+	expectReplica(t, started, "replica-a/app")
+
+	// a replica that stopped by itself is restarted
+	stoppedChan := <-replicaStopped
+	stoppedChan <- "replica-a/app"
+	expectReplica(t, started, "replica-a/app")
+
+	// a replica that started running is added and a deleted replica is stopped
+	_, err := kube.CoreV1().Pods("test").UpdateStatus(context.TODO(), replicaPod("replica-b", "backend", true), metav1.UpdateOptions{})
+	assert.NilError(t, err)
+	expectReplica(t, started, "replica-b/app")
+	err = kube.CoreV1().Pods("test").Delete(context.TODO(), "replica-a", metav1.DeleteOptions{})
+	assert.NilError(t, err)
+	expectReplica(t, stopped, "replica-a/app")
+
+	// the remaining replicas are stopped with the primary sync
+	close(primaryDone)
+	expectReplica(t, stopped, "replica-b/app")
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timeout waiting for the replicas to stop")
+	}
+
+	select {
+	case key := <-started:
+		t.Fatalf("Unexpected replica %s", key)
+	default:
+	}
+}
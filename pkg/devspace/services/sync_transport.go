@@ -0,0 +1,183 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	helpersync "github.com/loft-sh/devspace/helper/cmd/sync"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// newSyncTransport creates the transport the sync uses to start the helper sync servers
+func (serviceClient *client) newSyncTransport(pod *v1.Pod, container string, syncConfig *latest.SyncConfig) (sync.Transport, error) {
+	if isLocalTransport(syncConfig) {
+		return sync.LocalTransport{}, nil
+	}
+
+	if syncConfig.Transport != nil && syncConfig.Transport.Type == latest.SyncTransportTypePortForward {
+		port := helpersync.DefaultListenPort
+		if syncConfig.Transport.Port != nil {
+			port = *syncConfig.Transport.Port
+		}
+
+		transport := &portForwardTransport{
+			client:    serviceClient.client,
+			pod:       pod,
+			container: container,
+			port:      port,
+			exec: &execTransport{
+				serviceClient: serviceClient,
+				pod:           pod,
+				container:     container,
+			},
+		}
+
+		return transport, transport.startServer()
+	}
+
+	return &execTransport{
+		serviceClient: serviceClient,
+		pod:           pod,
+		container:     container,
+	}, nil
+}
+
+func isLocalTransport(syncConfig *latest.SyncConfig) bool {
+	return syncConfig.Transport != nil && syncConfig.Transport.Type == latest.SyncTransportTypeLocal
+}
+
+// execTransport starts the helper sync servers via kubectl exec, one exec stream per server
+type execTransport struct {
+	serviceClient *client
+	pod           *v1.Pod
+	container     string
+}
+
+// Start implements interface
+func (t *execTransport) Start(args []string, stdin io.Reader, stdout io.Writer) error {
+	command := append([]string{DevSpaceHelperContainerPath, "sync"}, args...)
+	return t.serviceClient.startStream(t.pod, t.container, command, stdin, stdout)
+}
+
+func (t *execTransport) String() string {
+	return fmt.Sprintf("pod %s/%s", t.pod.Namespace, t.pod.Name)
+}
+
+// portForwardTransport connects to a long-running helper in the container that serves the sync servers over tcp.
+// Each server uses its own port forwarding connection. The helper does not run commands for connections over
+// tcp, so sync servers that run commands are started via exec instead
+type portForwardTransport struct {
+	client    kubectl.Client
+	pod       *v1.Pod
+	container string
+	port      int
+
+	// token authenticates the connections to the long-running helper
+	token string
+	exec  *execTransport
+}
+
+// startServer starts the long-running helper in the background if it is not running yet and retrieves its token
+func (t *portForwardTransport) startServer() error {
+	stdout, stderr, err := t.client.ExecBuffered(t.pod, t.container, []string{DevSpaceHelperContainerPath, "sync", "listen", "--port", strconv.Itoa(t.port), "--background"}, nil)
+	if err != nil {
+		return errors.Errorf("start sync server in pod %s/%s: %s %v", t.pod.Namespace, t.pod.Name, string(stderr), err)
+	}
+
+	t.token = strings.TrimSpace(string(stdout))
+	if t.token == "" {
+		return errors.Errorf("start sync server in pod %s/%s: no token received", t.pod.Namespace, t.pod.Name)
+	}
+
+	return nil
+}
+
+// Start implements interface
+func (t *portForwardTransport) Start(args []string, stdin io.Reader, stdout io.Writer) error {
+	if helpersync.HasCommandArgs(args) {
+		return t.exec.Start(args, stdin, stdout)
+	}
+
+	request, err := json.Marshal(&helpersync.ListenRequest{
+		Token: t.token,
+		Args:  args,
+	})
+	if err != nil {
+		return err
+	}
+
+	readyChan := make(chan struct{})
+	stopChan := make(chan struct{})
+	errorChan := make(chan error, 1)
+	pf, err := t.client.NewPortForwarder(t.pod, []string{"0:" + strconv.Itoa(t.port)}, []string{"127.0.0.1"}, stopChan, readyChan, errorChan)
+	if err != nil {
+		return errors.Wrap(err, "create port forwarder")
+	}
+	defer close(stopChan)
+
+	go func() {
+		err := pf.ForwardPorts()
+		if err != nil {
+			errorChan <- err
+		}
+	}()
+
+	select {
+	case <-readyChan:
+	case err := <-errorChan:
+		return errors.Wrap(err, "forward port")
+	case <-time.After(20 * time.Second):
+		return errors.Errorf("timeout waiting for port forwarding to start")
+	}
+
+	ports, err := pf.GetPorts()
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("tcp", "127.0.0.1:"+strconv.Itoa(int(ports[0].Local)))
+	if err != nil {
+		return errors.Wrap(err, "connect to sync server")
+	}
+	defer conn.Close()
+
+	// The first line tells the helper which sync server to start
+	_, err = conn.Write(append(request, '\n'))
+	if err != nil {
+		return errors.Wrap(err, "send sync command")
+	}
+
+	copyErrors := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(conn, stdin)
+		copyErrors <- err
+	}()
+	go func() {
+		_, err := io.Copy(stdout, conn)
+		if err == nil {
+			err = errors.New("connection closed by sync server")
+		}
+
+		copyErrors <- err
+	}()
+
+	select {
+	case err := <-copyErrors:
+		return err
+	case err := <-errorChan:
+		return err
+	}
+}
+
+func (t *portForwardTransport) String() string {
+	return fmt.Sprintf("pod %s/%s:%d", t.pod.Namespace, t.pod.Name, t.port)
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
+	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	"github.com/loft-sh/devspace/pkg/devspace/tunnel"
+	"github.com/loft-sh/devspace/pkg/util/imageselector"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/pkg/errors"
+)
+
+// startUDPForwarding forwards the udp ports of the port forwarding config via the devspace helper,
+// because the kubernetes port forwarding only supports tcp
+func (serviceClient *client) startUDPForwarding(cache *generated.CacheConfig, index int, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
+	var err error
+
+	portMappings := filterPortMappings(portForwarding.PortMappings, latest.PortProtocolUDP)
+	status := newPortForwardingStatusReporter(serviceClient.portForwardingStatus, index, latest.PortProtocolUDP, udpPortStrings(portMappings), logpkg.GetFileLogger("portforwarding"))
+
+	// apply config & set image selector
+	options := targetselector.NewEmptyOptions().ApplyConfigParameter(portForwarding.LabelSelector, portForwarding.Namespace, portForwarding.ContainerName, "")
+	options.AllowPick = false
+	options.ImageSelector = []imageselector.ImageSelector{}
+	imageSelector, err := imageselector.Resolve(portForwarding.ImageName, serviceClient.config, serviceClient.dependencies)
+	if err != nil {
+		return err
+	} else if imageSelector != nil {
+		options.ImageSelector = append(options.ImageSelector, *imageSelector)
+	}
+	if portForwarding.ImageSelector != "" {
+		imageSelector, err := util.ResolveImageAsImageSelector(portForwarding.ImageSelector, serviceClient.config, serviceClient.dependencies)
+		if err != nil {
+			return err
+		}
+
+		options.ImageSelector = append(options.ImageSelector, *imageSelector)
+	}
+	options.WaitingStrategy = targetselector.NewUntilNewestRunningWaitingStrategy(time.Second * 2)
+	options.SkipInitContainers = true
+
+	status.update(PortForwardingStateConnecting, "", nil)
+	log.StartWait("UDP-Port-Forwarding: Waiting for containers to start...")
+	connection, err := serviceClient.connectUDPForwarding(options, portForwarding, portMappings, log)
+	log.StopWait()
+	if err != nil {
+		status.update(PortForwardingStateFailed, "", err)
+		return err
+	}
+	status.update(PortForwardingStateConnected, connection.pod, nil)
+
+	go func() {
+		superviseReconnects(connection.done, interrupt, reconnectHandler{
+			connect: func() (<-chan error, error) {
+				newConnection, err := serviceClient.connectUDPForwarding(options, portForwarding, portMappings, logpkg.Discard)
+				if err != nil {
+					return nil, err
+				}
+
+				connection = newConnection
+				status.update(PortForwardingStateConnected, connection.pod, nil)
+				return connection.done, nil
+			},
+			lost: func(err error) {
+				connection.close()
+				status.fileLog.Error(err)
+				status.update(PortForwardingStateReconnecting, "", err)
+				serviceClient.log.Warnf("UDP-Port-Forwarding: lost connection to %s: %v, reconnecting...", connection.pod, err)
+			},
+			retry: func(err error, backoff time.Duration) {
+				status.update(PortForwardingStateReconnecting, "", err)
+				status.fileLog.Infof("Error reconnecting udp port forwarding %s: %v, retry in %s", udpPortStrings(portMappings), err, backoff)
+			},
+			reconnected: func() {
+				serviceClient.log.Donef("UDP port forwarding reconnected on %s (%s)", udpPortStrings(portMappings), connection.pod)
+			},
+		})
+
+		connection.close()
+		status.remove()
+	}()
+
+	return nil
+}
+
+// udpForwardingConnection is a udp tunnel through the devspace helper in a container
+type udpForwardingConnection struct {
+	pod string
+
+	// done receives the error once the connection to the container is lost
+	done <-chan error
+
+	closeOnce sync.Once
+	closeChan chan error
+	stdin     io.Closer
+	stdout    io.Closer
+}
+
+// close stops the tunnel, it can be called multiple times
+func (c *udpForwardingConnection) close() {
+	c.closeOnce.Do(func() {
+		close(c.closeChan)
+		_ = c.stdin.Close()
+		_ = c.stdout.Close()
+	})
+}
+
+// connectUDPForwarding selects the container, injects the devspace helper and starts the udp tunnel to it
+func (serviceClient *client) connectUDPForwarding(options targetselector.Options, portForwarding *latest.PortForwardingConfig, portMappings []*latest.PortMapping, log logpkg.Logger) (*udpForwardingConnection, error) {
+	container, err := targetselector.NewTargetSelector(serviceClient.client).SelectSingleContainer(context.TODO(), options, log)
+	if err != nil {
+		return nil, errors.Errorf("%s: %s", message.SelectorErrorPod, err.Error())
+	}
+
+	// make sure the devspace helper binary is injected
+	err = InjectDevSpaceHelper(serviceClient.client, container.Pod, container.Container.Name, string(portForwarding.Arch), serviceClient.log)
+	if err != nil {
+		return nil, err
+	}
+
+	errorChan := make(chan error, 2)
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	connection := &udpForwardingConnection{
+		pod:       container.Pod.Namespace + "/" + container.Pod.Name,
+		done:      errorChan,
+		closeChan: make(chan error),
+		stdin:     stdinWriter,
+		stdout:    stdoutWriter,
+	}
+
+	go func() {
+		err := serviceClient.startStream(container.Pod, container.Container.Name, []string{DevSpaceHelperContainerPath, "tunnel"}, stdinReader, stdoutWriter)
+		if err != nil {
+			errorChan <- errors.Errorf("UDP Port Forwarding - connection lost to pod %s: %v", connection.pod, err)
+		}
+	}()
+
+	go func() {
+		err := tunnel.StartUDPForward(stdoutReader, stdinWriter, portMappings, connection.closeChan, container.Pod.Namespace, container.Pod.Name, log)
+		if err != nil {
+			errorChan <- err
+		}
+	}()
+
+	return connection, nil
+}
+
+// udpPortStrings returns the udp ports in the local:remote/udp format
+func udpPortStrings(portMappings []*latest.PortMapping) string {
+	ports := []string{}
+	for _, portMapping := range portMappings {
+		if portMapping.LocalPort == nil {
+			continue
+		}
+
+		remotePort := *portMapping.LocalPort
+		if portMapping.RemotePort != nil {
+			remotePort = *portMapping.RemotePort
+		}
+
+		ports = append(ports, strconv.Itoa(*portMapping.LocalPort)+":"+strconv.Itoa(remotePort)+"/udp")
+	}
+
+	return strings.Join(ports, ", ")
+}
@@ -0,0 +1,273 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+)
+
+// uploadBatch is a part of a large upload that is uploaded and retried on its own
+type uploadBatch struct {
+	files []*FileInformation
+	size  int64
+}
+
+// splitUploadBatches splits the files into batches of at most maxFiles files and maxSize bytes. A file
+// that is larger than maxSize is uploaded in its own batch
+func splitUploadBatches(files []*FileInformation, maxFiles int, maxSize int64) []*uploadBatch {
+	batches := []*uploadBatch{}
+	batch := &uploadBatch{}
+	for _, file := range files {
+		size := int64(0)
+		if file.IsDirectory == false {
+			size = file.Size
+		}
+
+		if len(batch.files) > 0 && ((maxFiles > 0 && len(batch.files) >= maxFiles) || (maxSize > 0 && batch.size+size > maxSize)) {
+			batches = append(batches, batch)
+			batch = &uploadBatch{}
+		}
+
+		batch.files = append(batch.files, file)
+		batch.size += size
+	}
+	if len(batch.files) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// uploadProgress tracks how many files and bytes of a batched upload were transferred
+type uploadProgress struct {
+	mutex sync.Mutex
+
+	files      int
+	totalFiles int
+	size       int64
+	totalSize  int64
+}
+
+// done adds the batch to the transferred files and returns the amount of transferred files and bytes
+func (p *uploadProgress) done(batch *uploadBatch) (int, int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.files += len(batch.files)
+	p.size += batch.size
+	return p.files, p.size
+}
+
+// shouldUploadInBatches checks if the files are too many or too large for a single upload
+func (u *upstream) shouldUploadInBatches(files []*FileInformation) bool {
+	if u.sync.Options.UploadConnections <= 0 || u.sync.upstreamTransport == nil {
+		return false
+	} else if hasFeature(u.capabilities, util.FeatureExecute) == false {
+		// the commands of a batched upload could not be executed over the main connection
+		return false
+	}
+
+	return len(splitUploadBatches(files, u.sync.Options.UploadBatchFiles, u.sync.Options.UploadBatchSize)) > 1
+}
+
+// applyCreatesInBatches splits the files into batches and uploads them over several helper
+// connections in parallel. A batch that fails is retried on its own with a new connection
+func (u *upstream) applyCreatesInBatches(files []*FileInformation) error {
+	// Large files that exist remotely already are uploaded as delta over the main connection
+	u.sync.fileIndex.fileMapMutex.Lock()
+	files = u.uploadDeltas(files)
+	u.sync.fileIndex.fileMapMutex.Unlock()
+
+	batches := splitUploadBatches(files, u.sync.Options.UploadBatchFiles, u.sync.Options.UploadBatchSize)
+	if len(batches) == 0 {
+		// the batch command of the uploaded deltas still has to run
+		return u.executeCommands(nil)
+	}
+
+	progress := &uploadProgress{totalFiles: len(files)}
+	for _, batch := range batches {
+		progress.totalSize += batch.size
+	}
+
+	connections := u.sync.Options.UploadConnections
+	if connections > len(batches) {
+		connections = len(batches)
+	}
+
+	u.sync.log.Infof("Upstream - Upload %d create change(s) in %d batches over %d connection(s) (Uncompressed ~%0.2f KB)", len(files), len(batches), connections, float64(progress.totalSize)/1024.0)
+
+	// the channel is filled before the workers start, so that no one blocks if a worker stops early
+	batchChan := make(chan *uploadBatch, len(batches))
+	for _, batch := range batches {
+		batchChan <- batch
+	}
+	close(batchChan)
+
+	// the first worker that fails cancels the uploads of the others
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		err      error
+		errMutex sync.Mutex
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < connections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			workerErr := u.uploadBatches(ctx, batchChan, progress)
+			if workerErr != nil {
+				errMutex.Lock()
+				if err == nil {
+					err = workerErr
+					cancel()
+				}
+				errMutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	// the additional connections don't run commands, so they are executed once for all batches
+	return u.executeCommands(files)
+}
+
+// executeCommands runs the upload commands over the main connection for files that were uploaded
+// over the additional connections
+func (u *upstream) executeCommands(files []*FileInformation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*30)
+	defer cancel()
+
+	executeClient, err := u.client.Execute(ctx)
+	if err != nil {
+		return errors.Wrap(err, "execute client")
+	}
+
+	sendFiles := make([]string, 0, removeFilesBufferSize)
+	for _, file := range files {
+		sendFiles = append(sendFiles, file.Name)
+		if len(sendFiles) >= removeFilesBufferSize {
+			err = executeClient.Send(&remote.Paths{
+				Paths: sendFiles,
+			})
+			if err != nil {
+				return errors.Wrap(err, "send paths")
+			}
+
+			sendFiles = make([]string, 0, removeFilesBufferSize)
+		}
+	}
+
+	if len(sendFiles) > 0 {
+		err = executeClient.Send(&remote.Paths{
+			Paths: sendFiles,
+		})
+		if err != nil {
+			return errors.Wrap(err, "send paths")
+		}
+	}
+
+	_, err = executeClient.CloseAndRecv()
+	if err != nil {
+		return errors.Wrap(err, "execute commands")
+	}
+
+	return nil
+}
+
+// uploadBatches uploads batches over its own helper connection until there are no batches left or the context is canceled
+func (u *upstream) uploadBatches(ctx context.Context, batches <-chan *uploadBatch, progress *uploadProgress) error {
+	var connection *upstreamConnection
+	defer func() {
+		if connection != nil {
+			connection.Close()
+		}
+	}()
+
+	for batch := range batches {
+		files := batch.files
+		for i := 0; len(files) > 0; i++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			var err error
+			if connection == nil {
+				connection, err = u.sync.newUpstreamConnection(u.uploadLimit)
+			}
+			if err == nil {
+				err = u.uploadBatch(ctx, connection.client, files)
+				if err == nil {
+					break
+				}
+			}
+
+			if i+1 >= syncRetries {
+				return errors.Wrap(err, "upload batch")
+			}
+
+			// the connection might be broken, so the batch is retried with a new one
+			u.sync.log.Infof("Upstream - Retry batch of %d file(s) because of error: %v", len(files), err)
+			if connection != nil {
+				connection.Close()
+				connection = nil
+			}
+
+			files = u.updateUploadChanges(files)
+		}
+
+		doneFiles, doneSize := progress.done(batch)
+		u.sync.log.Infof("Upstream - Uploaded %d of %d file(s) (~%0.2f of ~%0.2f MB)", doneFiles, progress.totalFiles, float64(doneSize)/1024.0/1024.0, float64(progress.totalSize)/1024.0/1024.0)
+	}
+
+	return nil
+}
+
+// uploadBatch uploads the files as a single archive with the given client
+func (u *upstream) uploadBatch(ctx context.Context, client remote.UpstreamClient, files []*FileInformation) error {
+	reader, writer := io.Pipe()
+
+	defer reader.Close()
+	defer writer.Close()
+
+	var archiver *Archiver
+	errorChan := make(chan error, 1)
+	go func() {
+		var compressErr error
+		archiver, compressErr = u.compress(writer, files, u.ignoreMatcher)
+		errorChan <- compressErr
+	}()
+
+	err := uploadArchive(ctx, client, reader)
+	if err != nil {
+		return errors.Wrap(err, "upload archive")
+	}
+
+	err = <-errorChan
+	if err != nil {
+		return errors.Wrap(err, "compress archive")
+	}
+
+	// update the written files, so that they are not uploaded again
+	u.sync.fileIndex.fileMapMutex.Lock()
+	defer u.sync.fileIndex.fileMapMutex.Unlock()
+
+	for _, element := range archiver.WrittenFiles() {
+		u.sync.fileIndex.CreateDirInFileMap(path.Dir(element.Name))
+		u.sync.fileIndex.fileMap[element.Name] = element
+	}
+
+	return nil
+}
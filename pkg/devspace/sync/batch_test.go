@@ -0,0 +1,164 @@
+// +build !windows
+
+package sync
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestSplitUploadBatches(t *testing.T) {
+	files := []*FileInformation{
+		{Name: "/a", Size: 10},
+		{Name: "/b", Size: 10},
+		{Name: "/dir", Size: 4096, IsDirectory: true},
+		{Name: "/c", Size: 100},
+		{Name: "/d", Size: 1},
+	}
+
+	testCases := map[string]struct {
+		maxFiles int
+		maxSize  int64
+		expected [][]string
+	}{
+		"no limits":       {expected: [][]string{{"/a", "/b", "/dir", "/c", "/d"}}},
+		"max files":       {maxFiles: 2, expected: [][]string{{"/a", "/b"}, {"/dir", "/c"}, {"/d"}}},
+		"max size":        {maxSize: 25, expected: [][]string{{"/a", "/b", "/dir"}, {"/c"}, {"/d"}}},
+		"files and size":  {maxFiles: 2, maxSize: 15, expected: [][]string{{"/a"}, {"/b", "/dir"}, {"/c"}, {"/d"}}},
+		"larger than max": {maxSize: 5, expected: [][]string{{"/a"}, {"/b"}, {"/dir"}, {"/c"}, {"/d"}}},
+	}
+
+	for name, testCase := range testCases {
+		batches := splitUploadBatches(files, testCase.maxFiles, testCase.maxSize)
+		if len(batches) != len(testCase.expected) {
+			t.Fatalf("Test case %s: expected %d batches, got %d", name, len(testCase.expected), len(batches))
+		}
+
+		for i, batch := range batches {
+			names := []string{}
+			for _, file := range batch.files {
+				names = append(names, file.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(testCase.expected[i]) {
+				t.Fatalf("Test case %s: expected batch %d to be %v, got %v", name, i, testCase.expected[i], names)
+			}
+		}
+	}
+}
+
+func TestUploadInBatches(t *testing.T) {
+	remote, local, outside := initTestDirs(t)
+	defer os.RemoveAll(remote)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(outside)
+
+	for i := 0; i < 25; i++ {
+		err := os.MkdirAll(filepath.Join(local, fmt.Sprintf("dir%d", i%3)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(local, fmt.Sprintf("dir%d", i%3), fmt.Sprintf("file%d.txt", i)), []byte(fmt.Sprintf("content%d", i)), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	syncClient, err := NewSync(local, Options{
+		InitialSync:               latest.InitialSyncStrategyPreferLocal,
+		UpstreamInitialSyncDone:   make(chan bool),
+		DownstreamInitialSyncDone: make(chan bool),
+		SyncError:                 make(chan error, 1),
+		UploadConnections:         3,
+		UploadBatchFiles:          4,
+		Log:                       log.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syncClient.Stop(nil)
+
+	transport := &stoppedTransport{stopped: make(chan string, 32)}
+	err = syncClient.ConnectDownstream(transport, []string{"downstream", remote})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The commands of the main connection are executed once for the whole upload
+	batchLog := filepath.Join(outside, "batch.log")
+	fileLog := filepath.Join(outside, "files.log")
+	err = syncClient.ConnectUpstream(transport, []string{
+		"upstream",
+		"--batchcmd", "sh", "--batchargs", "-c", "--batchargs", "echo batch >> " + batchLog,
+		"--filechangecmd", "sh", "--filechangeargs", "-c", "--filechangeargs", "echo $0 >> " + fileLog, "--filechangeargs", "{}",
+		remote,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = syncClient.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-syncClient.Options.UpstreamInitialSyncDone:
+	case err := <-syncClient.Options.SyncError:
+		t.Fatal(err)
+	case <-time.After(time.Second * 20):
+		t.Fatal("Timeout waiting for initial sync")
+	}
+
+	for i := 0; i < 25; i++ {
+		path := filepath.Join(remote, fmt.Sprintf("dir%d", i%3), fmt.Sprintf("file%d.txt", i))
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(content) != fmt.Sprintf("content%d", i) {
+			t.Fatalf("Expected content%d in %s, got %s", i, path, string(content))
+		}
+	}
+
+	// The additional connections are closed after the upload
+	for i := 0; i < 3; i++ {
+		select {
+		case args := <-transport.stopped:
+			if args != "upstream" {
+				t.Fatalf("Expected an upstream server to stop, got %s", args)
+			}
+		case <-time.After(time.Second * 10):
+			t.Fatal("Timeout waiting for the additional upstream servers to stop")
+		}
+	}
+
+	out, err := ioutil.ReadFile(batchLog)
+	if err != nil {
+		t.Fatal(err)
+	} else if string(out) != "batch\n" {
+		t.Fatalf("Expected the batch command to run once, got %q", string(out))
+	}
+
+	out, err = ioutil.ReadFile(fileLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 25 {
+		t.Fatalf("Expected the file change command to run for 25 files, got %d", len(lines))
+	}
+}
+
+func TestWithoutCommandArgs(t *testing.T) {
+	args := withoutCommandArgs([]string{"upstream", "--exclude", "node_modules", "--batchcmd", "make", "--batchargs", "build", "--uploadrule={\"path\":\"*.go\"}", "--filechangecmd", "chmod", "--symlinks", "skip", "/app"})
+	expected := []string{"upstream", "--exclude", "node_modules", "--symlinks", "skip", "/app"}
+	if fmt.Sprint(args) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+}
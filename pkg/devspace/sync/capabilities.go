@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requiredFeatures are the helper features the sync can't work without
+var requiredFeatures = []string{util.FeatureChangesNotify}
+
+type capabilitiesFunc func(ctx context.Context, in *remote.Empty, opts ...grpc.CallOption) (*remote.Capabilities, error)
+
+// getCapabilities asks the helper which version it has and which features it supports. Helpers that don't
+// know the capabilities call yet are treated as helpers without any optional features
+func getCapabilities(capabilities capabilitiesFunc) (*remote.Capabilities, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	remoteCapabilities, err := capabilities(ctx, &remote.Empty{})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return &remote.Capabilities{}, nil
+		}
+
+		return nil, err
+	}
+
+	return remoteCapabilities, nil
+}
+
+// hasFeature checks if the helper supports the given feature
+func hasFeature(capabilities *remote.Capabilities, feature string) bool {
+	if capabilities == nil {
+		return false
+	}
+
+	for _, f := range capabilities.Features {
+		if f == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckHelper returns an error if the helper the sync is connected to is too old to be used. The helper
+// should be replaced with a newer version in this case
+func (s *Sync) CheckHelper() error {
+	for _, capabilities := range []*remote.Capabilities{s.upstreamCapabilities(), s.downstreamCapabilities()} {
+		if capabilities == nil {
+			continue
+		}
+
+		for _, feature := range s.requiredFeatures() {
+			if hasFeature(capabilities, feature) == false {
+				version := capabilities.Version
+				if version == "" {
+					version = "unknown"
+				}
+
+				return errors.Errorf("devspace helper (version %s) does not support %s", version, feature)
+			}
+		}
+	}
+
+	return nil
+}
+
+// requiredFeatures returns the helper features the sync needs with its options
+func (s *Sync) requiredFeatures() []string {
+	if s.followsSymlinks() {
+		return requiredFeatures
+	}
+
+	return append([]string{util.FeatureSymlinks}, requiredFeatures...)
+}
+
+func (s *Sync) upstreamCapabilities() *remote.Capabilities {
+	if s.upstream == nil {
+		return nil
+	}
+
+	return s.upstream.capabilities
+}
+
+func (s *Sync) downstreamCapabilities() *remote.Capabilities {
+	if s.downstream == nil {
+		return nil
+	}
+
+	return s.downstream.capabilities
+}
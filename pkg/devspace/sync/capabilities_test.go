@@ -0,0 +1,45 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+)
+
+func TestCheckHelper(t *testing.T) {
+	testCases := map[string]struct {
+		upstream    *remote.Capabilities
+		downstream  *remote.Capabilities
+		expectError bool
+	}{
+		"current helper": {
+			upstream:   &remote.Capabilities{Version: "latest", Features: util.SupportedFeatures},
+			downstream: &remote.Capabilities{Version: "latest", Features: util.SupportedFeatures},
+		},
+		"helper without capabilities": {
+			upstream:    &remote.Capabilities{},
+			downstream:  &remote.Capabilities{},
+			expectError: true,
+		},
+		"missing required feature": {
+			upstream:    &remote.Capabilities{Version: "v5.0.0", Features: util.SupportedFeatures},
+			downstream:  &remote.Capabilities{Version: "v5.0.0", Features: []string{util.FeatureChecksums}},
+			expectError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		s := &Sync{
+			upstream:   &upstream{capabilities: testCase.upstream},
+			downstream: &downstream{capabilities: testCase.downstream},
+		}
+
+		err := s.CheckHelper()
+		if testCase.expectError && err == nil {
+			t.Fatalf("Test case %s: expected error", name)
+		} else if testCase.expectError == false && err != nil {
+			t.Fatalf("Test case %s: unexpected error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package sync
+
+import (
+	"io"
+	"time"
+)
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// logTransferStats prints the compression ratio and throughput of a transfer in verbose mode
+func (s *Sync) logTransferStats(prefix, compression string, uncompressed, compressed int64, start time.Time) {
+	if s.Options.Verbose == false || compressed == 0 {
+		return
+	}
+
+	seconds := time.Since(start).Seconds()
+	s.log.Infof("%s - Transferred ~%0.2f KB with %s (ratio %0.2f, ~%0.2f KB/s)", prefix, float64(compressed)/1024.0, compression, float64(uncompressed)/float64(compressed), float64(compressed)/1024.0/seconds)
+}
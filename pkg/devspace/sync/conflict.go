@@ -0,0 +1,54 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/fsutil"
+)
+
+// conflictFileSuffix is appended to the local copy of a file that was changed locally and remotely
+const conflictFileSuffix = ".conflict"
+
+// resolveConflicts resolves remote changes of files that were also changed locally since they were last synced
+// with the configured conflict strategy and returns the changes that should still be downloaded.
+// s.fileIndex needs to be locked before this function is called
+func (d *downstream) resolveConflicts(changes []*remote.Change) []*remote.Change {
+	newChanges := make([]*remote.Change, 0, len(changes))
+	for _, change := range changes {
+		absFilepath := filepath.Join(d.sync.LocalPath, change.Path)
+		stat, err := os.Stat(absFilepath)
+		if err != nil || isConflict(change, stat, d.sync) == false {
+			newChanges = append(newChanges, change)
+			continue
+		}
+
+		switch d.sync.Options.ConflictStrategy {
+		case latest.ConflictStrategyPreferLocal:
+			// The local file is uploaded by the upstream, because it differs from the fileMap
+			d.sync.log.Infof("Downstream - Conflict: '.%s' was changed locally and remotely, keep local file", change.Path)
+			continue
+		case latest.ConflictStrategyPreferRemote:
+			d.sync.log.Infof("Downstream - Conflict: '.%s' was changed locally and remotely, override local file", change.Path)
+		case latest.ConflictStrategyKeepBoth:
+			err = fsutil.Copy(absFilepath, absFilepath+conflictFileSuffix, true)
+			if err != nil {
+				d.sync.log.Infof("Downstream - Conflict: '.%s' was changed locally and remotely, keep local file because writing the conflict file failed: %v", change.Path, err)
+				continue
+			}
+
+			d.sync.log.Infof("Downstream - Conflict: '.%s' was changed locally and remotely, saved local file as '.%s%s'", change.Path, change.Path, conflictFileSuffix)
+		default:
+			d.sync.log.Infof("Downstream - Conflict: '.%s' was changed locally and remotely, keep newest file", change.Path)
+			newChanges = append(newChanges, change)
+			continue
+		}
+
+		d.unarchiver.overridePaths[change.Path] = true
+		newChanges = append(newChanges, change)
+	}
+
+	return newChanges
+}
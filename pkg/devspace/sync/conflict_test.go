@@ -0,0 +1,183 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestResolveConflicts(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	// unchanged was not changed locally, conflict was changed locally since the last sync
+	lastSynced := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"unchanged", "conflict"} {
+		err = ioutil.WriteFile(filepath.Join(local, name), []byte("local"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Chtimes(filepath.Join(local, "unchanged"), lastSynced, lastSynced)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []*remote.Change{
+		{ChangeType: remote.ChangeType_CHANGE, Path: "/unchanged", MtimeUnix: time.Now().Unix(), Size: 6},
+		{ChangeType: remote.ChangeType_CHANGE, Path: "/conflict", MtimeUnix: time.Now().Unix(), Size: 6},
+	}
+
+	testCases := map[latest.ConflictStrategy]struct {
+		expectedChanges  int
+		expectedOverride bool
+		expectedCopy     bool
+	}{
+		latest.ConflictStrategyPreferNewest: {expectedChanges: 2},
+		latest.ConflictStrategyPreferLocal:  {expectedChanges: 1},
+		latest.ConflictStrategyPreferRemote: {expectedChanges: 2, expectedOverride: true},
+		latest.ConflictStrategyKeepBoth:     {expectedChanges: 2, expectedOverride: true, expectedCopy: true},
+	}
+
+	for strategy, testCase := range testCases {
+		s := &Sync{
+			LocalPath: local,
+			Options: Options{
+				ConflictStrategy: strategy,
+			},
+			fileIndex: newFileIndex(),
+			log:       log.Discard,
+		}
+		for _, name := range []string{"/unchanged", "/conflict"} {
+			s.fileIndex.Set(&FileInformation{Name: name, Mtime: lastSynced.Unix(), Size: 5})
+		}
+
+		d := &downstream{
+			sync:       s,
+			unarchiver: NewUnarchiver(s, false, log.Discard),
+		}
+
+		newChanges := d.resolveConflicts(changes)
+		if len(newChanges) != testCase.expectedChanges {
+			t.Fatalf("Unexpected changes for strategy %s: expected %d, got %d", strategy, testCase.expectedChanges, len(newChanges))
+		}
+		if d.unarchiver.overridePaths["/conflict"] != testCase.expectedOverride {
+			t.Fatalf("Unexpected override for strategy %s: expected %v", strategy, testCase.expectedOverride)
+		}
+		if d.unarchiver.overridePaths["/unchanged"] {
+			t.Fatalf("Unexpected override of /unchanged for strategy %s", strategy)
+		}
+
+		_, err = os.Stat(filepath.Join(local, "conflict"+conflictFileSuffix))
+		if (err == nil) != testCase.expectedCopy {
+			t.Fatalf("Unexpected conflict file for strategy %s: %v", strategy, err)
+		}
+		_ = os.Remove(filepath.Join(local, "conflict"+conflictFileSuffix))
+	}
+}
+
+func TestResolveConflictsBeforeUpload(t *testing.T) {
+	testCases := map[latest.ConflictStrategy]struct {
+		expectedLocal    string
+		expectedRemote   string
+		expectedConflict string
+	}{
+		latest.ConflictStrategyPreferLocal:  {expectedLocal: "local change", expectedRemote: "local change"},
+		latest.ConflictStrategyPreferRemote: {expectedLocal: "remote change", expectedRemote: "remote change"},
+		latest.ConflictStrategyKeepBoth:     {expectedLocal: "remote change", expectedRemote: "remote change", expectedConflict: "local change"},
+	}
+
+	for strategy, testCase := range testCases {
+		local, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(local)
+		remotePath, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(remotePath)
+
+		// the file was changed on both sides since it was last synced
+		lastSynced := time.Now().Add(-time.Hour).Truncate(time.Second)
+		changed := time.Now().Add(-time.Minute).Truncate(time.Second)
+		for path, content := range map[string]string{filepath.Join(local, "file"): "local change", filepath.Join(remotePath, "file"): "remote change"} {
+			err = ioutil.WriteFile(path, []byte(content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.Chtimes(path, changed, changed)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		syncClient, err := NewSync(local, Options{
+			ConflictStrategy: strategy,
+			SyncError:        make(chan error, 1),
+			Log:              log.Discard,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = syncClient.ConnectDownstream(LocalTransport{}, []string{"downstream", remotePath})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = syncClient.ConnectUpstream(LocalTransport{}, []string{"upstream", remotePath})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		syncClient.fileIndex.Set(&FileInformation{Name: "/file", Mtime: lastSynced.Unix(), Size: int64(len("synced"))})
+		syncClient.initialSyncDone = true
+
+		// the upstream notices the local change while the downstream syncs the notified remote change
+		syncClient.downstream.notifyRemoteChanges()
+		downstreamErr := make(chan error, 1)
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			downstreamErr <- syncClient.downstream.syncNotifiedChanges()
+		}()
+
+		err = syncClient.upstream.applyChanges([]*FileInformation{{Name: "/file", Mtime: changed.Unix(), MtimeNano: changed.UnixNano(), Size: int64(len("local change"))}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = <-downstreamErr
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]string{
+			filepath.Join(local, "file"):                    testCase.expectedLocal,
+			filepath.Join(remotePath, "file"):               testCase.expectedRemote,
+			filepath.Join(local, "file"+conflictFileSuffix): testCase.expectedConflict,
+		}
+		for path, expectedContent := range expected {
+			content, err := ioutil.ReadFile(path)
+			if expectedContent == "" {
+				if err == nil {
+					t.Fatalf("Unexpected file %s for strategy %s", path, strategy)
+				}
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			} else if string(content) != expectedContent {
+				t.Fatalf("Unexpected content of %s for strategy %s: expected %s, got %s", path, strategy, expectedContent, string(content))
+			}
+		}
+
+		syncClient.Stop(nil)
+	}
+}
@@ -0,0 +1,242 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+)
+
+// deltaThreshold is the file size in bytes from which on changed files are transferred as the delta
+// to the version the other side already has instead of the complete file
+var deltaThreshold int64 = 1024 * 1024
+
+// shouldTransferDelta checks if the file is big enough for a delta transfer and was already synced before,
+// which means the other side has a version of it. s.fileIndex needs to be locked before this function is called
+func shouldTransferDelta(s *Sync, relativePath string, size int64, isDir bool) bool {
+	if isDir || size < deltaThreshold {
+		return false
+	}
+
+	lastSynced := s.fileIndex.fileMap[relativePath]
+	return lastSynced != nil && lastSynced.IsDirectory == false && lastSynced.IsSymbolicLink == false && lastSynced.LinkTarget == "" && lastSynced.Size > 0
+}
+
+// uploadDeltas uploads the changed blocks of large files that exist remotely already and returns the
+// files that need to be uploaded completely. s.fileIndex needs to be locked before this function is called
+func (u *upstream) uploadDeltas(files []*FileInformation) []*FileInformation {
+	if hasFeature(u.capabilities, util.FeatureDelta) == false {
+		return files
+	}
+
+	newFiles := make([]*FileInformation, 0, len(files))
+	for _, file := range files {
+		if shouldTransferDelta(u.sync, file.Name, file.Size, file.IsDirectory) == false {
+			newFiles = append(newFiles, file)
+			continue
+		}
+
+		fileInformation, err := u.uploadDelta(file.Name)
+		if err != nil {
+			u.sync.log.Infof("Upstream - Upload complete file '%s', because delta upload failed: %v", u.getRelativeUpstreamPath(file.Name), err)
+			newFiles = append(newFiles, file)
+			continue
+		}
+
+		u.sync.fileIndex.fileMap[fileInformation.Name] = fileInformation
+	}
+
+	return newFiles
+}
+
+func (u *upstream) uploadDelta(relativePath string) (*FileInformation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	// Retrieve the block signatures of the remote file
+	blockSize := util.DeltaBlockSize(u.sync.fileIndex.fileMap[relativePath].Size)
+	signatureClient, err := u.client.Signature(ctx, &remote.SignatureRequest{
+		Path:      relativePath,
+		BlockSize: blockSize,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieve signature")
+	}
+
+	blocks := make([]*remote.BlockSignature, 0, 1024)
+	for {
+		signature, err := signatureClient.Recv()
+		if signature != nil {
+			blocks = append(blocks, signature.Blocks...)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "recv signature")
+		}
+	}
+
+	// Send the changed blocks
+	deltaClient, err := u.client.ApplyDelta(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply delta")
+	}
+
+	sent := int64(0)
+	stat, err := util.SendDelta(filepath.Join(u.sync.LocalPath, relativePath), relativePath, blockSize, blocks, func(delta *remote.Delta) error {
+		for _, operation := range delta.Operations {
+			sent += int64(len(operation.Data))
+		}
+		if delta.Path != "" {
+			delta.Mode = uint32(chmodTarEntry(os.FileMode(delta.Mode), u.sync.Options.PreserveExecutable))
+		}
+
+		return deltaClient.Send(delta)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "send delta")
+	}
+
+	_, err = deltaClient.CloseAndRecv()
+	if err != nil {
+		return nil, errors.Wrap(err, "apply delta")
+	}
+
+	u.sync.log.Infof("Upstream - Uploaded delta of '%s' (~%0.2f KB of ~%0.2f KB)", u.getRelativeUpstreamPath(relativePath), float64(sent)/1024.0, float64(stat.Size())/1024.0)
+	return &FileInformation{
+		Name:      relativePath,
+		Mtime:     stat.ModTime().Unix(),
+		MtimeNano: stat.ModTime().UnixNano(),
+		Size:      stat.Size(),
+	}, nil
+}
+
+// downloadDeltas downloads the changed blocks of large files that exist locally already and returns the
+// changes that need to be downloaded completely
+func (d *downstream) downloadDeltas(changes []*remote.Change) []*remote.Change {
+	if hasFeature(d.capabilities, util.FeatureDelta) == false {
+		return changes
+	}
+
+	d.sync.fileIndex.fileMapMutex.Lock()
+	defer d.sync.fileIndex.fileMapMutex.Unlock()
+
+	newChanges := make([]*remote.Change, 0, len(changes))
+	for _, change := range changes {
+		if shouldTransferDelta(d.sync, change.Path, change.Size, change.IsDir) == false {
+			newChanges = append(newChanges, change)
+			continue
+		}
+
+		// Newer local files are handled by the unarchiver
+		absFilepath := filepath.Join(d.sync.LocalPath, change.Path)
+		stat, err := os.Stat(absFilepath)
+		if err != nil || stat.Mode().IsRegular() == false || (stat.ModTime().Unix() > change.MtimeUnix && d.unarchiver.overridePaths[change.Path] == false) {
+			newChanges = append(newChanges, change)
+			continue
+		}
+
+		err = d.downloadDelta(change.Path, absFilepath)
+		if err != nil {
+			d.sync.log.Infof("Downstream - Download complete file '.%s', because delta download failed: %v", change.Path, err)
+			newChanges = append(newChanges, change)
+			continue
+		}
+
+		delete(d.unarchiver.overridePaths, change.Path)
+	}
+
+	return newChanges
+}
+
+func (d *downstream) downloadDelta(relativePath, absFilepath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	file, err := os.Open(absFilepath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Send the block signatures of the local file
+	deltaClient, err := d.client.Delta(ctx)
+	if err != nil {
+		return errors.Wrap(err, "start delta")
+	}
+
+	blockSize := util.DeltaBlockSize(stat.Size())
+	signature := &remote.Signature{
+		Path:      relativePath,
+		BlockSize: blockSize,
+	}
+	err = util.ComputeSignature(file, blockSize, func(blocks []*remote.BlockSignature) error {
+		signature.Blocks = blocks
+		err := deltaClient.Send(signature)
+		signature = &remote.Signature{}
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "send signature")
+	}
+
+	// The file is replaced when it is patched, which fails on windows if it is still open
+	file.Close()
+
+	err = deltaClient.CloseSend()
+	if err != nil {
+		return errors.Wrap(err, "close send")
+	}
+
+	// Receive the changed blocks and patch the local file
+	delta, err := deltaClient.Recv()
+	if err != nil {
+		return errors.Wrap(err, "recv delta")
+	}
+
+	mtime := time.Unix(delta.MtimeUnix, 0)
+	size := delta.Size
+	received := int64(0)
+	recv := func() (*remote.Delta, error) {
+		delta, err := deltaClient.Recv()
+		if delta != nil {
+			for _, operation := range delta.Operations {
+				received += int64(len(operation.Data))
+			}
+		}
+
+		return delta, err
+	}
+	for _, operation := range delta.Operations {
+		received += int64(len(operation.Data))
+	}
+
+	err = util.PatchFile(absFilepath, delta, recv)
+	if err != nil {
+		return errors.Wrap(err, "patch file")
+	}
+
+	// Set mod time correctly
+	_ = os.Chtimes(absFilepath, time.Now(), mtime)
+
+	// Update fileMap so that upstream does not upload the file
+	d.sync.fileIndex.fileMap[relativePath] = &FileInformation{
+		Name:  relativePath,
+		Mtime: mtime.Unix(),
+		Size:  size,
+	}
+
+	d.sync.log.Infof("Downstream - Downloaded delta of '.%s' (~%0.2f KB of ~%0.2f KB)", relativePath, float64(received)/1024.0, float64(size)/1024.0)
+	return d.unarchiver.executeFileChangeCmd(absFilepath)
+}
@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestDeltaTransfer(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+	remotePath, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(remotePath)
+
+	oldContent := make([]byte, 256*1024)
+	rand.Read(oldContent)
+	newContent := append([]byte{}, oldContent...)
+	copy(newContent[100*1024:], []byte("changed content"))
+
+	// upload is changed locally and download is changed remotely
+	for name, contents := range map[string][][]byte{"upload": {newContent, oldContent}, "download": {oldContent, newContent}} {
+		err = ioutil.WriteFile(filepath.Join(local, name), contents[0], 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(remotePath, name), contents[1], 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defer func(threshold int64) { deltaThreshold = threshold }(deltaThreshold)
+	deltaThreshold = 1024
+
+	s, err := NewSync(local, Options{Log: log.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"/upload", "/download"} {
+		s.fileIndex.Set(&FileInformation{Name: name, Size: int64(len(oldContent)), Mtime: 1})
+	}
+
+	upClientReader, upClientWriter := io.Pipe()
+	upServerReader, upServerWriter := io.Pipe()
+	go func() {
+		_ = server.StartUpstreamServer(upServerReader, upClientWriter, &server.UpstreamOptions{
+			UploadPath: remotePath,
+		})
+	}()
+	downClientReader, downClientWriter := io.Pipe()
+	downServerReader, downServerWriter := io.Pipe()
+	go func() {
+		_ = server.StartDownstreamServer(downServerReader, downClientWriter, &server.DownstreamOptions{
+			RemotePath: remotePath,
+			Polling:    true,
+		})
+	}()
+
+	err = s.InitUpstream(upClientReader, upServerWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InitDownstream(downClientReader, downServerWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.fileIndex.Lock()
+	files := s.upstream.uploadDeltas([]*FileInformation{{Name: "/upload", Size: int64(len(newContent)), Mtime: 2}})
+	s.fileIndex.Unlock()
+	if len(files) != 0 {
+		t.Fatalf("Expected /upload to be uploaded as delta")
+	}
+
+	changes := s.downstream.downloadDeltas([]*remote.Change{{Path: "/download", Size: int64(len(newContent)), MtimeUnix: time.Now().Unix() + 10}})
+	if len(changes) != 0 {
+		t.Fatalf("Expected /download to be downloaded as delta")
+	}
+
+	for _, path := range []string{filepath.Join(remotePath, "upload"), filepath.Join(local, "download")} {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != string(newContent) {
+			t.Fatalf("Unexpected content of %s", path)
+		}
+	}
+}
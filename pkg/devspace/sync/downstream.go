@@ -7,10 +7,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/juju/ratelimit"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/loft-sh/devspace/helper/remote"
 	"github.com/loft-sh/devspace/helper/util"
@@ -25,10 +28,25 @@ type downstream struct {
 	client remote.DownstreamClient
 
 	unarchiver *Unarchiver
+
+	// capabilities are the version and features of the downstream helper
+	capabilities *remote.Capabilities
+
+	// compression is the negotiated compression of downloaded archives
+	compression string
+
+	// remoteChanges is open while the helper notified us about remote changes that are not synced yet and is
+	// closed as soon as they are synced, so that the upstream can wait for them. Protected by the remoteChangesMutex
+	remoteChanges      chan struct{}
+	remoteChangesMutex sync.Mutex
 }
 
 const downloadFilesBufferSize = 64
 
+// pollChangesInterval is the interval in which the remote changes are counted if the helper
+// does not notify us about changes
+const pollChangesInterval = time.Millisecond * 500
+
 // newDownstream creates a new downstream handler with the given parameters
 func newDownstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*downstream, error) {
 	var (
@@ -50,13 +68,22 @@ func newDownstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*do
 		return nil, errors.Wrap(err, "new client connection")
 	}
 
+	// Check which features the helper supports
+	client := remote.NewDownstreamClient(conn)
+	capabilities, err := getCapabilities(client.Capabilities)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieve helper capabilities")
+	}
+
 	return &downstream{
-		interrupt:  make(chan bool, 1),
-		sync:       sync,
-		reader:     reader,
-		writer:     writer,
-		client:     remote.NewDownstreamClient(conn),
-		unarchiver: NewUnarchiver(sync, false, sync.log),
+		interrupt:    make(chan bool, 1),
+		sync:         sync,
+		reader:       reader,
+		writer:       writer,
+		client:       client,
+		unarchiver:   NewUnarchiver(sync, false, sync.log),
+		capabilities: capabilities,
+		compression:  util.NegotiateCompression(capabilities.Compressions),
 	}, nil
 }
 
@@ -109,6 +136,58 @@ func (d *downstream) collectChanges() ([]*remote.Change, error) {
 	return changes, nil
 }
 
+// checksums retrieves the remote content checksums for the given paths
+func (d *downstream) checksums(paths []string) (map[string]string, error) {
+	checksums := make(map[string]string, len(paths))
+	if len(paths) == 0 {
+		return checksums, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*30)
+	defer cancel()
+
+	checksumsClient, err := d.client.Checksums(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "start retrieving checksums")
+	}
+
+	for i := 0; i < len(paths); i += downloadFilesBufferSize {
+		end := i + downloadFilesBufferSize
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		err = checksumsClient.Send(&remote.Paths{
+			Paths: paths[i:end],
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "send paths")
+		}
+	}
+
+	err = checksumsClient.CloseSend()
+	if err != nil {
+		return nil, errors.Wrap(err, "close send")
+	}
+
+	for {
+		checksumChunk, err := checksumsClient.Recv()
+		if checksumChunk != nil {
+			for _, checksum := range checksumChunk.Checksums {
+				checksums[checksum.Path] = checksum.Sha256
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "recv checksums")
+		}
+	}
+
+	return checksums, nil
+}
+
 func (d *downstream) startPing(doneChan chan struct{}) {
 	go func() {
 		for {
@@ -136,14 +215,61 @@ func (d *downstream) mainLoop() error {
 	// start pinging the underlying connection
 	d.startPing(doneChan)
 
-	lastAmountChanges := int64(0)
-	recheckInterval := 1700
-	if d.sync.Options.Polling == false {
-		recheckInterval = 500
+	// cancel the notifications if we get interrupted
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-d.interrupt:
+			cancel()
+		case <-doneChan:
+		}
+	}()
+
+	// Older helpers do not support notifications
+	if hasFeature(d.capabilities, util.FeatureChangesNotify) == false {
+		return d.pollChanges()
+	}
+
+	// The remote side notifies us as soon as there are changes
+	notifyClient, err := d.client.ChangesNotify(ctx, &remote.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "start change notifications")
 	}
 
 	for {
-		// Check for changes remotely
+		_, err := notifyClient.Recv()
+		if err != nil {
+			select {
+			case <-d.interrupt:
+				return nil
+			default:
+			}
+
+			if status.Code(err) == codes.Unimplemented {
+				return d.pollChanges()
+			}
+
+			return errors.Wrap(err, "receive change notification")
+		}
+
+		err = d.syncNotifiedChanges()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// pollChanges checks the amount of remote changes periodically and syncs them as soon as they settled
+func (d *downstream) pollChanges() error {
+	lastAmountChanges := int64(0)
+	for {
+		select {
+		case <-d.interrupt:
+			return nil
+		case <-time.After(pollChangesInterval):
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
 		changeAmount, err := d.client.ChangesCount(ctx, &remote.Empty{})
 		cancel()
@@ -153,30 +279,75 @@ func (d *downstream) mainLoop() error {
 
 		// Compare change amount
 		if lastAmountChanges > 0 && changeAmount.Amount == lastAmountChanges {
-			d.sync.fileIndex.fileMapMutex.Lock()
-			changes, err := d.collectChanges()
-			d.sync.fileIndex.fileMapMutex.Unlock()
-			if err != nil {
-				return errors.Wrap(err, "collect changes")
-			}
-
-			err = d.applyChanges(changes, false)
+			err = d.syncNotifiedChanges()
 			if err != nil {
-				return errors.Wrap(err, "apply changes")
+				return err
 			}
 		}
 
-		select {
-		case <-d.interrupt:
-			return nil
-		case <-time.After(time.Duration(recheckInterval) * time.Millisecond):
-			break
-		}
-
 		lastAmountChanges = changeAmount.Amount
 	}
 }
 
+// notifyRemoteChanges records that the helper reported remote changes that are about to be synced
+func (d *downstream) notifyRemoteChanges() {
+	d.remoteChangesMutex.Lock()
+	defer d.remoteChangesMutex.Unlock()
+
+	if d.remoteChanges == nil {
+		d.remoteChanges = make(chan struct{})
+	}
+}
+
+// remoteChangesSynced returns a channel that is closed as soon as the notified remote changes are synced
+func (d *downstream) remoteChangesSynced() <-chan struct{} {
+	d.remoteChangesMutex.Lock()
+	defer d.remoteChangesMutex.Unlock()
+
+	if d.remoteChanges == nil {
+		synced := make(chan struct{})
+		close(synced)
+		return synced
+	}
+
+	return d.remoteChanges
+}
+
+// syncNotifiedChanges syncs the remote changes the helper notified us about and wakes up an upstream
+// that waits for them
+func (d *downstream) syncNotifiedChanges() error {
+	d.notifyRemoteChanges()
+	defer func() {
+		d.remoteChangesMutex.Lock()
+		defer d.remoteChangesMutex.Unlock()
+
+		close(d.remoteChanges)
+		d.remoteChanges = nil
+	}()
+
+	return d.syncChanges()
+}
+
+// syncChanges collects the remote changes and applies them locally
+func (d *downstream) syncChanges() error {
+	d.sync.fileIndex.fileMapMutex.Lock()
+	changes, err := d.collectChanges()
+	if err == nil {
+		changes = d.resolveConflicts(changes)
+	}
+	d.sync.fileIndex.fileMapMutex.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "collect changes")
+	}
+
+	err = d.applyChanges(changes, false)
+	if err != nil {
+		return errors.Wrap(err, "apply changes")
+	}
+
+	return nil
+}
+
 func (d *downstream) shouldKeep(change *remote.Change) bool {
 	// Is a delete change?
 	if change.ChangeType == remote.ChangeType_DELETE {
@@ -216,6 +387,11 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 	// Remove all files and folders that should be deleted first and we ignore errors
 	d.remove(remove, force)
 
+	// Large files that exist locally already are downloaded as delta
+	if len(download) > 0 {
+		download = d.downloadDeltas(download)
+	}
+
 	// Extract downloaded archive
 	if len(download) > 0 {
 		for i := 0; i < syncRetries; i++ {
@@ -283,16 +459,15 @@ func (d *downstream) downloadFiles(writer io.WriteCloser, changes []*remote.Chan
 	defer cancel()
 
 	// Print log message
+	filesize := int64(0)
+	for _, v := range changes {
+		filesize += v.Size
+	}
 	if len(changes) <= 3 || d.sync.Options.Verbose {
 		for _, element := range changes {
 			d.sync.log.Infof("Downstream - Download file '.%s', uncompressed: ~%0.2f KB", element.Path, float64(element.Size)/1024.0)
 		}
 	} else if len(changes) > 3 {
-		filesize := int64(0)
-		for _, v := range changes {
-			filesize += v.Size
-		}
-
 		d.sync.log.Infof("Downstream - Download %d file(s) (Uncompressed: ~%0.2f KB)", len(changes), float64(filesize)/1024.0)
 	}
 
@@ -309,7 +484,8 @@ func (d *downstream) downloadFiles(writer io.WriteCloser, changes []*remote.Chan
 
 		if len(downloadFiles) >= downloadFilesBufferSize {
 			err = downloadClient.Send(&remote.Paths{
-				Paths: downloadFiles,
+				Paths:       downloadFiles,
+				Compression: d.compression,
 			})
 			if err != nil {
 				return errors.Wrap(err, "send path")
@@ -321,7 +497,8 @@ func (d *downstream) downloadFiles(writer io.WriteCloser, changes []*remote.Chan
 
 	if len(downloadFiles) >= 0 {
 		err = downloadClient.Send(&remote.Paths{
-			Paths: downloadFiles,
+			Paths:       downloadFiles,
+			Compression: d.compression,
 		})
 		if err != nil {
 			return errors.Wrap(err, "send path")
@@ -335,9 +512,12 @@ func (d *downstream) downloadFiles(writer io.WriteCloser, changes []*remote.Chan
 	}
 
 	// Download tar archive into the writer
+	start := time.Now()
+	received := int64(0)
 	for {
 		chunk, err := downloadClient.Recv()
 		if chunk != nil {
+			received += int64(len(chunk.Content))
 			_, err := writer.Write(chunk.Content)
 			if err != nil {
 				return errors.Wrap(err, "write chunk")
@@ -352,6 +532,7 @@ func (d *downstream) downloadFiles(writer io.WriteCloser, changes []*remote.Chan
 		}
 	}
 
+	d.sync.logTransferStats("Downstream", d.compression, filesize, received, start)
 	return nil
 }
 
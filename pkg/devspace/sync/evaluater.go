@@ -61,6 +61,11 @@ func shouldUpload(s *Sync, fileInformation *FileInformation) bool {
 		}
 	}
 
+	// Exclude files that are too large
+	if fileInformation.IsDirectory == false && s.exceedsSizeLimit(fileInformation.Name, fileInformation.Size) {
+		return false
+	}
+
 	// Check if we already tracked the path
 	if s.fileIndex.fileMap[fileInformation.Name] != nil {
 		// Folder already exists, don't send change
@@ -73,6 +78,11 @@ func shouldUpload(s *Sync, fileInformation *FileInformation) bool {
 			return false
 		}
 
+		// Preserved symlinks only change if they point somewhere else
+		if fileInformation.LinkTarget != "" || s.fileIndex.fileMap[fileInformation.Name].LinkTarget != "" {
+			return fileInformation.LinkTarget != s.fileIndex.fileMap[fileInformation.Name].LinkTarget
+		}
+
 		// File did not change or was changed by downstream
 		if fileInformation.Mtime == s.fileIndex.fileMap[fileInformation.Name].Mtime && fileInformation.Size == s.fileIndex.fileMap[fileInformation.Name].Size {
 			return false
@@ -84,8 +94,18 @@ func shouldUpload(s *Sync, fileInformation *FileInformation) bool {
 
 // s.fileIndex needs to be locked before this function is called
 func shouldDownload(change *remote.Change, s *Sync) bool {
+	// Exclude files that are too large, the helper marks them if it has size limits as well
+	if change.IsDir == false && (s.exceedsSizeLimit(change.Path, change.Size) || change.SizeExceeded) {
+		return false
+	}
+
 	// Does file already exist in the filemap?
 	if s.fileIndex.fileMap[change.Path] != nil {
+		// Preserved symlinks only change if they point somewhere else
+		if change.LinkTarget != "" || s.fileIndex.fileMap[change.Path].LinkTarget != "" {
+			return change.LinkTarget != s.fileIndex.fileMap[change.Path].LinkTarget
+		}
+
 		// Don't override folders that exist in the filemap
 		if change.IsDir == false {
 			// Redownload file if mtime is newer than saved one
@@ -122,6 +142,16 @@ func shouldRemoveLocal(absFilepath string, fileInformation *FileInformation, s *
 	// and shouldUpload are always false, and hence it never appears in the fileMap and is not copied to the remove fileMap clone
 	// in the beginning of the downstream mainLoop
 
+	// Preserved symlinks are only deleted if they still point to the same target
+	if fileInformation.LinkTarget != "" || (s.fileIndex.fileMap[fileInformation.Name] != nil && s.fileIndex.fileMap[fileInformation.Name].LinkTarget != "") {
+		target, err := os.Readlink(absFilepath)
+		if err != nil {
+			return false
+		}
+
+		return force || (s.fileIndex.fileMap[fileInformation.Name] != nil && s.fileIndex.fileMap[fileInformation.Name].LinkTarget == target)
+	}
+
 	// Only delete if mtime and size did not change
 	stat, err := os.Stat(absFilepath)
 	if err != nil {
@@ -164,3 +194,45 @@ func shouldRemoveLocal(absFilepath string, fileInformation *FileInformation, s *
 
 	return false
 }
+
+// s.fileIndex needs to be locked before this function is called
+// A remote change is a conflict if the following conditions are met:
+// - The change is a file change that is not excluded from upload, because then local changes are never synced anyways
+// - The local file changed in terms of size and mtime since both sides last agreed on its state in the d.config.fileMap
+// - The remote file changed in terms of size and mtime since both sides last agreed on its state
+// - The local and remote file are not equal in terms of size and mtime
+func isConflict(change *remote.Change, stat os.FileInfo, s *Sync) bool {
+	if change.ChangeType == remote.ChangeType_DELETE || change.IsDir || stat == nil || stat.IsDir() {
+		return false
+	}
+
+	// Local changes are never uploaded for these paths
+	if s.uploadIgnoreMatcher != nil {
+		if util.MatchesPath(s.uploadIgnoreMatcher, change.Path, false) {
+			return false
+		}
+	}
+
+	// Local and remote file are already the same
+	if stat.ModTime().Unix() == change.MtimeUnix && stat.Size() == change.Size {
+		return false
+	}
+
+	// If the file is not tracked yet it was created on both sides
+	lastSynced := s.fileIndex.fileMap[change.Path]
+	if lastSynced == nil {
+		return true
+	}
+
+	// Local file did not change since the last sync
+	if stat.ModTime().Unix() == lastSynced.Mtime && stat.Size() == lastSynced.Size {
+		return false
+	}
+
+	// Remote file did not change since the last sync
+	if change.MtimeUnix == lastSynced.Mtime && change.Size == lastSynced.Size {
+		return false
+	}
+
+	return true
+}
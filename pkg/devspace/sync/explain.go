@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/pkg/errors"
+)
+
+// Directions a path can be synced in
+const (
+	DirectionUpload   = "upload"
+	DirectionDownload = "download"
+)
+
+// Exclusion explains if and why a path is excluded from the sync in a certain direction
+type Exclusion struct {
+	Direction string `json:"direction"`
+	Excluded  bool   `json:"excluded"`
+
+	// Rule is the exclude path that decided whether the path is excluded
+	// and Source the config key the rule came from
+	Rule   string `json:"rule,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+type excludeRule struct {
+	path   string
+	source string
+}
+
+// Explain evaluates the relative path against the exclude paths of the given options the same way the
+// upstream and downstream do and returns for each direction which rule decides if the path is synced
+func Explain(options Options, relativePath string, isDir bool) ([]*Exclusion, error) {
+	excludePaths := []excludeRule{}
+	for _, path := range options.ExcludePaths {
+		excludePaths = append(excludePaths, excludeRule{path: path, source: "excludePaths"})
+	}
+
+	// These are added by NewSync
+	excludePaths = append(excludePaths, excludeRule{path: ".devspace/", source: "default"})
+	uploadExcludePaths := []excludeRule{}
+	for _, path := range options.UploadExcludePaths {
+		uploadExcludePaths = append(uploadExcludePaths, excludeRule{path: path, source: "uploadExcludePaths"})
+	}
+	if options.ConflictStrategy == latest.ConflictStrategyKeepBoth {
+		uploadExcludePaths = append(uploadExcludePaths, excludeRule{path: "*" + conflictFileSuffix, source: "conflictStrategy"})
+	}
+
+	downloadExcludePaths := []excludeRule{}
+	for _, path := range options.DownloadExcludePaths {
+		downloadExcludePaths = append(downloadExcludePaths, excludeRule{path: path, source: "downloadExcludePaths"})
+	}
+
+	exclusions := []*Exclusion{}
+	for _, direction := range []struct {
+		name     string
+		disabled bool
+		rules    []excludeRule
+	}{
+		{name: DirectionUpload, disabled: options.UpstreamDisabled, rules: uploadExcludePaths},
+		{name: DirectionDownload, disabled: options.DownstreamDisabled, rules: downloadExcludePaths},
+	} {
+		if direction.disabled {
+			continue
+		}
+
+		// The exclude paths and the exclude paths of a direction are separate matchers and a path is
+		// excluded if one of them excludes it, so a negation cannot include a path of the other matcher
+		exclusion := &Exclusion{Direction: direction.name}
+		for _, rules := range [][]excludeRule{excludePaths, direction.rules} {
+			rule, excluded, err := matchingRule(rules, relativePath, isDir)
+			if err != nil {
+				return nil, errors.Wrap(err, "match exclude paths")
+			} else if rule == nil {
+				continue
+			}
+
+			exclusion.Excluded = excluded
+			exclusion.Rule = rule.path
+			exclusion.Source = rule.source
+			if excluded {
+				break
+			}
+		}
+
+		exclusions = append(exclusions, exclusion)
+	}
+
+	return exclusions, nil
+}
+
+// matchingRule returns the rule that decides whether the path is excluded by the given rules or nil if no
+// rule matches the path
+func matchingRule(rules []excludeRule, relativePath string, isDir bool) (*excludeRule, bool, error) {
+	paths := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		paths = append(paths, rule.path)
+	}
+
+	match, excluded, err := ignoreparser.MatchingRule(paths, relativePath, isDir)
+	if err != nil || match < 0 {
+		return nil, false, err
+	}
+
+	return &rules[match], excluded, nil
+}
@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+)
+
+func TestExplain(t *testing.T) {
+	testCases := map[string]struct {
+		options  Options
+		path     string
+		isDir    bool
+		expected []*Exclusion
+	}{
+		"not excluded": {
+			options: Options{ExcludePaths: []string{"node_modules/"}},
+			path:    "src/index.js",
+			expected: []*Exclusion{
+				{Direction: DirectionUpload},
+				{Direction: DirectionDownload},
+			},
+		},
+		"excluded in both directions": {
+			options: Options{ExcludePaths: []string{"node_modules/"}},
+			path:    "node_modules",
+			isDir:   true,
+			expected: []*Exclusion{
+				{Direction: DirectionUpload, Excluded: true, Rule: "node_modules/", Source: "excludePaths"},
+				{Direction: DirectionDownload, Excluded: true, Rule: "node_modules/", Source: "excludePaths"},
+			},
+		},
+		"upload exclude and download negation": {
+			options: Options{
+				ExcludePaths:         []string{"*.log"},
+				UploadExcludePaths:   []string{"logs/"},
+				DownloadExcludePaths: []string{"!logs/app.log"},
+			},
+			path: "logs/app.log",
+			expected: []*Exclusion{
+				{Direction: DirectionUpload, Excluded: true, Rule: "*.log", Source: "excludePaths"},
+				{Direction: DirectionDownload, Excluded: true, Rule: "*.log", Source: "excludePaths"},
+			},
+		},
+		"download negation within download exclude": {
+			options: Options{
+				ExcludePaths:         []string{"*.tmp"},
+				UploadExcludePaths:   []string{"logs/"},
+				DownloadExcludePaths: []string{"logs/*", "!logs/app.log"},
+			},
+			path: "logs/app.log",
+			expected: []*Exclusion{
+				{Direction: DirectionUpload, Excluded: true, Rule: "logs/", Source: "uploadExcludePaths"},
+				{Direction: DirectionDownload, Rule: "!logs/app.log", Source: "downloadExcludePaths"},
+			},
+		},
+		"defaults": {
+			options: Options{ConflictStrategy: latest.ConflictStrategyKeepBoth, DownstreamDisabled: true},
+			path:    "main.go.conflict",
+			expected: []*Exclusion{
+				{Direction: DirectionUpload, Excluded: true, Rule: "*.conflict", Source: "conflictStrategy"},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		exclusions, err := Explain(testCase.options, testCase.path, testCase.isDir)
+		if err != nil {
+			t.Fatalf("Test case %s: unexpected error: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(exclusions, testCase.expected) {
+			t.Fatalf("Test case %s: unexpected exclusions", name)
+		}
+	}
+}
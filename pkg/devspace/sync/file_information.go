@@ -14,6 +14,9 @@ type FileInformation struct {
 
 	IsSymbolicLink bool
 	IsDirectory    bool
+
+	// LinkTarget is the target of a symlink that is synced as symlink
+	LinkTarget string
 }
 
 // Sys implements interface
@@ -50,5 +53,6 @@ func parseFileInformation(change *remote.Change) *FileInformation {
 		Mtime:       change.MtimeUnix,
 		MtimeNano:   change.MtimeUnixNano,
 		IsDirectory: change.IsDir,
+		LinkTarget:  change.LinkTarget,
 	}
 }
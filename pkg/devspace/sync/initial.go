@@ -16,6 +16,10 @@ import (
 
 type initialSyncer struct {
 	o *initialSyncOptions
+
+	// remoteChecksums holds the remote content checksums of files
+	// that need to be compared by content
+	remoteChecksums map[string]string
 }
 
 type initialSyncOptions struct {
@@ -32,10 +36,26 @@ type initialSyncOptions struct {
 	DownstreamDisabled bool
 	FileIndex          *fileIndex
 
+	// LastState is the file index of the last sync session with the same container. If set, files that
+	// were only changed or deleted on one side since then are synced in that direction
+	LastState map[string]*FileInformation
+
 	ApplyRemote func(changes []*FileInformation, remove bool)
 	ApplyLocal  func(changes []*remote.Change, force bool) error
 	AddSymlink  func(relativePath, absPath string) (os.FileInfo, error)
 
+	// Symlinks defines if local symlinks are followed, preserved or skipped. PreservedSymlink
+	// returns the file information of a symlink that is synced as symlink
+	Symlinks         latest.SymlinksMode
+	PreservedSymlink func(relativePath, absPath string, lstat os.FileInfo) *FileInformation
+
+	// ExceedsSizeLimit checks if a local file is too large to be synced
+	ExceedsSizeLimit func(relativePath string, size int64) bool
+
+	// RemoteChecksums retrieves the remote content checksums for the given paths
+	// and is only used if CompareBy is checksum
+	RemoteChecksums func(paths []string) (map[string]string, error)
+
 	UpstreamDone   func()
 	DownstreamDone func()
 
@@ -50,69 +70,120 @@ func newInitialSyncer(options *initialSyncOptions) *initialSyncer {
 	return &initialSyncer{o: options}
 }
 
+// initialSyncPlan holds the changes the initial sync applies on both sides
+type initialSyncPlan struct {
+	// removeRemote and upload are applied remotely
+	removeRemote []*FileInformation
+	upload       []*FileInformation
+
+	// removeLocal and download are applied locally
+	removeLocal []*remote.Change
+	download    []*remote.Change
+}
+
 func (i *initialSyncer) Run(remoteState map[string]*FileInformation) error {
+	plan, err := i.plan(remoteState)
+	if err != nil {
+		return err
+	}
+
+	// Upstream initial sync
+	go func() {
+		if len(plan.removeRemote) > 0 {
+			i.o.ApplyRemote(plan.removeRemote, true)
+		}
+		if len(plan.upload) > 0 {
+			i.o.ApplyRemote(plan.upload, false)
+		}
+
+		i.o.UpstreamDone()
+	}()
+
+	// Download changes if enabled
+	if len(plan.removeLocal) > 0 {
+		// We already made sure the local files did not change since the last sync session
+		// or the strategy is mirrorRemote
+		err = i.o.ApplyLocal(plan.removeLocal, true)
+		if err != nil {
+			return errors.Wrap(err, "apply changes")
+		}
+	}
+	if len(plan.download) > 0 {
+		err = i.o.ApplyLocal(plan.download, false)
+		if err != nil {
+			return errors.Wrap(err, "apply changes")
+		}
+	}
+
+	i.o.DownstreamDone()
+	return nil
+}
+
+// plan calculates which changes the initial sync should apply locally and remotely
+func (i *initialSyncer) plan(remoteState map[string]*FileInformation) (*initialSyncPlan, error) {
 	// Here we calculate the delta between the remote and local state, the result of this operation
 	// are files we should download (new and override) and files we should upload (new and override)
 	download := remoteState
 	upload, err := i.CalculateDelta(download)
 	if err != nil {
-		return errors.Wrap(err, "diff server client")
+		return nil, errors.Wrap(err, "diff server client")
 	}
 
-	// Upstream initial sync
-	go func() {
-		if i.o.UpstreamDisabled == false {
-			// Remove remote if mirror local
-			if len(download) > 0 && i.o.Strategy == latest.InitialSyncStrategyMirrorLocal {
-				deleteRemote := make([]*FileInformation, 0, len(download))
-				for _, element := range download {
-					if i.o.UploadIgnoreMatcher != nil && util.MatchesPath(i.o.UploadIgnoreMatcher, element.Name, element.IsDirectory) {
-						continue
-					}
-
-					deleteRemote = append(deleteRemote, &FileInformation{
-						Name:        element.Name,
-						IsDirectory: element.IsDirectory,
-					})
+	// Files that were deleted on one side since the last sync session are removed on the other side
+	upload, removeRemote, removeLocal := i.deletedSinceLastState(download, upload)
+	if len(removeRemote) > 0 || len(removeLocal) > 0 {
+		i.o.Log.Infof("Initial Sync - %d file(s) were deleted locally and %d file(s) remotely since the last sync session", len(removeRemote), len(removeLocal))
+	}
+
+	plan := &initialSyncPlan{}
+	if i.o.UpstreamDisabled == false {
+		plan.removeRemote = removeRemote
+
+		// Remove remote if mirror local
+		if i.o.Strategy == latest.InitialSyncStrategyMirrorLocal {
+			for _, element := range download {
+				if i.o.UploadIgnoreMatcher != nil && util.MatchesPath(i.o.UploadIgnoreMatcher, element.Name, element.IsDirectory) {
+					continue
 				}
 
-				i.o.ApplyRemote(deleteRemote, true)
+				plan.removeRemote = append(plan.removeRemote, &FileInformation{
+					Name:        element.Name,
+					Size:        element.Size,
+					IsDirectory: element.IsDirectory,
+				})
 			}
+		}
 
-			// Upload remote if not mirror remote
-			if len(upload) > 0 {
-				if i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
-					// only apply the ones that match the downstream ignore matcher
-					changes := []*FileInformation{}
-					for _, element := range upload {
-						if i.o.DownloadIgnoreMatcher != nil && util.MatchesPath(i.o.DownloadIgnoreMatcher, element.Name, element.IsDirectory) {
-							changes = append(changes, element)
-						}
-					}
-					if len(changes) > 0 {
-						i.o.ApplyRemote(changes, false)
-					}
-				} else {
-					i.o.ApplyRemote(upload, false)
+		// Upload remote if not mirror remote
+		if i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
+			// only apply the ones that match the downstream ignore matcher
+			for _, element := range upload {
+				if i.o.DownloadIgnoreMatcher != nil && util.MatchesPath(i.o.DownloadIgnoreMatcher, element.Name, element.IsDirectory) {
+					plan.upload = append(plan.upload, element)
 				}
 			}
-
+		} else {
+			plan.upload = upload
 		}
+	}
 
-		i.o.UpstreamDone()
-	}()
-
-	// Download changes if enabled
 	if i.o.DownstreamDisabled == false {
+		for _, element := range removeLocal {
+			plan.removeLocal = append(plan.removeLocal, &remote.Change{
+				ChangeType: remote.ChangeType_DELETE,
+				Path:       element.Name,
+				IsDir:      element.IsDirectory,
+			})
+		}
+
 		// Remove local if mirror remote
-		if len(upload) > 0 && i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
-			remoteChanges := make([]*remote.Change, 0, len(upload))
+		if i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
 			for _, element := range upload {
 				if i.o.DownloadIgnoreMatcher != nil && util.MatchesPath(i.o.DownloadIgnoreMatcher, element.Name, element.IsDirectory) {
 					continue
 				}
 
-				remoteChanges = append(remoteChanges, &remote.Change{
+				plan.removeLocal = append(plan.removeLocal, &remote.Change{
 					ChangeType:    remote.ChangeType_DELETE,
 					Path:          element.Name,
 					MtimeUnix:     element.Mtime,
@@ -121,59 +192,27 @@ func (i *initialSyncer) Run(remoteState map[string]*FileInformation) error {
 					IsDir:         element.IsDirectory,
 				})
 			}
-
-			err = i.o.ApplyLocal(remoteChanges, true)
-			if err != nil {
-				return errors.Wrap(err, "apply changes")
-			}
 		}
 
 		// Download local if not mirror local
-		if len(download) > 0 {
-			if i.o.Strategy == latest.InitialSyncStrategyMirrorLocal {
-				// only apply the ones that match the upstream ignore matcher
-				remoteChanges := make([]*remote.Change, 0, len(download))
-				for _, element := range download {
-					if i.o.UploadIgnoreMatcher != nil && util.MatchesPath(i.o.UploadIgnoreMatcher, element.Name, element.IsDirectory) {
-						remoteChanges = append(remoteChanges, &remote.Change{
-							ChangeType:    remote.ChangeType_CHANGE,
-							Path:          element.Name,
-							MtimeUnix:     element.Mtime,
-							MtimeUnixNano: element.MtimeNano,
-							Size:          element.Size,
-							IsDir:         element.IsDirectory,
-						})
-					}
-				}
-				if len(remoteChanges) > 0 {
-					err = i.o.ApplyLocal(remoteChanges, false)
-					if err != nil {
-						return errors.Wrap(err, "apply changes")
-					}
-				}
-			} else {
-				remoteChanges := make([]*remote.Change, 0, len(download))
-				for _, element := range download {
-					remoteChanges = append(remoteChanges, &remote.Change{
-						ChangeType:    remote.ChangeType_CHANGE,
-						Path:          element.Name,
-						MtimeUnix:     element.Mtime,
-						MtimeUnixNano: element.MtimeNano,
-						Size:          element.Size,
-						IsDir:         element.IsDirectory,
-					})
-				}
-
-				err = i.o.ApplyLocal(remoteChanges, false)
-				if err != nil {
-					return errors.Wrap(err, "apply changes")
-				}
+		for _, element := range download {
+			// only apply the ones that match the upstream ignore matcher
+			if i.o.Strategy == latest.InitialSyncStrategyMirrorLocal && (i.o.UploadIgnoreMatcher == nil || util.MatchesPath(i.o.UploadIgnoreMatcher, element.Name, element.IsDirectory) == false) {
+				continue
 			}
+
+			plan.download = append(plan.download, &remote.Change{
+				ChangeType:    remote.ChangeType_CHANGE,
+				Path:          element.Name,
+				MtimeUnix:     element.Mtime,
+				MtimeUnixNano: element.MtimeNano,
+				Size:          element.Size,
+				IsDir:         element.IsDirectory,
+			})
 		}
 	}
 
-	i.o.DownstreamDone()
-	return nil
+	return plan, nil
 }
 
 func (i *initialSyncer) CalculateDelta(remoteState map[string]*FileInformation) ([]*FileInformation, error) {
@@ -184,12 +223,145 @@ func (i *initialSyncer) CalculateDelta(remoteState map[string]*FileInformation)
 		strategy = latest.InitialSyncStrategyPreferLocal
 	}
 
+	if i.o.CompareBy == latest.InitialSyncCompareByChecksum {
+		err := i.collectChecksums(remoteState)
+		if err != nil {
+			return nil, errors.Wrap(err, "collect checksums")
+		}
+	}
+
 	return i.deltaPath(i.o.LocalPath, remoteState, strategy, false)
 }
 
+// usesLastState checks if the state of the last sync session should be used to decide in which
+// direction a change is synced. The mirror strategies always prefer one side
+func (i *initialSyncer) usesLastState() bool {
+	return i.o.LastState != nil && i.o.Strategy != latest.InitialSyncStrategyMirrorLocal && i.o.Strategy != latest.InitialSyncStrategyMirrorRemote
+}
+
+// deletedSinceLastState determines the files and folders that still exist on one side, but were deleted on the
+// other side since the last sync session and did not change otherwise. These are removed from the download and
+// upload changes and returned as the paths to remove remotely and locally
+func (i *initialSyncer) deletedSinceLastState(download map[string]*FileInformation, upload []*FileInformation) ([]*FileInformation, []*FileInformation, []*FileInformation) {
+	if i.usesLastState() == false {
+		return upload, nil, nil
+	}
+
+	// Remote files that did not change were deleted locally
+	removeRemote := []*FileInformation{}
+	remoteDirs := []*FileInformation{}
+	for name, element := range download {
+		lastSynced := i.o.LastState[name]
+		if lastSynced == nil || element.IsSymbolicLink || lastSynced.IsDirectory != element.IsDirectory {
+			continue
+		} else if element.IsDirectory {
+			remoteDirs = append(remoteDirs, element)
+			continue
+		}
+
+		if element.Mtime == lastSynced.Mtime && element.Size == lastSynced.Size && i.existsLocally(name) == false {
+			removeRemote = append(removeRemote, &FileInformation{Name: name})
+			delete(download, name)
+		}
+	}
+
+	// Remote folders are only removed if nothing inside them is downloaded
+	keepDirs := map[string]bool{}
+	for name := range download {
+		for dir := path.Dir(name); dir != "/" && dir != "." && keepDirs[dir] == false; dir = path.Dir(dir) {
+			keepDirs[dir] = true
+		}
+	}
+	for _, element := range remoteDirs {
+		if keepDirs[element.Name] == false && i.existsLocally(element.Name) == false {
+			removeRemote = append(removeRemote, &FileInformation{Name: element.Name, IsDirectory: true})
+			delete(download, element.Name)
+		}
+	}
+
+	// Local files and empty folders that did not change were deleted remotely
+	newUpload := make([]*FileInformation, 0, len(upload))
+	removeLocal := []*FileInformation{}
+	i.o.FileIndex.Lock()
+	for _, element := range upload {
+		lastSynced := i.o.LastState[element.Name]
+		if lastSynced != nil && i.o.FileIndex.fileMap[element.Name] == nil && lastSynced.IsDirectory == element.IsDirectory && (element.IsDirectory || (element.Mtime == lastSynced.Mtime && element.Size == lastSynced.Size)) {
+			removeLocal = append(removeLocal, element)
+			continue
+		}
+
+		newUpload = append(newUpload, element)
+	}
+	i.o.FileIndex.Unlock()
+
+	return newUpload, removeRemote, removeLocal
+}
+
+func (i *initialSyncer) existsLocally(relativePath string) bool {
+	_, err := os.Lstat(path.Join(i.o.LocalPath, relativePath))
+	return err == nil
+}
+
+// collectChecksums retrieves the remote checksums for all files that exist locally and remotely
+// with the same size but a different mtime, because only for those the content has to be compared
+func (i *initialSyncer) collectChecksums(remoteState map[string]*FileInformation) error {
+	paths := make([]string, 0, 64)
+	for _, element := range remoteState {
+		if element.IsDirectory || element.IsSymbolicLink {
+			continue
+		}
+
+		stat, err := os.Lstat(path.Join(i.o.LocalPath, element.Name))
+		if err != nil || stat.Mode().IsRegular() == false {
+			continue
+		}
+
+		if stat.Size() == element.Size && stat.ModTime().Unix() != element.Mtime {
+			paths = append(paths, element.Name)
+		}
+	}
+
+	i.remoteChecksums = map[string]string{}
+	if len(paths) == 0 || i.o.RemoteChecksums == nil {
+		return nil
+	}
+
+	i.o.Log.Infof("Initial Sync - Compare %d file(s) by checksum", len(paths))
+	checksums, err := i.o.RemoteChecksums(paths)
+	if err != nil {
+		return err
+	}
+
+	i.remoteChecksums = checksums
+	return nil
+}
+
+// contentEquals checks if the local file has the same content as the remote file
+func (i *initialSyncer) contentEquals(fileInformation *FileInformation) bool {
+	remoteChecksum, ok := i.remoteChecksums[fileInformation.Name]
+	if !ok {
+		return false
+	}
+
+	localChecksum, err := util.FileChecksum(path.Join(i.o.LocalPath, fileInformation.Name))
+	if err != nil {
+		return false
+	}
+
+	return localChecksum == remoteChecksum
+}
+
 func (i *initialSyncer) deltaPath(absPath string, remoteState map[string]*FileInformation, strategy latest.InitialSyncStrategy, ignore bool) ([]*FileInformation, error) {
 	relativePath := getRelativeFromFullPath(absPath, i.o.LocalPath)
 
+	// Symlinks that are not followed are synced as they are or not at all
+	if i.o.Symlinks != "" && i.o.Symlinks != latest.SymlinksModeFollow && ignore == false {
+		lstat, err := os.Lstat(absPath)
+		if err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+			return i.deltaSymlink(relativePath, absPath, lstat, remoteState, strategy), nil
+		}
+	}
+
 	// We skip files that are suddenly not there anymore
 	stat, err := os.Stat(absPath)
 	if err != nil {
@@ -252,6 +424,12 @@ func (i *initialSyncer) deltaPath(absPath string, remoteState map[string]*FileIn
 		return i.deltaDir(absPath, stat, remoteState, strategy, ignore)
 	}
 
+	// Files that are too large are neither uploaded nor replaced with the remote version
+	if ignore == false && i.o.ExceedsSizeLimit != nil && i.o.ExceedsSizeLimit(relativePath, stat.Size()) {
+		delete(remoteState, relativePath)
+		return nil, nil
+	}
+
 	if ignore == false {
 		fileInfo := &FileInformation{
 			Name:           relativePath,
@@ -279,6 +457,38 @@ func (i *initialSyncer) deltaPath(absPath string, remoteState map[string]*FileIn
 	return nil, nil
 }
 
+func (i *initialSyncer) deltaSymlink(relativePath, absPath string, lstat os.FileInfo, remoteState map[string]*FileInformation, strategy latest.InitialSyncStrategy) []*FileInformation {
+	// Upload excluded symlinks are replaced with the remote version
+	if i.o.UploadIgnoreMatcher != nil {
+		stat, err := os.Stat(absPath)
+		if util.MatchesPath(i.o.UploadIgnoreMatcher, relativePath, err == nil && stat.IsDir()) {
+			return nil
+		}
+	}
+
+	var fileInfo *FileInformation
+	if i.o.PreservedSymlink != nil {
+		fileInfo = i.o.PreservedSymlink(relativePath, absPath, lstat)
+	}
+	if fileInfo == nil {
+		// We never replace a symlink that is not synced
+		delete(remoteState, relativePath)
+		return nil
+	}
+
+	i.o.FileIndex.Lock()
+	action := i.decide(fileInfo, strategy)
+	i.o.FileIndex.Unlock()
+	if action == uploadAction {
+		delete(remoteState, relativePath)
+		return []*FileInformation{fileInfo}
+	} else if action == noAction {
+		delete(remoteState, relativePath)
+	}
+
+	return nil
+}
+
 func (i *initialSyncer) deltaDir(filepath string, stat os.FileInfo, remoteState map[string]*FileInformation, strategy latest.InitialSyncStrategy, ignore bool) ([]*FileInformation, error) {
 	relativePath := getRelativeFromFullPath(filepath, i.o.LocalPath)
 
@@ -359,12 +569,34 @@ func (i *initialSyncer) decide(fileInformation *FileInformation, strategy latest
 			return noAction
 		}
 
-		// File did not change or was changed by downstream
-		if fileInformation.Size == i.o.FileIndex.fileMap[fileInformation.Name].Size {
+		// Preserved symlinks did not change if they point to the same target
+		if fileInformation.LinkTarget != "" || i.o.FileIndex.fileMap[fileInformation.Name].LinkTarget != "" {
+			if fileInformation.LinkTarget == i.o.FileIndex.fileMap[fileInformation.Name].LinkTarget {
+				return noAction
+			}
+		} else if fileInformation.Size == i.o.FileIndex.fileMap[fileInformation.Name].Size {
+			// File did not change or was changed by downstream
 			if fileInformation.Mtime == i.o.FileIndex.fileMap[fileInformation.Name].Mtime {
 				return noAction
 			} else if i.o.CompareBy == latest.InitialSyncCompareBySize {
 				return noAction
+			} else if i.o.CompareBy == latest.InitialSyncCompareByChecksum && i.contentEquals(fileInformation) {
+				return noAction
+			}
+		}
+
+		// If the file only changed on one side since the last sync session, we sync it in that direction
+		if i.usesLastState() {
+			lastSynced := i.o.LastState[fileInformation.Name]
+			if lastSynced != nil && lastSynced.IsDirectory == false {
+				remoteInformation := i.o.FileIndex.fileMap[fileInformation.Name]
+				localChanged := fileInformation.Mtime != lastSynced.Mtime || fileInformation.Size != lastSynced.Size
+				remoteChanged := remoteInformation.Mtime != lastSynced.Mtime || remoteInformation.Size != lastSynced.Size
+				if localChanged && remoteChanged == false {
+					return uploadAction
+				} else if localChanged == false && remoteChanged {
+					return downloadAction
+				}
 			}
 		}
 
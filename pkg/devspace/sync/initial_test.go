@@ -0,0 +1,201 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestInitialSyncCompareByChecksum(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	err = ioutil.WriteFile(filepath.Join(local, "same"), []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(local, "different"), []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sameChecksum, err := util.FileChecksum(filepath.Join(local, "same"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the remote files have the same size but are older than the local ones
+	remoteMtime := time.Now().Add(-time.Hour).Unix()
+	remoteState := map[string]*FileInformation{
+		"/same":      {Name: "/same", Size: 7, Mtime: remoteMtime},
+		"/different": {Name: "/different", Size: 7, Mtime: remoteMtime},
+	}
+
+	fileIndex := newFileIndex()
+	for _, element := range remoteState {
+		fileIndex.Set(element)
+	}
+
+	var requestedPaths []string
+	syncer := newInitialSyncer(&initialSyncOptions{
+		LocalPath: local,
+		Strategy:  latest.InitialSyncStrategyPreferLocal,
+		CompareBy: latest.InitialSyncCompareByChecksum,
+		FileIndex: fileIndex,
+		RemoteChecksums: func(paths []string) (map[string]string, error) {
+			requestedPaths = paths
+			return map[string]string{
+				"/same":      sameChecksum,
+				"/different": "0000",
+			}, nil
+		},
+		AddSymlink: func(relativePath, absPath string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		Log: log.Discard,
+	})
+
+	upload, err := syncer.CalculateDelta(remoteState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requestedPaths) != 2 {
+		t.Fatalf("Expected checksums for 2 paths to be requested, got %d", len(requestedPaths))
+	}
+	if len(upload) != 1 || upload[0].Name != "/different" {
+		t.Fatalf("Expected only /different to be uploaded, got %#+v", upload)
+	}
+	if len(remoteState) != 0 {
+		t.Fatalf("Expected nothing to download, got %d files", len(remoteState))
+	}
+}
+
+func TestInitialSyncLastState(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	lastSynced := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"changedLocal", "changedRemote", "deletedRemote"} {
+		err = ioutil.WriteFile(filepath.Join(local, name), []byte("content"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if name != "changedLocal" {
+			err = os.Chtimes(filepath.Join(local, name), lastSynced, lastSynced)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	lastState := map[string]*FileInformation{}
+	for _, name := range []string{"/changedLocal", "/changedRemote", "/deletedRemote", "/deletedLocal"} {
+		lastState[name] = &FileInformation{Name: name, Size: 7, Mtime: lastSynced.Unix()}
+	}
+
+	// the remote changedLocal is older than the remote changedRemote, so without the last state
+	// preferRemote would download changedLocal
+	remoteState := map[string]*FileInformation{
+		"/changedLocal":  {Name: "/changedLocal", Size: 7, Mtime: lastSynced.Unix()},
+		"/changedRemote": {Name: "/changedRemote", Size: 8, Mtime: time.Now().Unix()},
+		"/deletedLocal":  {Name: "/deletedLocal", Size: 7, Mtime: lastSynced.Unix()},
+	}
+
+	fileIndex := newFileIndex()
+	for _, element := range remoteState {
+		fileIndex.Set(element)
+	}
+
+	syncer := newInitialSyncer(&initialSyncOptions{
+		LocalPath: local,
+		Strategy:  latest.InitialSyncStrategyPreferRemote,
+		FileIndex: fileIndex,
+		LastState: lastState,
+		AddSymlink: func(relativePath, absPath string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		Log: log.Discard,
+	})
+
+	upload, err := syncer.CalculateDelta(remoteState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload, removeRemote, removeLocal := syncer.deletedSinceLastState(remoteState, upload)
+	if len(upload) != 1 || upload[0].Name != "/changedLocal" {
+		t.Fatalf("Expected only /changedLocal to be uploaded, got %#+v", upload)
+	}
+	if len(remoteState) != 1 || remoteState["/changedRemote"] == nil {
+		t.Fatalf("Expected only /changedRemote to be downloaded, got %#+v", remoteState)
+	}
+	if len(removeRemote) != 1 || removeRemote[0].Name != "/deletedLocal" {
+		t.Fatalf("Expected only /deletedLocal to be removed remotely, got %#+v", removeRemote)
+	}
+	if len(removeLocal) != 1 || removeLocal[0].Name != "/deletedRemote" {
+		t.Fatalf("Expected only /deletedRemote to be removed locally, got %#+v", removeLocal)
+	}
+}
+
+func TestInitialSyncPlan(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	err = ioutil.WriteFile(filepath.Join(local, "local"), []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[latest.InitialSyncStrategy]struct {
+		upload, removeRemote, download, removeLocal int
+	}{
+		latest.InitialSyncStrategyMirrorLocal:  {upload: 1, removeRemote: 1},
+		latest.InitialSyncStrategyMirrorRemote: {removeLocal: 1, download: 1},
+		latest.InitialSyncStrategyPreferLocal:  {upload: 1, download: 1},
+	}
+
+	for strategy, testCase := range testCases {
+		remoteState := map[string]*FileInformation{
+			"/remote": {Name: "/remote", Size: 10, Mtime: time.Now().Unix()},
+		}
+
+		fileIndex := newFileIndex()
+		for _, element := range remoteState {
+			fileIndex.Set(element)
+		}
+
+		syncer := newInitialSyncer(&initialSyncOptions{
+			LocalPath: local,
+			Strategy:  strategy,
+			CompareBy: latest.InitialSyncCompareByMTime,
+			FileIndex: fileIndex,
+			Log:       log.Discard,
+		})
+
+		plan, err := syncer.plan(remoteState)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(plan.upload) != testCase.upload || len(plan.removeRemote) != testCase.removeRemote || len(plan.download) != testCase.download || len(plan.removeLocal) != testCase.removeLocal {
+			t.Fatalf("Unexpected plan for strategy %s: %d upload(s), %d remote remove(s), %d download(s), %d local remove(s)", strategy, len(plan.upload), len(plan.removeRemote), len(plan.download), len(plan.removeLocal))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package sync
+
+import (
+	"os"
+	"sort"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+)
+
+// Plan describes the changes the initial sync would apply with the configured strategy
+type Plan struct {
+	Strategy latest.InitialSyncStrategy `json:"strategy"`
+
+	Upload       []*PlanEntry `json:"upload"`
+	Download     []*PlanEntry `json:"download"`
+	RemoveRemote []*PlanEntry `json:"removeRemote"`
+	RemoveLocal  []*PlanEntry `json:"removeLocal"`
+}
+
+// PlanEntry is a file or folder that would be changed by the initial sync
+type PlanEntry struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	IsDirectory bool   `json:"isDirectory,omitempty"`
+}
+
+// Plan collects the remote state and calculates the changes of the initial sync without applying them.
+// The upstream and downstream need to be initialized, but the sync must not be started
+func (s *Sync) Plan() (*Plan, error) {
+	initialSync, remoteState, err := s.newInitialSyncer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Symlinks are not watched during a dry run
+	initialSync.o.AddSymlink = func(relativePath, absPath string) (os.FileInfo, error) {
+		return os.Stat(absPath)
+	}
+
+	plan, err := initialSync.plan(remoteState)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Plan{
+		Strategy:     initialSync.o.Strategy,
+		Upload:       fileInformationToPlanEntries(plan.upload),
+		Download:     changesToPlanEntries(plan.download),
+		RemoveRemote: fileInformationToPlanEntries(plan.removeRemote),
+		RemoveLocal:  changesToPlanEntries(plan.removeLocal),
+	}, nil
+}
+
+func fileInformationToPlanEntries(files []*FileInformation) []*PlanEntry {
+	entries := make([]*PlanEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, &PlanEntry{
+			Path:        file.Name,
+			Size:        file.Size,
+			IsDirectory: file.IsDirectory,
+		})
+	}
+
+	return sortPlanEntries(entries)
+}
+
+func changesToPlanEntries(changes []*remote.Change) []*PlanEntry {
+	entries := make([]*PlanEntry, 0, len(changes))
+	for _, change := range changes {
+		entries = append(entries, &PlanEntry{
+			Path:        change.Path,
+			Size:        change.Size,
+			IsDirectory: change.IsDir,
+		})
+	}
+
+	return sortPlanEntries(entries)
+}
+
+func sortPlanEntries(entries []*PlanEntry) []*PlanEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries
+}
@@ -0,0 +1,22 @@
+package sync
+
+// exceedsSizeLimit checks if the file at the given relative path is too large to be synced
+// and reports every skipped file once in the sync log
+func (s *Sync) exceedsSizeLimit(relativePath string, size int64) bool {
+	maxSize := s.sizeLimits.MaxSize(relativePath)
+	exceeded := maxSize > 0 && size > maxSize
+
+	s.skippedFilesMutex.Lock()
+	defer s.skippedFilesMutex.Unlock()
+
+	if exceeded == false {
+		// the file is reported again if it grows too large again
+		delete(s.skippedFiles, relativePath)
+		return false
+	} else if s.skippedFiles[relativePath] == false {
+		s.skippedFiles[relativePath] = true
+		s.log.Infof("Skip file %s, because its size of %0.2f MB exceeds the limit of %0.2f MB", relativePath, float64(size)/1024.0/1024.0, float64(maxSize)/1024.0/1024.0)
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// persistedState is the file index of a sync session that is saved to disk
+type persistedState struct {
+	// ID identifies the container the file index was synced with
+	ID    string                      `json:"id"`
+	Files map[string]*FileInformation `json:"files"`
+}
+
+// loadState loads the file index that was saved for the container with the given id. If there is no
+// state or the state was saved for a different container, nil is returned
+func loadState(stateFile, id string) (map[string]*FileInformation, error) {
+	if stateFile == "" || id == "" {
+		return nil, nil
+	}
+
+	out, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	state := &persistedState{}
+	err = json.Unmarshal(out, state)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse state")
+	} else if state.ID != id {
+		return nil, nil
+	}
+
+	return state.Files, nil
+}
+
+// saveState saves the current file index. s.fileIndex needs to be locked before this function is called
+func (s *Sync) saveState() error {
+	if s.Options.StateFile == "" || s.Options.StateID == "" {
+		return nil
+	}
+
+	out, err := json.Marshal(&persistedState{
+		ID:    s.Options.StateID,
+		Files: s.fileIndex.fileMap,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.Options.StateFile), 0755)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.Options.StateFile, out, 0644)
+}
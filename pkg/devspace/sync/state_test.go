@@ -0,0 +1,137 @@
+package sync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestInitialSyncFromState(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+	remotePath, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(remotePath)
+
+	// all files were synced an hour ago and changed or deleted on one side since then
+	lastSynced := time.Now().Add(-time.Hour).Truncate(time.Second)
+	changed := time.Now().Add(-time.Minute).Truncate(time.Second)
+	type file struct {
+		content string
+		mtime   time.Time
+	}
+	localFiles := map[string]file{
+		"unchanged":     {"content", lastSynced},
+		"changedLocal":  {"changed", changed},
+		"changedRemote": {"content", lastSynced},
+		"deletedRemote": {"content", lastSynced},
+	}
+	remoteFiles := map[string]file{
+		"unchanged":                 {"content", lastSynced},
+		"changedLocal":              {"content", lastSynced},
+		"changedRemote":             {"changed", changed},
+		"deletedLocal":              {"content", lastSynced},
+		"deletedLocalChangedRemote": {"changed", changed},
+	}
+	for dir, files := range map[string]map[string]file{local: localFiles, remotePath: remoteFiles} {
+		for name, f := range files {
+			err = ioutil.WriteFile(filepath.Join(dir, name), []byte(f.content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.Chtimes(filepath.Join(dir, name), f.mtime, f.mtime)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	state := &persistedState{ID: "container", Files: map[string]*FileInformation{}}
+	for _, name := range []string{"unchanged", "changedLocal", "changedRemote", "deletedRemote", "deletedLocal", "deletedLocalChangedRemote"} {
+		state.Files["/"+name] = &FileInformation{Name: "/" + name, Mtime: lastSynced.Unix(), Size: int64(len("content"))}
+	}
+	out, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateFile := filepath.Join(local, ".state.json")
+	err = ioutil.WriteFile(stateFile, out, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncClient, err := NewSync(local, Options{
+		InitialSync:               latest.InitialSyncStrategyPreferLocal,
+		ExcludePaths:              []string{".state.json"},
+		StateFile:                 stateFile,
+		StateID:                   "container",
+		UpstreamInitialSyncDone:   make(chan bool),
+		DownstreamInitialSyncDone: make(chan bool),
+		SyncError:                 make(chan error, 1),
+		Log:                       log.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syncClient.Stop(nil)
+
+	err = syncClient.ConnectDownstream(LocalTransport{}, []string{"downstream", remotePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = syncClient.ConnectUpstream(LocalTransport{}, []string{"upstream", remotePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = syncClient.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, done := range []chan bool{syncClient.Options.UpstreamInitialSyncDone, syncClient.Options.DownstreamInitialSyncDone} {
+		select {
+		case <-done:
+		case err := <-syncClient.Options.SyncError:
+			t.Fatal(err)
+		case <-time.After(time.Second * 20):
+			t.Fatal("Timeout waiting for initial sync")
+		}
+	}
+
+	// a file that was deleted locally, but changed remotely is restored locally
+	expected := map[string]string{
+		filepath.Join(remotePath, "unchanged"):                 "content",
+		filepath.Join(remotePath, "changedLocal"):              "changed",
+		filepath.Join(local, "changedRemote"):                  "changed",
+		filepath.Join(local, "deletedRemote"):                  "",
+		filepath.Join(remotePath, "deletedRemote"):             "",
+		filepath.Join(remotePath, "deletedLocal"):              "",
+		filepath.Join(local, "deletedLocalChangedRemote"):      "changed",
+		filepath.Join(remotePath, "deletedLocalChangedRemote"): "changed",
+	}
+	deadline := time.Now().Add(time.Second * 10)
+	for path, expectedContent := range expected {
+		for {
+			content, err := ioutil.ReadFile(path)
+			if (expectedContent == "" && os.IsNotExist(err)) || (err == nil && string(content) == expectedContent) {
+				break
+			} else if time.Now().After(deadline) {
+				t.Fatalf("Unexpected content of %s: expected %q, got %q (%v)", path, expectedContent, string(content), err)
+			}
+
+			time.Sleep(time.Millisecond * 100)
+		}
+	}
+}
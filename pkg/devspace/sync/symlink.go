@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/watch"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/syncthing/notify"
@@ -103,3 +105,40 @@ func (s *Symlink) Crawl() error {
 func (s *Symlink) Stop() {
 	s.watcher.Stop()
 }
+
+// followsSymlinks returns true if local symlinks are resolved and the files they point to are synced
+func (s *Sync) followsSymlinks() bool {
+	return s.Options.Symlinks == "" || s.Options.Symlinks == latest.SymlinksModeFollow
+}
+
+// preservedSymlink returns the file information of a local symlink that is synced as symlink. Nil is
+// returned if symlinks are not preserved, the symlink is excluded or it points outside of the local path
+func (s *Sync) preservedSymlink(relativePath, absPath string, lstat os.FileInfo) *FileInformation {
+	if s.Options.Symlinks != latest.SymlinksModePreserve {
+		return nil
+	}
+
+	target, err := os.Readlink(absPath)
+	if err != nil {
+		return nil
+	} else if util.SymlinkWithinRoot(s.LocalPath, relativePath, target) == false {
+		s.log.Infof("Skip symlink %s, because its target %s is outside of the synced path", relativePath, target)
+		return nil
+	}
+
+	// Symlinks to folders are excluded like folders
+	if s.ignoreMatcher != nil {
+		stat, err := os.Stat(absPath)
+		if util.MatchesPath(s.ignoreMatcher, relativePath, err == nil && stat.IsDir()) {
+			return nil
+		}
+	}
+
+	return &FileInformation{
+		Name:       relativePath,
+		Mtime:      lstat.ModTime().Unix(),
+		MtimeNano:  lstat.ModTime().UnixNano(),
+		Size:       lstat.Size(),
+		LinkTarget: target,
+	}
+}
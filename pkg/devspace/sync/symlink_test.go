@@ -0,0 +1,150 @@
+//go:build !windows
+// +build !windows
+
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// checkSymlinkPath checks that the path is a symlink with the given target, a file with the given content
+// or that it does not exist
+func checkSymlinkPath(path, expected string) bool {
+	lstat, err := os.Lstat(path)
+	if expected == "" {
+		return os.IsNotExist(err)
+	} else if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(expected, "->") {
+		target, err := os.Readlink(path)
+		return err == nil && target == strings.TrimPrefix(expected, "->")
+	}
+
+	content, err := ioutil.ReadFile(path)
+	return lstat.Mode()&os.ModeSymlink == 0 && err == nil && string(content) == expected
+}
+
+func TestSyncSymlinks(t *testing.T) {
+	testCases := map[latest.SymlinksMode]map[string]string{
+		latest.SymlinksModeFollow: {
+			"upstream/link":            "local",
+			"upstream/escape":          "outside",
+			"downstream/remoteLink":    "remote",
+			"upstream/newLink":         "local",
+			"downstream/newRemoteLink": "remote",
+		},
+		latest.SymlinksModePreserve: {
+			"upstream/link":            "->target.txt",
+			"upstream/escape":          "",
+			"downstream/remoteLink":    "->remote.txt",
+			"upstream/newLink":         "->target.txt",
+			"downstream/newRemoteLink": "->remote.txt",
+		},
+		latest.SymlinksModeSkip: {
+			"upstream/link":            "",
+			"upstream/escape":          "",
+			"downstream/remoteLink":    "",
+			"upstream/newLink":         "",
+			"downstream/newRemoteLink": "",
+		},
+	}
+
+	for mode, expected := range testCases {
+		remote, local, outside := initTestDirs(t)
+		defer os.RemoveAll(remote)
+		defer os.RemoveAll(local)
+		defer os.RemoveAll(outside)
+
+		for path, content := range map[string]string{filepath.Join(local, "target.txt"): "local", filepath.Join(remote, "remote.txt"): "remote", filepath.Join(outside, "file.txt"): "outside"} {
+			err := ioutil.WriteFile(path, []byte(content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		for path, target := range map[string]string{filepath.Join(local, "link"): "target.txt", filepath.Join(local, "escape"): filepath.Join(outside, "file.txt"), filepath.Join(remote, "remoteLink"): "remote.txt"} {
+			err := os.Symlink(target, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		syncClient, err := NewSync(local, Options{
+			InitialSync:               latest.InitialSyncStrategyPreferLocal,
+			Symlinks:                  mode,
+			UpstreamInitialSyncDone:   make(chan bool),
+			DownstreamInitialSyncDone: make(chan bool),
+			SyncError:                 make(chan error, 1),
+			Log:                       log.Discard,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		args := []string{remote}
+		if mode != latest.SymlinksModeFollow {
+			args = []string{"--symlinks", string(mode), remote}
+		}
+		err = syncClient.ConnectDownstream(LocalTransport{}, append([]string{"downstream"}, args...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = syncClient.ConnectUpstream(LocalTransport{}, append([]string{"upstream"}, args...))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = syncClient.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, done := range []chan bool{syncClient.Options.UpstreamInitialSyncDone, syncClient.Options.DownstreamInitialSyncDone} {
+			select {
+			case <-done:
+			case err := <-syncClient.Options.SyncError:
+				t.Fatal(err)
+			case <-time.After(time.Second * 20):
+				t.Fatal("Timeout waiting for initial sync")
+			}
+		}
+
+		// symlinks created while syncing
+		err = os.Symlink("target.txt", filepath.Join(local, "newLink"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Symlink("remote.txt", filepath.Join(remote, "newRemoteLink"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// upstream paths are checked in the remote folder and downstream paths in the local folder
+		deadline := time.Now().Add(time.Second * 10)
+		for path, expectedPath := range expected {
+			absPath := filepath.Join(remote, strings.TrimPrefix(path, "upstream/"))
+			if strings.HasPrefix(path, "downstream/") {
+				absPath = filepath.Join(local, strings.TrimPrefix(path, "downstream/"))
+			}
+
+			for checkSymlinkPath(absPath, expectedPath) == false {
+				if time.Now().After(deadline) {
+					t.Fatalf("Symlinks mode %s: unexpected %s, expected %q", mode, path, expectedPath)
+				}
+
+				time.Sleep(time.Millisecond * 100)
+			}
+		}
+
+		syncClient.Stop(nil)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/log"
 
@@ -20,8 +21,6 @@ var initialUpstreamBatchSize = 1000
 
 // Options holds the sync options
 type Options struct {
-	Polling bool
-
 	ExcludePaths         []string
 	DownloadExcludePaths []string
 	UploadExcludePaths   []string
@@ -43,6 +42,29 @@ type Options struct {
 
 	InitialSyncCompareBy latest.InitialSyncCompareBy
 	InitialSync          latest.InitialSyncStrategy
+	ConflictStrategy     latest.ConflictStrategy
+	Symlinks             latest.SymlinksMode
+
+	// MaxFileSize and SizeRules exclude files from the sync that are larger than the
+	// configured amount of bytes, the last matching size rule wins over MaxFileSize
+	MaxFileSize int64
+	SizeRules   []util.SizeRule
+
+	// UploadConnections is the amount of helper connections that large uploads are split across. The
+	// uploaded files are split into batches of at most UploadBatchFiles files and UploadBatchSize bytes,
+	// which are retried on their own. Large uploads are not split if UploadConnections is 0
+	UploadConnections int
+	UploadBatchFiles  int
+	UploadBatchSize   int64
+
+	// PreserveExecutable is true if the helper sets the executable bits of uploaded files as they are
+	// locally, which means files are not made executable on windows
+	PreserveExecutable bool
+
+	// StateFile is the path where the file index is saved, so that a later sync with the
+	// same container identified by StateID only needs to reconcile the changes since then
+	StateFile string
+	StateID   string
 
 	// These channels can be used to listen for certain sync events
 	DownstreamInitialSyncDone chan bool
@@ -64,14 +86,29 @@ type Sync struct {
 	downloadIgnoreMatcher ignoreparser.IgnoreParser
 	uploadIgnoreMatcher   ignoreparser.IgnoreParser
 
+	// sizeLimits decides which files are too large to be synced, skippedFiles holds
+	// the files that were already reported as skipped
+	sizeLimits        *util.SizeLimits
+	skippedFiles      map[string]bool
+	skippedFilesMutex sync.Mutex
+
 	log log.Logger
 
 	upstream   *upstream
 	downstream *downstream
 
+	// upstreamTransport and upstreamArgs are used to open additional upstream connections, the args
+	// don't contain the command flags of the main connection
+	upstreamTransport Transport
+	upstreamArgs      []string
+
 	silent   bool
 	stopOnce sync.Once
 
+	// initialSyncDone is true if the file index holds the synced state
+	// and can be saved. It is protected by fileIndex
+	initialSyncDone bool
+
 	// Used for testing
 	readyChan chan bool
 }
@@ -96,6 +133,11 @@ func NewSync(localPath string, options Options) (*Sync, error) {
 	// We exclude the sync log to prevent an endless loop in upstream
 	options.ExcludePaths = append(options.ExcludePaths, ".devspace/")
 
+	// Conflict copies should only exist locally
+	if options.ConflictStrategy == latest.ConflictStrategyKeepBoth {
+		options.UploadExcludePaths = append(options.UploadExcludePaths, "*"+conflictFileSuffix)
+	}
+
 	// Initialize log
 	if options.Log == nil {
 		options.Log = log.GetFileLogger("sync")
@@ -106,8 +148,9 @@ func NewSync(localPath string, options Options) (*Sync, error) {
 		LocalPath: absoluteLocalPath,
 		Options:   options,
 
-		fileIndex: newFileIndex(),
-		log:       options.Log,
+		fileIndex:    newFileIndex(),
+		skippedFiles: map[string]bool{},
+		log:          options.Log,
 	}
 
 	err = s.initIgnoreParsers()
@@ -180,11 +223,20 @@ func (s *Sync) initIgnoreParsers() error {
 		s.uploadIgnoreMatcher = ignoreMatcher
 	}
 
+	sizeLimits, err := util.NewSizeLimits(s.Options.MaxFileSize, s.Options.SizeRules)
+	if err != nil {
+		return errors.Wrap(err, "compile size rules")
+	}
+
+	s.sizeLimits = sizeLimits
 	return nil
 }
 
 func (s *Sync) mainLoop() {
 	s.log.Info("Start syncing")
+	if s.Options.Verbose {
+		s.log.Infof("Downstream - DevSpace helper version %s detects remote changes with %s, transfers are compressed with %s", s.downstream.capabilities.Version, s.downstream.capabilities.WatchMode, s.downstream.compression)
+	}
 
 	// Start upstream as early as possible
 	if s.Options.UpstreamDisabled == false {
@@ -199,6 +251,14 @@ func (s *Sync) mainLoop() {
 			return
 		}
 
+		s.fileIndex.fileMapMutex.Lock()
+		s.initialSyncDone = true
+		err = s.saveState()
+		s.fileIndex.fileMapMutex.Unlock()
+		if err != nil {
+			s.log.Infof("Error saving sync state: %v", err)
+		}
+
 		if s.Options.DownstreamDisabled == false {
 			s.startDownstream()
 			s.Stop(nil)
@@ -238,9 +298,29 @@ func (s *Sync) startDownstream() {
 }
 
 func (s *Sync) initialSync() error {
-	err := s.downstream.populateFileMap()
+	initialSync, remoteState, err := s.newInitialSyncer()
+	if err != nil {
+		return err
+	}
+
+	return initialSync.Run(remoteState)
+}
+
+// newInitialSyncer retrieves the remote state and creates the initial syncer for it
+func (s *Sync) newInitialSyncer() (*initialSyncer, map[string]*FileInformation, error) {
+	// The saved state of the last sync session with this container is only the base to decide in which
+	// direction a change is synced, the remote files are always retrieved from the container
+	lastState, err := loadState(s.Options.StateFile, s.Options.StateID)
 	if err != nil {
-		return errors.Wrap(err, "populate file map")
+		s.log.Infof("Initial Sync - Ignore saved sync state: %v", err)
+		lastState = nil
+	} else if lastState != nil {
+		s.log.Info("Initial Sync - Reconcile changes since the last sync session with this container")
+	}
+
+	err = s.downstream.populateFileMap()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "populate file map")
 	}
 
 	downloadChanges := make(map[string]*FileInformation)
@@ -254,10 +334,17 @@ func (s *Sync) initialSync() error {
 	}
 	s.fileIndex.fileMapMutex.Unlock()
 
+	// Older helpers can't calculate checksums, so we compare by mtime instead
+	compareBy := s.Options.InitialSyncCompareBy
+	if compareBy == latest.InitialSyncCompareByChecksum && hasFeature(s.downstream.capabilities, util.FeatureChecksums) == false {
+		s.log.Infof("Initial Sync - The devspace helper does not support checksums, compare by mtime instead")
+		compareBy = latest.InitialSyncCompareByMTime
+	}
+
 	initialSync := newInitialSyncer(&initialSyncOptions{
 		LocalPath: s.LocalPath,
 		Strategy:  s.Options.InitialSync,
-		CompareBy: s.Options.InitialSyncCompareBy,
+		CompareBy: compareBy,
 
 		IgnoreMatcher:         s.ignoreMatcher,
 		DownloadIgnoreMatcher: s.downloadIgnoreMatcher,
@@ -266,12 +353,20 @@ func (s *Sync) initialSync() error {
 		UpstreamDisabled:   s.Options.UpstreamDisabled,
 		DownstreamDisabled: s.Options.DownstreamDisabled,
 		FileIndex:          s.fileIndex,
+		LastState:          lastState,
 
 		ApplyRemote: s.sendChangesToUpstream,
 		ApplyLocal:  s.downstream.applyChanges,
 		AddSymlink:  s.upstream.AddSymlink,
 		Log:         s.log,
 
+		Symlinks:         s.Options.Symlinks,
+		PreservedSymlink: s.preservedSymlink,
+
+		ExceedsSizeLimit: s.exceedsSizeLimit,
+
+		RemoteChecksums: s.downstream.checksums,
+
 		UpstreamDone: func() {
 			if s.Options.UpstreamInitialSyncDone != nil {
 				if s.Options.UpstreamDisabled == false {
@@ -292,7 +387,7 @@ func (s *Sync) initialSync() error {
 		},
 	})
 
-	return initialSync.Run(downloadChanges)
+	return initialSync, downloadChanges, nil
 }
 
 func (s *Sync) sendChangesToUpstream(changes []*FileInformation, remove bool) {
@@ -364,13 +459,27 @@ func (s *Sync) Stop(fatalError error) {
 			}
 		}
 
+		// Save the synced state, so that the next sync session doesn't have to do a full initial sync
+		s.fileIndex.fileMapMutex.Lock()
+		if s.initialSyncDone {
+			err := s.saveState()
+			if err != nil {
+				s.log.Infof("Error saving sync state: %v", err)
+			}
+		}
+		s.fileIndex.fileMapMutex.Unlock()
+
 		if fatalError != nil {
 			s.Error(fatalError)
 
 			// This needs to be rethought because we do not always kill the application here, would be better to have an error channel
-			// or runtime error here
+			// or runtime error here. Nobody might listen for the error anymore, e.g. if the sync is not restarted, so we
+			// don't block the stop
 			if s.Options.SyncError != nil {
-				s.Options.SyncError <- fatalError
+				select {
+				case s.Options.SyncError <- fatalError:
+				default:
+				}
 			}
 		}
 
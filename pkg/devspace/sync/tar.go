@@ -2,7 +2,6 @@ package sync
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
 	"io"
 	"io/ioutil"
@@ -15,6 +14,7 @@ import (
 	"time"
 
 	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/log"
 
 	"github.com/pkg/errors"
@@ -25,6 +25,10 @@ type Unarchiver struct {
 	syncConfig    *Sync
 	forceOverride bool
 
+	// overridePaths are paths that are overridden even if the local file is newer,
+	// s.fileIndex needs to be locked to access it
+	overridePaths map[string]bool
+
 	log log.Logger
 }
 
@@ -33,6 +37,7 @@ func NewUnarchiver(syncConfig *Sync, forceOverride bool, log log.Logger) *Unarch
 	return &Unarchiver{
 		syncConfig:    syncConfig,
 		forceOverride: forceOverride,
+		overridePaths: map[string]bool{},
 		log:           log,
 	}
 }
@@ -40,14 +45,14 @@ func NewUnarchiver(syncConfig *Sync, forceOverride bool, log log.Logger) *Unarch
 // Untar untars the given reader into the destination directory
 func (u *Unarchiver) Untar(fromReader io.Reader, toPath string) error {
 	fileCounter := 0
-	gzr, err := gzip.NewReader(fromReader)
+	decompressedReader, err := util.NewDecompressionReader(fromReader)
 	if err != nil {
 		return errors.Errorf("error decompressing: %v", err)
 	}
 
-	defer gzr.Close()
+	defer decompressedReader.Close()
 
-	tarReader := tar.NewReader(gzr)
+	tarReader := tar.NewReader(decompressedReader)
 	for {
 		shouldContinue, err := u.untarNext(toPath, tarReader)
 		if err != nil {
@@ -81,8 +86,22 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 	baseName := path.Dir(outFileName)
 
 	// Check if newer file is there and then don't override?
+	override := u.overridePaths[relativePath]
+	delete(u.overridePaths, relativePath)
+
+	if header.Typeflag == tar.TypeSymlink {
+		return true, u.untarSymlink(relativePath, outFileName, header)
+	}
+
+	// Replace a preserved symlink instead of writing to the file it points to
+	if u.syncConfig.Options.Symlinks == latest.SymlinksModePreserve {
+		if lstat, err := os.Lstat(outFileName); err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+			_ = os.Remove(outFileName)
+		}
+	}
+
 	stat, err := os.Stat(outFileName)
-	if err == nil && u.forceOverride == false {
+	if err == nil && u.forceOverride == false && override == false {
 		if stat.ModTime().Unix() > header.FileInfo().ModTime().Unix() {
 			// Update filemap otherwise we download and download again
 			u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
@@ -151,6 +170,65 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 	_ = os.Chtimes(outFileName, time.Now(), header.ModTime)
 
 	// Execute command if defined
+	err = u.executeFileChangeCmd(outFileName)
+	if err != nil {
+		return false, err
+	}
+
+	// Update fileMap so that upstream does not upload the file
+	u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
+		Name:        relativePath,
+		Mtime:       header.ModTime.Unix(),
+		Size:        header.FileInfo().Size(),
+		IsDirectory: false,
+	}
+
+	return true, nil
+}
+
+// untarSymlink creates a symlink that was preserved remotely. Symlinks that would point outside
+// of the local path or replace a local folder are skipped
+func (u *Unarchiver) untarSymlink(relativePath, outFileName string, header *tar.Header) error {
+	if util.SymlinkWithinRoot(u.syncConfig.LocalPath, relativePath, header.Linkname) == false {
+		u.syncConfig.log.Infof("Downstream - Skip symlink %s, because its target %s is outside of the synced path", relativePath, header.Linkname)
+		return nil
+	}
+
+	lstat, err := os.Lstat(outFileName)
+	if err == nil {
+		if lstat.IsDir() {
+			u.syncConfig.log.Infof("Downstream - Don't replace folder %s with a symlink", relativePath)
+			return nil
+		}
+
+		err = os.Remove(outFileName)
+		if err != nil {
+			return errors.Wrapf(err, "remove %s", outFileName)
+		}
+	}
+
+	if err := u.createAllFolders(path.Dir(outFileName), 0755); err != nil {
+		return err
+	}
+
+	err = os.Symlink(header.Linkname, outFileName)
+	if err != nil {
+		return errors.Wrapf(err, "create symlink %s", outFileName)
+	}
+
+	// Update fileMap so that upstream does not upload the symlink
+	u.syncConfig.fileIndex.CreateDirInFileMap(path.Dir(relativePath))
+	u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
+		Name:       relativePath,
+		Mtime:      header.ModTime.Unix(),
+		Size:       header.Size,
+		LinkTarget: header.Linkname,
+	}
+
+	return nil
+}
+
+func (u *Unarchiver) executeFileChangeCmd(outFileName string) error {
 	if u.syncConfig.Options.FileChangeCmd != "" {
 		cmdArgs := make([]string, 0, len(u.syncConfig.Options.FileChangeArgs))
 		for _, arg := range u.syncConfig.Options.FileChangeArgs {
@@ -163,19 +241,11 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 
 		out, err := exec.Command(u.syncConfig.Options.FileChangeCmd, cmdArgs...).CombinedOutput()
 		if err != nil {
-			return false, errors.Errorf("Error executing command '%s %s': %s => %v", u.syncConfig.Options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
+			return errors.Errorf("Error executing command '%s %s': %s => %v", u.syncConfig.Options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
 		}
 	}
 
-	// Update fileMap so that upstream does not upload the file
-	u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
-		Name:        relativePath,
-		Mtime:       header.ModTime.Unix(),
-		Size:        header.FileInfo().Size(),
-		IsDirectory: false,
-	}
-
-	return true, nil
+	return nil
 }
 
 func (u *Unarchiver) createAllFolders(name string, perm os.FileMode) error {
@@ -223,6 +293,15 @@ type Archiver struct {
 	ignoreMatcher ignoreparser.IgnoreParser
 	writer        *tar.Writer
 	writtenFiles  map[string]*FileInformation
+
+	// symlinks defines if symlinks are followed, preserved or skipped
+	symlinks latest.SymlinksMode
+
+	// exceedsSizeLimit checks if a file is too large to be archived
+	exceedsSizeLimit func(relativePath string, size int64) bool
+
+	// preserveExecutable keeps the executable bits of the files as they are, because the helper applies them
+	preserveExecutable bool
 }
 
 // NewArchiver creates a new archiver
@@ -248,6 +327,18 @@ func (a *Archiver) AddToArchive(relativePath string) error {
 		return nil
 	}
 
+	// Symlinks are only resolved if they should be followed
+	if a.symlinks != "" && a.symlinks != latest.SymlinksModeFollow {
+		lstat, err := os.Lstat(absFilepath)
+		if err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+			if a.symlinks == latest.SymlinksModePreserve {
+				return a.tarSymlink(relativePath, lstat)
+			}
+
+			return nil
+		}
+	}
+
 	// We skip files that are suddenly not there anymore
 	stat, err := os.Stat(absFilepath)
 	if err != nil {
@@ -266,6 +357,11 @@ func (a *Archiver) AddToArchive(relativePath string) error {
 		return a.tarFolder(fileInformation, stat)
 	}
 
+	// skip files that are too large
+	if a.exceedsSizeLimit != nil && a.exceedsSizeLimit(relativePath, stat.Size()) {
+		return nil
+	}
+
 	// exclude file?
 	if a.ignoreMatcher == nil || a.ignoreMatcher.HasNegatePatterns() == false || util.MatchesPath(a.ignoreMatcher, relativePath, false) == false {
 		return a.tarFile(fileInformation, stat)
@@ -289,7 +385,7 @@ func (a *Archiver) tarFolder(target *FileInformation, targetStat os.FileInfo) er
 			hdr, _ := tar.FileInfoHeader(targetStat, filepath)
 			hdr.Uid = 0
 			hdr.Gid = 0
-			hdr.Mode = fillGo18FileTypeBits(int64(chmodTarEntry(os.FileMode(hdr.Mode))), targetStat)
+			hdr.Mode = fillGo18FileTypeBits(int64(chmodTarEntry(os.FileMode(hdr.Mode), a.preserveExecutable)), targetStat)
 			hdr.Name = target.Name
 			if err := a.writer.WriteHeader(hdr); err != nil {
 				return errors.Wrap(err, "tar write header")
@@ -333,7 +429,7 @@ func (a *Archiver) tarFile(target *FileInformation, targetStat os.FileInfo) erro
 	hdr.Name = target.Name
 	hdr.Uid = 0
 	hdr.Gid = 0
-	hdr.Mode = fillGo18FileTypeBits(int64(chmodTarEntry(os.FileMode(hdr.Mode))), targetStat)
+	hdr.Mode = fillGo18FileTypeBits(int64(chmodTarEntry(os.FileMode(hdr.Mode), a.preserveExecutable)), targetStat)
 	hdr.ModTime = time.Unix(target.Mtime, 0)
 
 	if err := a.writer.WriteHeader(hdr); err != nil {
@@ -355,6 +451,42 @@ func (a *Archiver) tarFile(target *FileInformation, targetStat os.FileInfo) erro
 	return nil
 }
 
+// tarSymlink adds a symlink as symlink instead of the file it points to. Excluded symlinks and
+// symlinks that point outside of the base path are skipped
+func (a *Archiver) tarSymlink(relativePath string, lstat os.FileInfo) error {
+	absFilepath := path.Join(a.basePath, relativePath)
+	target, err := os.Readlink(absFilepath)
+	if err != nil || util.SymlinkWithinRoot(a.basePath, relativePath, target) == false {
+		return nil
+	}
+
+	// Symlinks to folders are excluded like folders
+	if a.ignoreMatcher != nil {
+		stat, err := os.Stat(absFilepath)
+		if util.MatchesPath(a.ignoreMatcher, relativePath, err == nil && stat.IsDir()) {
+			return nil
+		}
+	}
+
+	hdr, err := tar.FileInfoHeader(lstat, target)
+	if err != nil {
+		return errors.Wrap(err, "create tar file info header")
+	}
+	hdr.Name = relativePath
+	hdr.Uid = 0
+	hdr.Gid = 0
+	hdr.ModTime = time.Unix(lstat.ModTime().Unix(), 0)
+
+	if err := a.writer.WriteHeader(hdr); err != nil {
+		return errors.Wrap(err, "tar write header")
+	}
+
+	fileInformation := createFileInformationFromStat(relativePath, lstat)
+	fileInformation.LinkTarget = target
+	a.writtenFiles[relativePath] = fileInformation
+	return nil
+}
+
 const (
 	modeISDIR  = 040000  // Directory
 	modeISFIFO = 010000  // FIFO
@@ -366,9 +498,10 @@ const (
 )
 
 // chmodTarEntry is used to adjust the file permissions used in tar header based
-// on the platform the archival is done.
-func chmodTarEntry(perm os.FileMode) os.FileMode {
-	if runtime.GOOS != "windows" {
+// on the platform the archival is done. If the executable bits are preserved, they
+// are not added on windows
+func chmodTarEntry(perm os.FileMode, preserveExecutable bool) os.FileMode {
+	if runtime.GOOS != "windows" || preserveExecutable {
 		return perm
 	}
 
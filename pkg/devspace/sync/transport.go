@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/juju/ratelimit"
+	helpersync "github.com/loft-sh/devspace/helper/cmd/sync"
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// Transport starts a sync server of the devspace helper and connects it to the sync client
+type Transport interface {
+	fmt.Stringer
+
+	// Start runs the helper sync command with the given args, such as upstream --exclude node_modules /app.
+	// The server reads from stdin and writes to stdout. Start blocks until the server has stopped
+	Start(args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+// LocalTransport runs the sync servers in this process, which syncs the local path with another
+// local directory instead of a container
+type LocalTransport struct{}
+
+// Start implements interface
+func (LocalTransport) Start(args []string, stdin io.Reader, stdout io.Writer) error {
+	syncCmd := helpersync.NewSyncCmdWithStreams(&helpersync.Streams{
+		Reader: stdin,
+		Writer: stdout,
+	})
+	syncCmd.SetArgs(args)
+	syncCmd.SilenceUsage = true
+	syncCmd.SilenceErrors = true
+
+	return syncCmd.Execute()
+}
+
+func (LocalTransport) String() string {
+	return "local directory"
+}
+
+// ConnectUpstream starts the upstream server with the given args via the transport and inits the upstream
+func (s *Sync) ConnectUpstream(transport Transport, args []string) error {
+	// the transport is remembered, because large uploads open additional connections
+	s.upstreamTransport = transport
+	s.upstreamArgs = withoutCommandArgs(args)
+
+	reader, writer := s.connect(transport, args)
+	return s.InitUpstream(reader, writer)
+}
+
+// ConnectDownstream starts the downstream server with the given args via the transport and inits the downstream
+func (s *Sync) ConnectDownstream(transport Transport, args []string) error {
+	reader, writer := s.connect(transport, args)
+	return s.InitDownstream(reader, writer)
+}
+
+func (s *Sync) connect(transport Transport, args []string) (io.ReadCloser, io.WriteCloser) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	go func() {
+		err := transport.Start(args, stdinReader, stdoutWriter)
+		if err != nil {
+			s.Stop(errors.Errorf("Sync - connection lost to %s: %v", transport, err))
+		}
+	}()
+
+	return stdoutReader, stdinWriter
+}
+
+// withoutCommandArgs returns the upstream args without the command flags and their values. They are not passed
+// to additional connections, because the commands of a batched upload are executed once over the main connection
+func withoutCommandArgs(args []string) []string {
+	newArgs := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		flag := strings.SplitN(args[i], "=", 2)
+		if helpersync.CommandFlags[flag[0]] {
+			if len(flag) == 1 {
+				i++
+			}
+
+			continue
+		}
+
+		newArgs = append(newArgs, args[i])
+	}
+
+	return newArgs
+}
+
+// upstreamConnection is an additional connection to an upstream server, which is used to upload batches in parallel
+type upstreamConnection struct {
+	client remote.UpstreamClient
+	conn   *grpc.ClientConn
+	stdin  io.WriteCloser
+}
+
+// Close closes the connection, which stops the upstream server
+func (c *upstreamConnection) Close() {
+	_ = c.conn.Close()
+	_ = c.stdin.Close()
+}
+
+// newUpstreamConnection starts another upstream server via the transport the upstream was connected with.
+// In contrast to connect, the sync is not stopped if this connection is lost
+func (s *Sync) newUpstreamConnection(uploadLimit *ratelimit.Bucket) (*upstreamConnection, error) {
+	if s.upstreamTransport == nil {
+		return nil, errors.New("upstream was not connected via a transport")
+	}
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	go func() {
+		err := s.upstreamTransport.Start(s.upstreamArgs, stdinReader, stdoutWriter)
+		if err == nil {
+			err = io.EOF
+		}
+
+		// calls on this connection fail instead of blocking after the server has stopped
+		_ = stdoutWriter.CloseWithError(err)
+		_ = stdinReader.CloseWithError(err)
+	}()
+
+	var writer io.Writer = stdinWriter
+	if uploadLimit != nil {
+		writer = ratelimit.Writer(stdinWriter, uploadLimit)
+	}
+
+	conn, err := util.NewClientConnection(stdoutReader, writer)
+	if err != nil {
+		_ = stdinWriter.Close()
+		return nil, errors.Wrap(err, "new client connection")
+	}
+
+	return &upstreamConnection{
+		client: remote.NewUpstreamClient(conn),
+		conn:   conn,
+		stdin:  stdinWriter,
+	}, nil
+}
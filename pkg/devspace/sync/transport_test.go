@@ -0,0 +1,101 @@
+// +build !windows
+
+package sync
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// stoppedTransport reports when the wrapped transport returns
+type stoppedTransport struct {
+	LocalTransport
+
+	stopped chan string
+}
+
+func (t *stoppedTransport) Start(args []string, stdin io.Reader, stdout io.Writer) error {
+	defer func() {
+		t.stopped <- args[0]
+	}()
+
+	return t.LocalTransport.Start(args, stdin, stdout)
+}
+
+func TestLocalTransport(t *testing.T) {
+	remote, local, outside := initTestDirs(t)
+	defer os.RemoveAll(remote)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(outside)
+
+	err := ioutil.WriteFile(filepath.Join(local, "local.txt"), []byte("local"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(remote, "remote.txt"), []byte("remote"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncClient, err := NewSync(local, Options{
+		InitialSync:               latest.InitialSyncStrategyPreferLocal,
+		UpstreamInitialSyncDone:   make(chan bool),
+		DownstreamInitialSyncDone: make(chan bool),
+		SyncError:                 make(chan error, 1),
+		Log:                       log.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &stoppedTransport{stopped: make(chan string, 2)}
+	err = syncClient.ConnectDownstream(transport, []string{"downstream", remote})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = syncClient.ConnectUpstream(transport, []string{"upstream", remote})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = syncClient.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, done := range []chan bool{syncClient.Options.UpstreamInitialSyncDone, syncClient.Options.DownstreamInitialSyncDone} {
+		select {
+		case <-done:
+		case err := <-syncClient.Options.SyncError:
+			t.Fatal(err)
+		case <-time.After(time.Second * 20):
+			t.Fatal("Timeout waiting for initial sync")
+		}
+	}
+
+	for path, expected := range map[string]string{filepath.Join(remote, "local.txt"): "local", filepath.Join(local, "remote.txt"): "remote"} {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(content) != expected {
+			t.Fatalf("Expected %s in %s, got %s", expected, path, string(content))
+		}
+	}
+
+	// The servers have to stop with the sync
+	syncClient.Stop(nil)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-transport.stopped:
+		case <-time.After(time.Second * 10):
+			t.Fatal("Timeout waiting for the sync servers to stop")
+		}
+	}
+}
@@ -2,7 +2,6 @@ package sync
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
@@ -19,6 +18,8 @@ import (
 	"github.com/loft-sh/devspace/helper/util"
 	"github.com/pkg/errors"
 	"github.com/syncthing/notify"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type upstream struct {
@@ -40,6 +41,15 @@ type upstream struct {
 	workingDirectory string
 
 	ignoreMatcher ignoreparser.IgnoreParser
+
+	// capabilities are the version and features of the upstream helper
+	capabilities *remote.Capabilities
+
+	// compression is the negotiated compression of uploaded archives
+	compression string
+
+	// uploadLimit is shared by all connections that upload files
+	uploadLimit *ratelimit.Bucket
 }
 
 const removeFilesBufferSize = 64
@@ -49,6 +59,7 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 	var (
 		clientReader io.Reader = reader
 		clientWriter io.Writer = writer
+		uploadLimit  *ratelimit.Bucket
 	)
 
 	// Apply limits if specified
@@ -56,7 +67,8 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 		clientReader = ratelimit.Reader(reader, ratelimit.NewBucketWithRate(float64(sync.Options.DownstreamLimit), sync.Options.DownstreamLimit))
 	}
 	if sync.Options.UpstreamLimit > 0 {
-		clientWriter = ratelimit.Writer(writer, ratelimit.NewBucketWithRate(float64(sync.Options.UpstreamLimit), sync.Options.UpstreamLimit))
+		uploadLimit = ratelimit.NewBucketWithRate(float64(sync.Options.UpstreamLimit), sync.Options.UpstreamLimit)
+		clientWriter = ratelimit.Writer(writer, uploadLimit)
 	}
 
 	// Create client
@@ -75,6 +87,13 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 		return nil, errors.Wrap(err, "compile paths")
 	}
 
+	// Check which features the helper supports
+	client := remote.NewUpstreamClient(conn)
+	capabilities, err := getCapabilities(client.Capabilities)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieve helper capabilities")
+	}
+
 	workingDirectory, _ := os.Getwd()
 	return &upstream{
 		events:      make(chan notify.EventInfo, 1000), // High buffer size so we don't miss any fsevents if there are a lot of changes
@@ -86,10 +105,13 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 
 		reader: reader,
 		writer: writer,
-		client: remote.NewUpstreamClient(conn),
+		client: client,
 
 		workingDirectory: workingDirectory,
 		ignoreMatcher:    ignoreMatcher,
+		capabilities:     capabilities,
+		compression:      util.NegotiateCompression(capabilities.Compressions),
+		uploadLimit:      uploadLimit,
 	}, nil
 }
 
@@ -111,7 +133,10 @@ func (u *upstream) startPing(doneChan chan struct{}) {
 					ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 					_, err := u.client.Ping(ctx, &remote.Empty{})
 					cancel()
-					if err != nil {
+					if status.Code(err) == codes.Aborted {
+						// the helper reports the errors of debounced upload rules with the next ping
+						u.sync.log.Errorf("Upstream - %s", status.Convert(err).Message())
+					} else if err != nil {
 						u.sync.Stop(fmt.Errorf("ping connection: %v", err))
 					}
 				}
@@ -253,6 +278,14 @@ func (u *upstream) getFileInformationFromEvent(events []notify.EventInfo) ([]*Fi
 }
 
 func (u *upstream) evaluateChange(relativePath, fullpath string) (*FileInformation, error) {
+	// Symlinks that are not followed are synced as they are or not at all
+	if u.sync.followsSymlinks() == false {
+		lstat, err := os.Lstat(fullpath)
+		if err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+			return u.evaluateSymlink(relativePath, fullpath, lstat), nil
+		}
+	}
+
 	stat, err := os.Stat(fullpath)
 
 	// File / Folder exist -> Create File or Folder
@@ -332,6 +365,22 @@ func (u *upstream) evaluateChange(relativePath, fullpath string) (*FileInformati
 	return nil, nil
 }
 
+func (u *upstream) evaluateSymlink(relativePath, fullpath string, lstat os.FileInfo) *FileInformation {
+	if u.sync.uploadIgnoreMatcher != nil {
+		stat, err := os.Stat(fullpath)
+		if util.MatchesPath(u.sync.uploadIgnoreMatcher, relativePath, err == nil && stat.IsDir()) {
+			return nil
+		}
+	}
+
+	fileInfo := u.sync.preservedSymlink(relativePath, fullpath, lstat)
+	if fileInfo == nil || shouldUpload(u.sync, fileInfo) == false {
+		return nil
+	}
+
+	return fileInfo
+}
+
 func (u *upstream) AddSymlink(relativePath, absPath string) (os.FileInfo, error) {
 	// Get real path
 	targetPath, err := filepath.EvalSymlinks(absPath)
@@ -400,8 +449,22 @@ func (u *upstream) applyChanges(changes []*FileInformation) error {
 		}
 	}
 
-	// Apply creates
+	// Resolve files that were also changed remotely before they are overridden
 	if len(creates) > 0 {
+		var err error
+		creates, err = u.resolveConflicts(creates)
+		if err != nil {
+			return errors.Wrap(err, "resolve conflicts")
+		}
+	}
+
+	// Apply creates
+	if len(creates) > 0 && u.shouldUploadInBatches(creates) {
+		err := u.applyCreatesInBatches(creates)
+		if err != nil {
+			return errors.Wrap(err, "apply creates")
+		}
+	} else if len(creates) > 0 {
 		for i := 0; i < syncRetries; i++ {
 			err := u.applyCreates(creates)
 			if err == nil {
@@ -442,6 +505,53 @@ func (u *upstream) RestartContainer() error {
 	return nil
 }
 
+// resolveConflicts waits until the remote changes the helper notified us about are synced before local changes are
+// uploaded. The downstream resolves files that were changed locally and remotely since they were last synced with the
+// configured conflict strategy and updates the file index, so changes that lost against the remote version are not
+// uploaded anymore. It returns the changes that should still be uploaded
+func (u *upstream) resolveConflicts(files []*FileInformation) ([]*FileInformation, error) {
+	if u.sync.Options.ConflictStrategy == "" || u.sync.Options.DownstreamDisabled || u.sync.downstream == nil {
+		return files, nil
+	}
+
+	u.sync.fileIndex.fileMapMutex.Lock()
+	initialSyncDone := u.sync.initialSyncDone
+	u.sync.fileIndex.fileMapMutex.Unlock()
+	if initialSyncDone == false {
+		return files, nil
+	}
+
+	select {
+	case <-u.sync.downstream.remoteChangesSynced():
+	case <-u.interrupt:
+		return nil, nil
+	}
+
+	// The downloaded files might have changed the local files and the fileMap
+	u.sync.fileIndex.fileMapMutex.Lock()
+	defer u.sync.fileIndex.fileMapMutex.Unlock()
+
+	newChanges := make([]*FileInformation, 0, len(files))
+	for _, change := range files {
+		if change.IsDirectory == false && change.IsSymbolicLink == false && change.LinkTarget == "" {
+			stat, err := os.Stat(filepath.Join(u.sync.LocalPath, change.Name))
+			if err != nil {
+				continue
+			}
+
+			change.Mtime = stat.ModTime().Unix()
+			change.MtimeNano = stat.ModTime().UnixNano()
+			change.Size = stat.Size()
+		}
+
+		if shouldUpload(u.sync, change) {
+			newChanges = append(newChanges, change)
+		}
+	}
+
+	return newChanges, nil
+}
+
 func (u *upstream) updateUploadChanges(files []*FileInformation) []*FileInformation {
 	u.sync.fileIndex.fileMapMutex.Lock()
 	defer u.sync.fileIndex.fileMapMutex.Unlock()
@@ -484,6 +594,10 @@ func (u *upstream) applyCreates(files []*FileInformation) error {
 	u.sync.fileIndex.fileMapMutex.Lock()
 	defer u.sync.fileIndex.fileMapMutex.Unlock()
 
+	// Large files that exist remotely already are uploaded as delta, the archive is uploaded
+	// even if it is empty afterwards, because it triggers the batch command remotely
+	files = u.uploadDeltas(files)
+
 	var archiver *Archiver
 	errorChan := make(chan error)
 	go func() {
@@ -493,7 +607,9 @@ func (u *upstream) applyCreates(files []*FileInformation) error {
 	}()
 
 	// upload the archive
-	err := u.uploadArchive(reader)
+	start := time.Now()
+	compressedReader := &countingReader{reader: reader}
+	err := uploadArchive(context.Background(), u.client, compressedReader)
 	if err != nil {
 		return errors.Wrap(err, "upload archive")
 	}
@@ -504,6 +620,8 @@ func (u *upstream) applyCreates(files []*FileInformation) error {
 		return errors.Wrap(err, "compress archive")
 	}
 
+	u.sync.logTransferStats("Upstream", u.compression, size, compressedReader.n, start)
+
 	// finally update written files
 	for _, element := range archiver.WrittenFiles() {
 		u.sync.fileIndex.CreateDirInFileMap(path.Dir(element.Name))
@@ -517,7 +635,10 @@ func (u *upstream) compress(writer io.WriteCloser, files []*FileInformation, ign
 	defer writer.Close()
 
 	// Use compression
-	gw := gzip.NewWriter(writer)
+	gw, err := util.NewCompressionWriter(writer, u.compression)
+	if err != nil {
+		return nil, err
+	}
 	defer gw.Close()
 
 	// Create tar writer
@@ -526,6 +647,9 @@ func (u *upstream) compress(writer io.WriteCloser, files []*FileInformation, ign
 
 	// Archive the given files
 	archiver := NewArchiver(u.sync.LocalPath, tarWriter, ignoreMatcher)
+	archiver.symlinks = u.sync.Options.Symlinks
+	archiver.exceedsSizeLimit = u.sync.exceedsSizeLimit
+	archiver.preserveExecutable = u.sync.Options.PreserveExecutable
 	for _, file := range files {
 		err := archiver.AddToArchive(file.Name)
 		if err != nil {
@@ -536,13 +660,13 @@ func (u *upstream) compress(writer io.WriteCloser, files []*FileInformation, ign
 	return archiver, nil
 }
 
-func (u *upstream) uploadArchive(reader io.Reader) error {
+func uploadArchive(ctx context.Context, client remote.UpstreamClient, reader io.Reader) error {
 	// cancel after 1 hour
-	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
 	defer cancel()
 
 	// Create upload client
-	uploadClient, err := u.client.Upload(ctx)
+	uploadClient, err := client.Upload(ctx)
 	if err != nil {
 		return errors.Wrap(err, "upload")
 	}
@@ -0,0 +1,230 @@
+package tunnel
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// UDPSessionTimeout is the time after which a udp session without any datagrams is closed
+var UDPSessionTimeout = time.Minute
+
+// udpSession maps the datagrams of a local peer to a socket of the devspace helper in the container
+type udpSession struct {
+	id         string
+	addr       net.Addr
+	lastActive time.Time
+}
+
+// udpForwarder forwards the datagrams of a local udp listener to a remote port
+type udpForwarder struct {
+	conn   net.PacketConn
+	stream remote.Tunnel_ForwardClient
+	log    logpkg.Logger
+
+	sendMutex sync.Mutex
+
+	sessionsMutex sync.Mutex
+	sessions      map[string]*udpSession
+	sessionsByID  map[string]*udpSession
+}
+
+// StartUDPForward listens on the local udp ports of the port mappings and forwards every datagram via the
+// devspace helper to the remote port. Datagrams of the same local peer belong to the same session, so that
+// the answers of the remote port are sent back to the peer they belong to
+func StartUDPForward(reader io.ReadCloser, writer io.WriteCloser, portMappings []*latest.PortMapping, stopChan chan error, namespace string, name string, log logpkg.Logger) error {
+	// Create client
+	conn, err := util.NewClientConnection(reader, writer)
+	if err != nil {
+		return errors.Wrap(err, "new client connection")
+	}
+	defer conn.Close()
+
+	client := remote.NewTunnelClient(conn)
+	logFile := logpkg.GetFileLogger("portforwarding")
+
+	errorsChan := make(chan error, 2*len(portMappings))
+	for _, portMapping := range portMappings {
+		if portMapping.LocalPort == nil {
+			return fmt.Errorf("local port cannot be undefined")
+		}
+
+		localPort := *portMapping.LocalPort
+		remotePort := localPort
+		if portMapping.RemotePort != nil {
+			remotePort = *portMapping.RemotePort
+		}
+
+		bindAddress := "localhost"
+		if portMapping.BindAddress != "" {
+			bindAddress = portMapping.BindAddress
+		}
+
+		packetConn, err := net.ListenPacket("udp", net.JoinHostPort(bindAddress, strconv.Itoa(localPort)))
+		if err != nil {
+			return errors.Errorf("error listening on udp port %d: %v", localPort, err)
+		}
+		defer packetConn.Close()
+
+		stream, err := client.Forward(context.Background())
+		if err != nil {
+			return errors.Errorf("error sending forward request: %v", err)
+		}
+
+		err = stream.Send(&remote.SocketDataRequest{
+			Port:   int32(remotePort),
+			Scheme: remote.TunnelScheme_UDP,
+		})
+		if err != nil {
+			return errors.Errorf("failed to send initial forward request to server: %v", err)
+		}
+
+		forwarder := &udpForwarder{
+			conn:         packetConn,
+			stream:       stream,
+			log:          logFile,
+			sessions:     map[string]*udpSession{},
+			sessionsByID: map[string]*udpSession{},
+		}
+		go func() {
+			errorsChan <- forwarder.receive()
+		}()
+		go func() {
+			errorsChan <- forwarder.forward()
+		}()
+		go forwarder.closeInactiveSessions(stopChan)
+
+		log.Donef("UDP port forwarding started on %d:%d (%s/%s)", localPort, remotePort, namespace, name)
+	}
+
+	select {
+	case err := <-errorsChan:
+		return err
+	case <-stopChan:
+		return nil
+	}
+}
+
+// forward sends the datagrams of the local peers to the helper
+func (f *udpForwarder) forward() error {
+	buff := make([]byte, 64*1024)
+	for {
+		n, addr, err := f.conn.ReadFrom(buff)
+		if err != nil {
+			return errors.Errorf("error reading from udp socket: %v", err)
+		}
+
+		data := make([]byte, n)
+		copy(data, buff[:n])
+		err = f.send(&remote.SocketDataRequest{
+			RequestId: f.session(addr).id,
+			Data:      data,
+		})
+		if err != nil {
+			return errors.Errorf("failed sending message to forward stream: %v", err)
+		}
+	}
+}
+
+// receive sends the datagrams of the remote port back to the local peers
+func (f *udpForwarder) receive() error {
+	for {
+		m, err := f.stream.Recv()
+		if err != nil {
+			return fmt.Errorf("error reading from stream: %v", err)
+		}
+		if m.HasErr && m.LogMessage != nil {
+			f.log.Warnf("%s: %s", m.RequestId, m.LogMessage.Message)
+		}
+
+		f.sessionsMutex.Lock()
+		session := f.sessionsByID[m.RequestId]
+		if session != nil && m.ShouldClose {
+			f.removeSession(session)
+		}
+		f.sessionsMutex.Unlock()
+		if session == nil || len(m.Data) == 0 {
+			continue
+		}
+
+		_, err = f.conn.WriteTo(m.Data, session.addr)
+		if err != nil {
+			f.log.Warnf("%s: failed writing to udp socket: %v", m.RequestId, err)
+		}
+	}
+}
+
+// session returns the session of the local peer or creates a new one
+func (f *udpForwarder) session(addr net.Addr) *udpSession {
+	f.sessionsMutex.Lock()
+	defer f.sessionsMutex.Unlock()
+
+	session := f.sessions[addr.String()]
+	if session == nil {
+		session = &udpSession{
+			id:   uuid.New().String(),
+			addr: addr,
+		}
+
+		f.sessions[addr.String()] = session
+		f.sessionsByID[session.id] = session
+	}
+
+	session.lastActive = time.Now()
+	return session
+}
+
+// closeInactiveSessions closes the sessions that did not send any datagrams within the session timeout
+func (f *udpForwarder) closeInactiveSessions(stopChan chan error) {
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(UDPSessionTimeout / 2):
+		}
+
+		f.sessionsMutex.Lock()
+		inactive := []*udpSession{}
+		for _, session := range f.sessions {
+			if time.Since(session.lastActive) > UDPSessionTimeout {
+				inactive = append(inactive, session)
+				f.removeSession(session)
+			}
+		}
+		f.sessionsMutex.Unlock()
+
+		for _, session := range inactive {
+			err := f.send(&remote.SocketDataRequest{
+				RequestId:   session.id,
+				ShouldClose: true,
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// removeSession removes the session, f.sessionsMutex needs to be locked before this function is called
+func (f *udpForwarder) removeSession(session *udpSession) {
+	delete(f.sessions, session.addr.String())
+	delete(f.sessionsByID, session.id)
+}
+
+func (f *udpForwarder) send(request *remote.SocketDataRequest) error {
+	f.sendMutex.Lock()
+	defer f.sendMutex.Unlock()
+
+	return f.stream.Send(request)
+}
@@ -0,0 +1,108 @@
+package tunnel
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/tunnel"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestUDPForward(t *testing.T) {
+	// the remote port answers every datagram with its uppercase version
+	echo, err := net.ListenPacket("udp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+
+	go func() {
+		buff := make([]byte, 1024)
+		for {
+			n, addr, err := echo.ReadFrom(buff)
+			if err != nil {
+				return
+			}
+
+			answer := []byte{}
+			for _, b := range buff[:n] {
+				if b >= 'a' && b <= 'z' {
+					b -= 'a' - 'A'
+				}
+				answer = append(answer, b)
+			}
+			_, _ = echo.WriteTo(answer, addr)
+		}
+	}()
+
+	// connect the client with the helper tunnel server
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+	defer stdoutWriter.Close()
+
+	go func() {
+		_ = tunnel.StartTunnelServer(stdinReader, stdoutWriter, false)
+	}()
+
+	// find a free local port
+	free, err := net.ListenPacket("udp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	localPort := free.LocalAddr().(*net.UDPAddr).Port
+	free.Close()
+	remotePort := echo.LocalAddr().(*net.UDPAddr).Port
+
+	stopChan := make(chan error)
+	defer close(stopChan)
+
+	errorChan := make(chan error, 1)
+	go func() {
+		errorChan <- StartUDPForward(stdoutReader, stdinWriter, []*latest.PortMapping{{LocalPort: &localPort, RemotePort: &remotePort}}, stopChan, "test", "test", log.Discard)
+	}()
+
+	// every local peer receives its own answers
+	peers := []net.Conn{}
+	for i := 0; i < 2; i++ {
+		peer, err := net.Dial("udp", net.JoinHostPort(free.LocalAddr().(*net.UDPAddr).IP.String(), strconv.Itoa(localPort)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer peer.Close()
+
+		peers = append(peers, peer)
+	}
+
+	for i, message := range []string{"hello", "world"} {
+		answer := ""
+		for start := time.Now(); time.Since(start) < time.Second*10 && answer == ""; {
+			select {
+			case err := <-errorChan:
+				t.Fatalf("Error forwarding: %v", err)
+			default:
+			}
+
+			_, err = peers[i].Write([]byte(message))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buff := make([]byte, 1024)
+			_ = peers[i].SetReadDeadline(time.Now().Add(time.Millisecond * 200))
+			n, err := peers[i].Read(buff)
+			if err == nil {
+				answer = string(buff[:n])
+			}
+		}
+
+		expected := map[string]string{"hello": "HELLO", "world": "WORLD"}[message]
+		if answer != expected {
+			t.Fatalf("Expected answer %s for peer %d, got %s", expected, i, answer)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package tunnel
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// socks5 protocol constants, see RFC 1928
+const (
+	socks5Version = 0x05
+
+	socks5NoAuth       = 0x00
+	socks5NoAcceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5Succeeded           = 0x00
+	socks5HostUnreachable     = 0x04
+	socks5CmdNotSupported     = 0x07
+	socks5AddrTypeUnsupported = 0x08
+)
+
+// proxyRequestID is the id of the only session of a proxy stream, because every proxied connection uses its own stream
+const proxyRequestID = "proxy"
+
+// proxy accepts SOCKS5 and HTTP CONNECT requests and opens the requested connections in the container
+type proxy struct {
+	client remote.TunnelClient
+	log    logpkg.Logger
+}
+
+// StartProxy listens on the local address for SOCKS5 and HTTP CONNECT proxy requests and opens the requested
+// connections from the container the devspace helper runs in. This allows to reach every host and port of
+// the cluster network, such as service dns names, through a single local port
+func StartProxy(reader io.ReadCloser, writer io.WriteCloser, bindAddress string, port int, stopChan chan error, namespace string, name string, log logpkg.Logger) error {
+	// Create client
+	conn, err := util.NewClientConnection(reader, writer)
+	if err != nil {
+		return errors.Wrap(err, "new client connection")
+	}
+	defer conn.Close()
+
+	if bindAddress == "" {
+		bindAddress = "localhost"
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(bindAddress, strconv.Itoa(port)))
+	if err != nil {
+		return errors.Errorf("error listening on port %d: %v", port, err)
+	}
+	defer listener.Close()
+
+	p := &proxy{
+		client: remote.NewTunnelClient(conn),
+		log:    logpkg.GetFileLogger("proxy"),
+	}
+
+	errorsChan := make(chan error, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				errorsChan <- errors.Errorf("error accepting connection: %v", err)
+				return
+			}
+
+			go p.handle(conn)
+		}
+	}()
+
+	log.Donef("Proxy started on %s (%s/%s)", listener.Addr().String(), namespace, name)
+	select {
+	case err := <-errorsChan:
+		return err
+	case <-stopChan:
+		return nil
+	}
+}
+
+// handle detects the protocol of the proxy request by its first byte, which is the version for SOCKS5
+// and the start of the method for HTTP
+func (p *proxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return
+	}
+
+	if first[0] == socks5Version {
+		err = p.handleSocks5(conn, reader)
+	} else {
+		err = p.handleHTTP(conn, reader)
+	}
+	if err != nil {
+		p.log.Infof("Proxy connection from %s: %v", conn.RemoteAddr(), err)
+	}
+}
+
+// handleSocks5 handles a SOCKS5 request without authentication. Only the CONNECT command is supported
+func (p *proxy) handleSocks5(conn net.Conn, reader *bufio.Reader) error {
+	// version and auth methods
+	header := make([]byte, 2)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return err
+	}
+	methods := make([]byte, header[1])
+	_, err = io.ReadFull(reader, methods)
+	if err != nil {
+		return err
+	}
+
+	noAuth := false
+	for _, method := range methods {
+		if method == socks5NoAuth {
+			noAuth = true
+		}
+	}
+	if noAuth == false {
+		_, _ = conn.Write([]byte{socks5Version, socks5NoAcceptable})
+		return errors.New("socks5 client does not support connecting without authentication")
+	}
+	_, err = conn.Write([]byte{socks5Version, socks5NoAuth})
+	if err != nil {
+		return err
+	}
+
+	// version, command, reserved and address type
+	request := make([]byte, 4)
+	_, err = io.ReadFull(reader, request)
+	if err != nil {
+		return err
+	}
+	if request[1] != socks5CmdConnect {
+		_ = writeSocks5Reply(conn, socks5CmdNotSupported)
+		return errors.Errorf("socks5 command %d is not supported", request[1])
+	}
+
+	var host string
+	switch request[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if request[3] == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+
+		ip := make([]byte, size)
+		_, err = io.ReadFull(reader, ip)
+		if err != nil {
+			return err
+		}
+
+		host = net.IP(ip).String()
+	case socks5AddrDomain:
+		length, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		domain := make([]byte, length)
+		_, err = io.ReadFull(reader, domain)
+		if err != nil {
+			return err
+		}
+
+		host = string(domain)
+	default:
+		_ = writeSocks5Reply(conn, socks5AddrTypeUnsupported)
+		return errors.Errorf("socks5 address type %d is not supported", request[3])
+	}
+
+	portBytes := make([]byte, 2)
+	_, err = io.ReadFull(reader, portBytes)
+	if err != nil {
+		return err
+	}
+
+	port := int(binary.BigEndian.Uint16(portBytes))
+	stream, err := p.dial(host, port)
+	if err != nil {
+		_ = writeSocks5Reply(conn, socks5HostUnreachable)
+		return err
+	}
+
+	err = writeSocks5Reply(conn, socks5Succeeded)
+	if err != nil {
+		_ = stream.CloseSend()
+		return err
+	}
+
+	return p.pipe(conn, reader, stream)
+}
+
+// writeSocks5Reply writes a reply with an empty bind address, because the address the container
+// connected from is not known
+func writeSocks5Reply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socks5Version, reply, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// handleHTTP handles a HTTP CONNECT request, other methods are rejected because the proxy does not
+// look into the forwarded traffic
+func (p *proxy) handleHTTP(conn net.Conn, reader *bufio.Reader) error {
+	request, err := http.ReadRequest(reader)
+	if err != nil {
+		return errors.Wrap(err, "read http request")
+	}
+	if request.Method != http.MethodConnect {
+		writeHTTPStatus(conn, http.StatusMethodNotAllowed)
+		return errors.Errorf("http method %s is not supported, only CONNECT requests can be proxied", request.Method)
+	}
+
+	host, portString, err := net.SplitHostPort(request.Host)
+	if err != nil {
+		writeHTTPStatus(conn, http.StatusBadRequest)
+		return errors.Errorf("invalid CONNECT address %s: %v", request.Host, err)
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		writeHTTPStatus(conn, http.StatusBadRequest)
+		return errors.Errorf("invalid CONNECT port %s", portString)
+	}
+
+	stream, err := p.dial(host, port)
+	if err != nil {
+		writeHTTPStatus(conn, http.StatusBadGateway)
+		return err
+	}
+
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	if err != nil {
+		_ = stream.CloseSend()
+		return err
+	}
+
+	return p.pipe(conn, reader, stream)
+}
+
+func writeHTTPStatus(conn net.Conn, status int) {
+	_, _ = fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nConnection: close\r\n\r\n", status, http.StatusText(status))
+}
+
+// dial opens a forward stream to the host and port and waits until the devspace helper connected to it
+func (p *proxy) dial(host string, port int) (remote.Tunnel_ForwardClient, error) {
+	stream, err := p.client.Forward(context.Background())
+	if err != nil {
+		return nil, errors.Errorf("error sending forward request: %v", err)
+	}
+
+	err = stream.Send(&remote.SocketDataRequest{
+		Host:   host,
+		Port:   int32(port),
+		Scheme: remote.TunnelScheme_TCP,
+	})
+	if err != nil {
+		return nil, errors.Errorf("failed to send initial forward request to server: %v", err)
+	}
+
+	// a request without data lets the helper connect to the target
+	err = stream.Send(&remote.SocketDataRequest{
+		RequestId: proxyRequestID,
+	})
+	if err != nil {
+		return nil, errors.Errorf("failed to send connect request to server: %v", err)
+	}
+
+	response, err := stream.Recv()
+	if err != nil {
+		return nil, errors.Errorf("error reading from stream: %v", err)
+	} else if response.HasErr {
+		_ = stream.CloseSend()
+		if response.LogMessage != nil {
+			return nil, errors.New(response.LogMessage.Message)
+		}
+
+		return nil, errors.Errorf("failed connecting to %s", net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+
+	return stream, nil
+}
+
+// pipe copies the data between the local connection and the stream until one of them is closed
+func (p *proxy) pipe(conn net.Conn, reader io.Reader, stream remote.Tunnel_ForwardClient) error {
+	sendMutex := sync.Mutex{}
+	send := func(request *remote.SocketDataRequest) error {
+		sendMutex.Lock()
+		defer sendMutex.Unlock()
+
+		return stream.Send(request)
+	}
+
+	go func() {
+		defer conn.Close()
+
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			if len(response.Data) > 0 {
+				_, err = conn.Write(response.Data)
+				if err != nil {
+					return
+				}
+			}
+			if response.ShouldClose {
+				if response.HasErr && response.LogMessage != nil {
+					p.log.Infof("Proxy connection from %s: %s", conn.RemoteAddr(), response.LogMessage.Message)
+				}
+
+				return
+			}
+		}
+	}()
+
+	defer func() {
+		_ = send(&remote.SocketDataRequest{
+			RequestId:   proxyRequestID,
+			ShouldClose: true,
+		})
+		sendMutex.Lock()
+		_ = stream.CloseSend()
+		sendMutex.Unlock()
+	}()
+
+	buff := make([]byte, bufferSize)
+	for {
+		n, err := reader.Read(buff)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buff[:n])
+			sendErr := send(&remote.SocketDataRequest{
+				RequestId: proxyRequestID,
+				Data:      data,
+			})
+			if sendErr != nil {
+				return errors.Errorf("failed sending message to forward stream: %v", sendErr)
+			}
+		}
+		if err != nil {
+			// the connection is closed by the receiving side if the target closed its socket
+			if err == io.EOF || strings.Contains(err.Error(), "use of closed network connection") {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package tunnel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/tunnel"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	netproxy "golang.org/x/net/proxy"
+)
+
+func TestProxy(t *testing.T) {
+	// the target answers every line with its uppercase version
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+
+					_, _ = conn.Write([]byte(strings.ToUpper(line)))
+				}
+			}(conn)
+		}
+	}()
+
+	// connect the proxy with the helper tunnel server
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+	defer stdoutWriter.Close()
+
+	go func() {
+		_ = tunnel.StartTunnelServer(stdinReader, stdoutWriter, false)
+	}()
+
+	// find a free local port
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyPort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	stopChan := make(chan error)
+	defer close(stopChan)
+
+	errorChan := make(chan error, 1)
+	go func() {
+		errorChan <- StartProxy(stdoutReader, stdinWriter, "127.0.0.1", proxyPort, stopChan, "test", "test", log.Discard)
+	}()
+
+	proxyAddress := net.JoinHostPort("127.0.0.1", strconv.Itoa(proxyPort))
+	targetAddress := echo.Addr().String()
+	for start := time.Now(); ; time.Sleep(time.Millisecond * 50) {
+		select {
+		case err := <-errorChan:
+			t.Fatalf("Error starting proxy: %v", err)
+		default:
+		}
+
+		conn, err := net.Dial("tcp", proxyAddress)
+		if err == nil {
+			conn.Close()
+			break
+		} else if time.Since(start) > time.Second*10 {
+			t.Fatalf("Proxy did not start: %v", err)
+		}
+	}
+
+	// socks5
+	dialer, err := netproxy.SOCKS5("tcp", proxyAddress, nil, netproxy.Direct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := dialer.Dial("tcp", targetAddress)
+	if err != nil {
+		t.Fatalf("Error connecting via socks5: %v", err)
+	}
+	expectEcho(t, "socks5", conn)
+
+	_, err = dialer.Dial("tcp", "127.0.0.1:1")
+	if err == nil {
+		t.Fatalf("Connecting via socks5 to a closed port succeeded")
+	}
+
+	// http connect
+	conn, err = net.Dial("tcp", proxyAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", targetAddress, targetAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Error reading CONNECT response: %v", err)
+	} else if response.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected CONNECT response status %d", response.StatusCode)
+	}
+	expectEcho(t, "http connect", conn)
+}
+
+func expectEcho(t *testing.T, name string, conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for _, message := range []string{"hello", "world"} {
+		_, err := conn.Write([]byte(message + "\n"))
+		if err != nil {
+			t.Fatalf("Error writing via %s: %v", name, err)
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second * 10))
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Error reading via %s: %v", name, err)
+		} else if answer != strings.ToUpper(message)+"\n" {
+			t.Fatalf("Expected answer %s via %s, got %s", strings.ToUpper(message), name, answer)
+		}
+	}
+}
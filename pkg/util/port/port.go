@@ -30,3 +30,14 @@ func CheckHostPort(host string, port int) (status bool, err error) {
 func Check(port int) (status bool, err error) {
 	return CheckHostPort("", port)
 }
+
+// FreeHostPort returns a free port on the host that is chosen by the operating system
+func FreeHostPort(host string) (int, error) {
+	server, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return 0, err
+	}
+	defer server.Close()
+
+	return server.Addr().(*net.TCPAddr).Port, nil
+}